@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN family_id CHAR(26) NULL;
+ALTER TABLE refresh_tokens ADD COLUMN parent_id CHAR(26) NULL;
+
+-- Existing tokens each become the root of their own family
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN token_hash CHAR(64) NULL;
+
+-- Existing rows keep working: their digest matches what the application now looks up
+UPDATE refresh_tokens SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+ALTER TABLE refresh_tokens ALTER COLUMN token_hash SET NOT NULL;
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+
+DROP INDEX IF EXISTS idx_refresh_tokens_token;
+ALTER TABLE refresh_tokens DROP COLUMN token;
+
+-- +goose Down
+ALTER TABLE refresh_tokens ADD COLUMN token TEXT NULL;
+
+-- Raw tokens cannot be recovered from their digests, so existing sessions are revoked
+UPDATE refresh_tokens
+SET token = token_hash,
+    revoked = true,
+    revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP);
+
+ALTER TABLE refresh_tokens ALTER COLUMN token SET NOT NULL;
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_token_key UNIQUE (token);
+CREATE INDEX idx_refresh_tokens_token ON refresh_tokens(token);
+
+DROP INDEX IF EXISTS idx_refresh_tokens_token_hash;
+ALTER TABLE refresh_tokens DROP COLUMN token_hash;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN user_agent VARCHAR(512) NULL;
+ALTER TABLE refresh_tokens ADD COLUMN client_ip VARCHAR(45) NULL;
+ALTER TABLE refresh_tokens ADD COLUMN device_name VARCHAR(100) NULL;
+ALTER TABLE refresh_tokens ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS device_name;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS client_ip;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS user_agent;
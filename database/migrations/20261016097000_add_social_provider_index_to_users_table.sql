@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE UNIQUE INDEX idx_users_social_provider ON users(social_provider, social_provider_id) WHERE social_provider_id IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_social_provider;
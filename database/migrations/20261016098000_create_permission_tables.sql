@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE permissions (
+    id CHAR(26) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255) NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(20) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role, permission),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+INSERT INTO permissions (id, name, description) VALUES
+    ('01M53HHE5PV80MEAJ51Y341RNQ', 'users:update', 'Update any user account'),
+    ('01M53HHE5PV80MEAJ5204HEZJN', 'users:delete', 'Delete any user account'),
+    ('01M53HHE5PV80MEAJ522PA39F3', 'users:read:email', 'Read the email address of any user');
+
+-- Admins keep the access they had before permissions were introduced
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:update'),
+    ('admin', 'users:delete'),
+    ('admin', 'users:read:email'),
+    ('staff', 'users:read:email');
+
+-- +goose Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE password_histories (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_password_histories_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_histories_user_id ON password_histories(user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_histories;
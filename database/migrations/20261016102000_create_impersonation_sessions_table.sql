@@ -0,0 +1,18 @@
+-- +goose Up
+-- Audit trail of admin impersonation; rows have no foreign keys so they outlive the accounts involved
+CREATE TABLE impersonation_sessions (
+    id CHAR(26) PRIMARY KEY,
+    admin_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    client_ip VARCHAR(45) NULL,
+    user_agent VARCHAR(512) NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonation_sessions_admin_id ON impersonation_sessions(admin_id);
+CREATE INDEX idx_impersonation_sessions_user_id ON impersonation_sessions(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS impersonation_sessions;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE security_events (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    details JSONB NULL,
+    client_ip VARCHAR(45) NULL,
+    user_agent VARCHAR(512) NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_security_events_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_security_events_user_id_created_at ON security_events(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS security_events;
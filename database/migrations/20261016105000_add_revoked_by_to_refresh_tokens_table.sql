@@ -0,0 +1,6 @@
+-- +goose Up
+-- Only tokens revoked by rotation count as reuse when presented again
+ALTER TABLE refresh_tokens ADD COLUMN revoked_by VARCHAR(20) NULL;
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS revoked_by;
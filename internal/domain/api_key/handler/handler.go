@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"net/http"
+
+	apikey "gin/internal/domain/api_key"
+	apikeysvc "gin/internal/domain/api_key/service"
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/response"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles HTTP requests for API keys and service accounts
+type APIKeyHandler struct {
+	apiKeyService apikeysvc.APIKeyServiceInterface
+	userService   usersvc.UserServiceInterface
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService apikeysvc.APIKeyServiceInterface, userService usersvc.UserServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		userService:   userService,
+	}
+}
+
+// ListAPIKeys lists the API keys of the authenticated user
+// @Summary      List API keys
+// @Description  List the active API keys of the authenticated user. Secrets are never returned.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]apikey.APIKeyDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.listAPIKeys(c, userID)
+}
+
+// CreateAPIKey creates an API key for the authenticated user
+// @Summary      Create API key
+// @Description  Create an API key acting as the authenticated user, limited to the given scopes. Send it in the X-API-Key header. The key is only shown in this response.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key  body      apikey.CreateAPIKeyRequest  true  "API key name, scopes and optional expiry"
+// @Success      201  {object}  response.Response{data=apikey.CreatedAPIKeyDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      422  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.createAPIKey(c, userID)
+}
+
+// RevokeAPIKey revokes an API key of the authenticated user
+// @Summary      Revoke API key
+// @Description  Revoke an API key of the authenticated user. It stops working immediately.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.revokeAPIKey(c, userID, c.Param("id"))
+}
+
+// CreateServiceAccount creates a service account for machine clients
+// @Summary      Create service account
+// @Description  Create an account that cannot log in and authenticates with API keys only. Requires the admin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        account  body      apikey.CreateServiceAccountRequest  true  "Service account name"
+// @Success      201      {object}  response.Response{data=user.UserDTO}
+// @Failure      401      {object}  response.ErrorResponse
+// @Failure      403      {object}  response.ErrorResponse
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /admin/service-accounts [post]
+func (h *APIKeyHandler) CreateServiceAccount(c *gin.Context) {
+	var req apikey.CreateServiceAccountRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	account, err := h.userService.CreateServiceAccount(c.Request.Context(), req.Name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, user.FromUserModel(*account), "Service account created successfully", response.HTTPCreated)
+}
+
+// ListUserAPIKeys lists the API keys of any user or service account
+// @Summary      List API keys of a user
+// @Description  List the active API keys of a user or service account. Requires the admin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response{data=[]apikey.APIKeyDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /admin/users/{id}/api-keys [get]
+func (h *APIKeyHandler) ListUserAPIKeys(c *gin.Context) {
+	h.listAPIKeys(c, c.Param("id"))
+}
+
+// CreateUserAPIKey creates an API key for any user or service account
+// @Summary      Create API key for a user
+// @Description  Create an API key acting as a user or service account. The key is only shown in this response. Requires the admin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string                      true  "User ID"
+// @Param        key  body      apikey.CreateAPIKeyRequest  true  "API key name, scopes and optional expiry"
+// @Success      201  {object}  response.Response{data=apikey.CreatedAPIKeyDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      422  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /admin/users/{id}/api-keys [post]
+func (h *APIKeyHandler) CreateUserAPIKey(c *gin.Context) {
+	h.createAPIKey(c, c.Param("id"))
+}
+
+// RevokeUserAPIKey revokes an API key of any user or service account
+// @Summary      Revoke API key of a user
+// @Description  Revoke an API key of a user or service account. Requires the admin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string  true  "User ID"
+// @Param        keyId  path      string  true  "API key ID"
+// @Success      200    {object}  response.Response
+// @Failure      401    {object}  response.ErrorResponse
+// @Failure      403    {object}  response.ErrorResponse
+// @Failure      404    {object}  response.ErrorResponse
+// @Failure      500    {object}  response.ErrorResponse
+// @Router       /admin/users/{id}/api-keys/{keyId} [delete]
+func (h *APIKeyHandler) RevokeUserAPIKey(c *gin.Context) {
+	h.revokeAPIKey(c, c.Param("id"), c.Param("keyId"))
+}
+
+// listAPIKeys responds with the active keys of a user
+func (h *APIKeyHandler) listAPIKeys(c *gin.Context, userID string) {
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, keys, "API keys retrieved successfully")
+}
+
+// createAPIKey binds the request and creates a key owned by the user
+func (h *APIKeyHandler) createAPIKey(c *gin.Context, userID string) {
+	var req apikey.CreateAPIKeyRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	created, err := h.apiKeyService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, created, "API key created. Store it now; it will not be shown again.", response.HTTPCreated)
+}
+
+// revokeAPIKey revokes a key owned by the user
+func (h *APIKeyHandler) revokeAPIKey(c *gin.Context, userID string, id string) {
+	if err := h.apiKeyService.Revoke(c.Request.Context(), userID, id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "API key revoked successfully", http.StatusOK)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	apikey "gin/internal/domain/api_key"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository handles API key database operations
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *APIKeyRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *apikey.APIKey) (*apikey.APIKey, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// FindByHash finds an API key by the digest of its secret
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*apikey.APIKey, error) {
+	var key apikey.APIKey
+	err := r.getDB(ctx).WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindActiveByUserID lists the unrevoked keys of a user, newest first
+func (r *APIKeyRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*apikey.APIKey, error) {
+	var keys []*apikey.APIKey
+	err := r.getDB(ctx).WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke revokes a key of a user
+// Returns false when the user has no such unrevoked key
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID string, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&apikey.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// TouchLastUsed records that a key was used, at most once per resolution to spare writes on busy keys
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, resolution time.Duration) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&apikey.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, time.Now().Add(-resolution)).
+		Update("last_used_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
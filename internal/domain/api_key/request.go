@@ -0,0 +1,16 @@
+package apikey
+
+import "time"
+
+// CreateAPIKeyRequest represents the request payload for creating an API key
+// Scopes are permission names such as "users:read:email"
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateServiceAccountRequest represents the request payload for creating a service account
+type CreateServiceAccountRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apikey "gin/internal/domain/api_key"
+	apiKeyRepository "gin/internal/domain/api_key/repository"
+	permissionsvc "gin/internal/domain/permission/service"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// displayPrefixLength is how many characters of the secret are kept with the prefix to tell keys apart
+const displayPrefixLength = 8
+
+// lastUsedResolution is how stale the last-used timestamp of a key may get before it is written again
+const lastUsedResolution = time.Minute
+
+// Options configures the API key format
+type Options struct {
+	// Prefix starts every key, e.g. "gsk", so leaked keys are easy to recognise and scan for
+	Prefix string
+}
+
+// APIKeyService implements APIKeyServiceInterface
+type APIKeyService struct {
+	apiKeyRepo        *apiKeyRepository.APIKeyRepository
+	userService       usersvc.UserServiceInterface
+	permissionService permissionsvc.PermissionServiceInterface
+	recorder          audit.Recorder
+	options           Options
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(
+	apiKeyRepo *apiKeyRepository.APIKeyRepository,
+	userService usersvc.UserServiceInterface,
+	permissionService permissionsvc.PermissionServiceInterface,
+	recorder audit.Recorder,
+	options Options,
+) APIKeyServiceInterface {
+	return &APIKeyService{
+		apiKeyRepo:        apiKeyRepo,
+		userService:       userService,
+		permissionService: permissionService,
+		recorder:          recorder,
+		options:           options,
+	}
+}
+
+// Create issues a new key for a user; the returned key is never shown again
+func (s *APIKeyService) Create(ctx context.Context, userID string, req apikey.CreateAPIKeyRequest) (*apikey.CreatedAPIKeyDTO, error) {
+	if _, err := s.userService.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "expires_at", Message: "The expires at must be a date in the future."},
+		})
+	}
+
+	scopes, err := s.validateScopes(ctx, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateSecureToken()
+	if err != nil {
+		return nil, exceptions.InternalError("Failed to generate API key", nil, nil)
+	}
+	key := s.options.Prefix + "_" + secret
+
+	created, err := s.apiKeyRepo.Create(ctx, &apikey.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    key[:len(s.options.Prefix)+1+displayPrefixLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recorder.Record(ctx, userID, audit.EventAPIKeyCreated, keyEventDetails(ctx, created.ID))
+	return &apikey.CreatedAPIKeyDTO{APIKeyDTO: apikey.FromAPIKeyModel(*created), Key: key}, nil
+}
+
+// List returns the unrevoked keys of a user
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]apikey.APIKeyDTO, error) {
+	keys, err := s.apiKeyRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]apikey.APIKeyDTO, 0, len(keys))
+	for _, key := range keys {
+		dtos = append(dtos, apikey.FromAPIKeyModel(*key))
+	}
+	return dtos, nil
+}
+
+// Revoke revokes a key of a user; it stops working immediately
+func (s *APIKeyService) Revoke(ctx context.Context, userID string, id string) error {
+	revoked, err := s.apiKeyRepo.Revoke(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return exceptions.NotFoundError("API key not found", nil, nil)
+	}
+
+	s.recorder.Record(ctx, userID, audit.EventAPIKeyRevoked, keyEventDetails(ctx, id))
+	return nil
+}
+
+// Authenticate resolves a presented key to the principal it acts as
+// Keys of suspended or deleted users stop working along with their owner
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*authz.Principal, error) {
+	if !strings.HasPrefix(key, s.options.Prefix+"_") {
+		return nil, invalidKeyError()
+	}
+
+	record, err := s.apiKeyRepo.FindByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.IsRevoked() || record.IsExpired() {
+		return nil, invalidKeyError()
+	}
+
+	owner, err := s.userService.GetUserByID(ctx, record.UserID)
+	if err != nil || owner.Status != constant.UserStatusActive {
+		return nil, invalidKeyError()
+	}
+
+	// Losing a last-used update is not worth failing the request for
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, record.ID, lastUsedResolution); err != nil {
+		logger.LogError(err, "Failed to record API key use", map[string]interface{}{"key_id": record.ID})
+	}
+
+	role := owner.Type
+	if role == "" {
+		role = constant.AccountTypeCustomer
+	}
+
+	return &authz.Principal{
+		UserID:   owner.ID,
+		Role:     role,
+		APIKeyID: record.ID,
+		Scopes:   record.ScopeList(),
+	}, nil
+}
+
+// validateScopes checks that every scope names a known permission and removes duplicates
+func (s *APIKeyService) validateScopes(ctx context.Context, scopes []string) ([]string, error) {
+	permissions, err := s.permissionService.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		known[p.Name] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	valid := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+				{Field: "scopes", Message: "The selected scope " + scope + " is invalid."},
+			})
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
+
+// invalidKeyError is returned for unknown, revoked and expired keys alike
+func invalidKeyError() error {
+	return exceptions.UnauthorizedError("Invalid API key", nil, nil)
+}
+
+// keyEventDetails describes a key in the owner's activity log, along with who managed it
+func keyEventDetails(ctx context.Context, keyID string) map[string]string {
+	details := map[string]string{"key_id": keyID, "actor_id": "system"}
+	if principal, ok := authz.PrincipalFromContext(ctx); ok {
+		details["actor_id"] = principal.UserID
+	}
+	return details
+}
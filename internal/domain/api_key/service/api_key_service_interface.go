@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	apikey "gin/internal/domain/api_key"
+	"gin/internal/shared/authz"
+)
+
+type APIKeyServiceInterface interface {
+	Create(ctx context.Context, userID string, req apikey.CreateAPIKeyRequest) (*apikey.CreatedAPIKeyDTO, error)
+	List(ctx context.Context, userID string) ([]apikey.APIKeyDTO, error)
+	Revoke(ctx context.Context, userID string, id string) error
+	Authenticate(ctx context.Context, key string) (*authz.Principal, error)
+}
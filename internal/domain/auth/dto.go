@@ -5,16 +5,25 @@ import "gin/internal/domain/user"
 // LoginResponseDTO represents the login response with tokens
 type LoginResponseDTO struct {
 	AccessToken  string       `json:"accessToken"`
-	RefreshToken string       `json:"refreshToken"`
+	RefreshToken string       `json:"refreshToken,omitempty"` // Omitted in cookie auth mode
+	CSRFToken    string       `json:"csrfToken,omitempty"`    // Set in cookie auth mode
 	TokenType    string       `json:"tokenType"`
 	ExpiresIn    int64        `json:"expiresIn"`
 	User         user.UserDTO `json:"user"`
 }
 
+// AccessTokenResponseDTO represents a new access token for the current session
+type AccessTokenResponseDTO struct {
+	AccessToken string `json:"accessToken"`
+	TokenType   string `json:"tokenType"`
+	ExpiresIn   int64  `json:"expiresIn"`
+}
+
 // RefreshTokenResponseDTO represents the refresh token response
 type RefreshTokenResponseDTO struct {
 	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"` // New refresh token (rotation)
+	RefreshToken string `json:"refreshToken,omitempty"` // New refresh token (rotation); omitted in cookie auth mode
+	CSRFToken    string `json:"csrfToken,omitempty"`    // Set in cookie auth mode
 	TokenType    string `json:"tokenType"`
 	ExpiresIn    int64  `json:"expiresIn"`
 }
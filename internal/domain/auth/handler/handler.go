@@ -1,34 +1,71 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"gin/internal/domain/auth"
-	"gin/internal/shared/constant"
-	exceptions "gin/internal/shared/exception"
+	authsvc "gin/internal/domain/auth/service"
+	emailverificationsvc "gin/internal/domain/email_verification/service"
+	magiclinksvc "gin/internal/domain/magic_link/service"
+	passwordresetsvc "gin/internal/domain/password_reset/service"
 	refreshtoken "gin/internal/domain/refresh_token"
 	refreshsvc "gin/internal/domain/refresh_token/service"
-	"gin/internal/shared/response"
+	twofactorsvc "gin/internal/domain/two_factor/service"
 	userdomain "gin/internal/domain/user"
 	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/response"
 	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-	userService         usersvc.UserServiceInterface
-	jwtManager          *utils.JWTManager
-	refreshTokenService refreshsvc.RefreshTokenServiceInterface
+	userService              usersvc.UserServiceInterface
+	jwtManager               *utils.JWTManager
+	refreshTokenService      refreshsvc.RefreshTokenServiceInterface
+	emailVerificationService emailverificationsvc.EmailVerificationServiceInterface
+	passwordResetService     passwordresetsvc.PasswordResetServiceInterface
+	magicLinkService         magiclinksvc.MagicLinkServiceInterface
+	twoFactorService         twofactorsvc.TwoFactorServiceInterface
+	socialLoginService       authsvc.SocialLoginServiceInterface
+	loginThrottleService     authsvc.LoginThrottleServiceInterface
+	recorder                 audit.Recorder
+	cookieOptions            utils.AuthCookieOptions
 }
 
-func NewAuthHandler(userService usersvc.UserServiceInterface, jwtManager *utils.JWTManager, refreshTokenService refreshsvc.RefreshTokenServiceInterface) *AuthHandler {
+func NewAuthHandler(
+	userService usersvc.UserServiceInterface,
+	jwtManager *utils.JWTManager,
+	refreshTokenService refreshsvc.RefreshTokenServiceInterface,
+	emailVerificationService emailverificationsvc.EmailVerificationServiceInterface,
+	passwordResetService passwordresetsvc.PasswordResetServiceInterface,
+	magicLinkService magiclinksvc.MagicLinkServiceInterface,
+	twoFactorService twofactorsvc.TwoFactorServiceInterface,
+	socialLoginService authsvc.SocialLoginServiceInterface,
+	loginThrottleService authsvc.LoginThrottleServiceInterface,
+	recorder audit.Recorder,
+	cookieOptions utils.AuthCookieOptions,
+) *AuthHandler {
 	return &AuthHandler{
-		userService:         userService,
-		jwtManager:          jwtManager,
-		refreshTokenService: refreshTokenService,
+		userService:              userService,
+		jwtManager:               jwtManager,
+		refreshTokenService:      refreshTokenService,
+		emailVerificationService: emailVerificationService,
+		passwordResetService:     passwordResetService,
+		magicLinkService:         magicLinkService,
+		twoFactorService:         twoFactorService,
+		socialLoginService:       socialLoginService,
+		loginThrottleService:     loginThrottleService,
+		recorder:                 recorder,
+		cookieOptions:            cookieOptions,
 	}
 }
 
@@ -59,20 +96,246 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		LastName:  req.LastName,
 		Email:     req.Email,
 	}
-	_, err := h.userService.CreateUser(c.Request.Context(), signupInput)
+	createdUser, err := h.userService.CreateUser(c.Request.Context(), signupInput)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	// TODO: Send verification email here
-	// The email should contain a link to the password setup page
+	// Send the verification email containing the password setup link
+	// A mail failure rolls back the signup so the user can simply retry
+	if err := h.emailVerificationService.SendVerification(c.Request.Context(), createdUser); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
 	response.SendSuccess(c, "User created successfully. Please check your email to verify your account and set your password.", http.StatusCreated)
 }
 
+// VerifyEmail verifies the signup email, sets the user's password and activates the account
+// @Summary      Verify email
+// @Description  Consume the single-use verification token sent at signup, set the account password and activate the account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        verification  body      auth.VerifyEmailRequest  true  "Verification token and new password"
+// @Success      200           {object}  response.Response{data=user.UserDTO}
+// @Failure      403           {object}  response.ErrorResponse
+// @Failure      422           {object}  response.ErrorResponse
+// @Failure      500           {object}  response.ErrorResponse
+// @Router       /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req auth.VerifyEmailRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	verifiedUser, err := h.emailVerificationService.Verify(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, userdomain.FromUserModel(*verifiedUser), "Email verified successfully. You can now log in.")
+}
+
+// ResendVerification sends a new verification email to an unverified account
+// @Summary      Resend verification email
+// @Description  Issue a new verification link for an inactive account. At most one link is sent per cooldown window. The response does not reveal whether the email is registered.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        email  body      auth.ResendVerificationRequest  true  "Account email"
+// @Success      200    {object}  response.Response
+// @Failure      422    {object}  response.ErrorResponse
+// @Failure      500    {object}  response.ErrorResponse
+// @Router       /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req auth.ResendVerificationRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.emailVerificationService.Resend(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "If an unverified account exists for this email, a new verification link has been sent.", http.StatusOK)
+}
+
+// ForgotPassword emails a password reset link
+// @Summary      Forgot password
+// @Description  Send a single-use password reset link. The response is identical whether or not the email is registered.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        email  body      auth.ForgotPasswordRequest  true  "Account email"
+// @Success      200    {object}  response.Response
+// @Failure      422    {object}  response.ErrorResponse
+// @Failure      500    {object}  response.ErrorResponse
+// @Router       /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req auth.ForgotPasswordRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "If an account exists for this email, a password reset link has been sent.", http.StatusOK)
+}
+
+// ResetPassword sets a new password using a reset token and revokes all sessions
+// @Summary      Reset password
+// @Description  Consume a single-use reset token, set a new password and revoke every refresh token of the account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        reset  body      auth.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200    {object}  response.Response
+// @Failure      422    {object}  response.ErrorResponse
+// @Failure      500    {object}  response.ErrorResponse
+// @Router       /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req auth.ResetPasswordRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.passwordResetService.Reset(c.Request.Context(), req.Token, req.Password); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "Password has been reset successfully. Please log in with your new password.", http.StatusOK)
+}
+
+// RequestMagicLink emails a single-use passwordless login link
+// @Summary      Request magic link
+// @Description  Email a short-lived, single-use login link. Always responds with the same message so the response does not reveal whether the email is registered. An optional device_id binds the link to the requesting device. Requests are limited per email and per client IP (429).
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      auth.MagicLinkRequest  true  "Account email and optional device binding"
+// @Success      200      {object}  response.Response
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      429      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req auth.MagicLinkRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.magicLinkService.RequestLink(c.Request.Context(), req.Email, req.DeviceID, c.ClientIP()); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "If an account exists for this email, a login link has been sent.", http.StatusOK)
+}
+
+// ConsumeMagicLink signs a user in with a magic link and returns JWT tokens
+// @Summary      Consume magic link
+// @Description  Exchange a magic link token for tokens, like /auth/login. A device-bound link also needs the device_id it was requested with. Inactive accounts are activated, since the link proves ownership of the email. Accounts with two-factor authentication receive a challenge instead of tokens.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      auth.ConsumeMagicLinkRequest  true  "Magic link token"
+// @Success      200      {object}  response.Response{data=auth.LoginResponseDTO}
+// @Failure      403      {object}  response.ErrorResponse
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /auth/magic-link/consume [post]
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	var req auth.ConsumeMagicLinkRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	u, err := h.magicLinkService.Consume(c.Request.Context(), req.Token, req.DeviceID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	h.completeLogin(c, u, req.DeviceName)
+}
+
 // Login authenticates a user and returns JWT tokens
 // @Summary      User login
-// @Description  Authenticate user with email and password, receive access and refresh tokens
+// @Description  Authenticate user with email and password, receive access and refresh tokens. Accounts with two-factor authentication receive a twofactor.ChallengeDTO instead (twoFactorRequired=true); exchange its challenge token for tokens at /auth/2fa/verify. Repeated failures for an email or client IP trigger an increasing backoff and then a temporary lockout (429), whether or not the account exists. In cookie auth mode the refresh token is set in an HttpOnly cookie instead of the body, along with a csrf_token cookie.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -80,8 +343,8 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 // @Success      200          {object}  response.Response{data=auth.LoginResponseDTO}
 // @Failure      400          {object}  response.ErrorResponse
 // @Failure      401          {object}  response.ErrorResponse
-// @Failure      404          {object}  response.ErrorResponse
 // @Failure      422          {object}  response.ErrorResponse
+// @Failure      429          {object}  response.ErrorResponse
 // @Failure      500          {object}  response.ErrorResponse
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -101,6 +364,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Refuse attempts while the email or client IP is backing off or locked out
+	clientIP := c.ClientIP()
+	if err := h.loginThrottleService.Check(c.Request.Context(), req.Email, clientIP); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
 	u, err := h.userService.GetUserByEmail(c.Request.Context(), req.Email)
 
 	if err != nil {
@@ -108,55 +378,97 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	if u == nil {
-		appErr := exceptions.NotFoundError("User not found with given email", nil, nil)
+	// Unknown emails get the same bcrypt work and the same answer as wrong passwords,
+	// so responses do not reveal whether an account exists
+	passwordHash := dummyPasswordHash()
+	if u != nil {
+		passwordHash = []byte(u.Password)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password)); err != nil || u == nil {
+		if err := h.loginThrottleService.RecordFailure(c.Request.Context(), req.Email, clientIP); err != nil {
+			appErr := exceptions.InternalError("Failed to record login attempt", nil, nil)
+			_ = c.Error(appErr)
+			return
+		}
+
+		if u != nil {
+			h.recorder.Record(c.Request.Context(), u.ID, audit.EventLoginFailed, map[string]string{"reason": "invalid_password"})
+		}
+
+		appErr := exceptions.UnauthorizedError("Invalid credentials", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	// Check if user is active
-	if u.Status != constant.UserStatusActive {
-		appErr := exceptions.UnauthorizedError("Your account is not active. Please verify your email and set your password", nil, nil)
+	if err := h.loginThrottleService.RecordSuccess(c.Request.Context(), req.Email); err != nil {
+		appErr := exceptions.InternalError("Failed to record login attempt", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)); err != nil {
-		appErr := exceptions.UnauthorizedError("Invalid credentials", nil, nil)
+	// Checked after the password so only the account owner learns the account state
+	if u.Status != constant.UserStatusActive {
+		appErr := exceptions.UnauthorizedError("Your account is not active. Please verify your email and set your password", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	// Generate access token
-	accessToken, err := h.jwtManager.GenerateAccessToken(u.ID)
+	h.completeLogin(c, u, req.DeviceName)
+}
+
+// dummyPasswordHash is compared against when no account matches the login email
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// completeLogin finishes the first authentication step: accounts with two-factor
+// authentication get a challenge, all others get a new session
+func (h *AuthHandler) completeLogin(c *gin.Context, u *userdomain.User, deviceName *string) {
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(c.Request.Context(), u.ID)
 	if err != nil {
-		appErr := exceptions.InternalError("Failed to generate access token", nil, nil)
-		_ = c.Error(appErr)
+		_ = c.Error(err)
 		return
 	}
 
-	// Generate refresh token
-	refreshToken, err := h.jwtManager.GenerateRefreshToken(u.ID)
+	if twoFactorEnabled {
+		challenge, err := h.twoFactorService.CreateChallenge(c.Request.Context(), u.ID)
+		if err != nil {
+			appErr := exceptions.InternalError("Failed to create two-factor challenge", nil, nil)
+			_ = c.Error(appErr)
+			return
+		}
+
+		response.SendResponse(c, challenge, "Two-factor authentication required")
+		return
+	}
+
+	h.respondWithLogin(c, u, deviceName)
+}
+
+// respondWithLogin starts a new session for an authenticated user and sends the login response
+func (h *AuthHandler) respondWithLogin(c *gin.Context, u *userdomain.User, deviceName *string) {
+	// Start a new session and issue its token pair
+	accessToken, refreshToken, err := h.issueTokens(c, u, nil, deviceName)
 	if err != nil {
-		appErr := exceptions.InternalError("Failed to generate refresh token", nil, nil)
-		_ = c.Error(appErr)
+		_ = c.Error(err)
 		return
 	}
 
-	// Save refresh token to database
-	refreshTokenModel := &refreshtoken.RefreshToken{
-		UserID:    u.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(h.jwtManager.GetRefreshExpiry()),
-		Revoked:   false,
+	// Evict the oldest sessions once the concurrent session limit is exceeded
+	if err := h.refreshTokenService.EnforceSessionLimit(c.Request.Context(), u.ID); err != nil {
+		appErr := exceptions.InternalError("Failed to enforce session limit", nil, nil)
+		_ = c.Error(appErr)
+		return
 	}
 
-	_, err = h.refreshTokenService.Create(c.Request.Context(), refreshTokenModel)
-	if err != nil {
-		appErr := exceptions.InternalError("Failed to save refresh token", nil, nil)
+	if err := h.userService.RecordSignIn(c.Request.Context(), u.ID); err != nil {
+		appErr := exceptions.InternalError("Failed to record sign-in", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
+	h.recorder.Record(c.Request.Context(), u.ID, audit.EventLoginSucceeded, nil)
 
 	// Create response DTO
 	loginResponseDTO := auth.LoginResponseDTO{
@@ -167,17 +479,28 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		User:         userdomain.FromUserModel(*u),
 	}
 
+	// In cookie auth mode the refresh token never reaches JavaScript
+	if h.cookieOptions.Enabled {
+		csrfToken, err := h.setAuthCookies(c, refreshToken)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		loginResponseDTO.RefreshToken = ""
+		loginResponseDTO.CSRFToken = csrfToken
+	}
+
 	// Send success response
 	response.SendResponse(c, loginResponseDTO, "Login successful")
 }
 
 // RefreshToken generates a new access token using a valid refresh token
 // @Summary      Refresh access token
-// @Description  Generate a new access token using a valid refresh token
+// @Description  Generate a new access token using a valid refresh token. In cookie auth mode the token is read from the refresh_token cookie and the body may be omitted; the X-CSRF-Token header must then match the csrf_token cookie.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Param        refresh  body      auth.RefreshTokenRequest  true  "Refresh token"
+// @Param        refresh  body      auth.RefreshTokenRequest  false  "Refresh token"
 // @Success      200      {object}  response.Response{data=auth.RefreshTokenResponseDTO}
 // @Failure      400      {object}  response.ErrorResponse
 // @Failure      401      {object}  response.ErrorResponse
@@ -186,8 +509,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req auth.RefreshTokenRequest
 
-	// Bind and validate JSON request
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// Browser clients in cookie auth mode send the refresh token as a cookie
+	if cookieToken, ok := utils.RefreshTokenFromCookie(c, h.cookieOptions); ok {
+		req.RefreshToken = cookieToken
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		// Bind and validate JSON request
 		validationErrors := utils.ExtractBindingErrors(err)
 		if len(validationErrors) > 0 {
 			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
@@ -208,6 +534,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// REUSE DETECTION: a revoked token is rejected, and a replayed rotated one revokes its family
+	if dbRefreshToken != nil && dbRefreshToken.Revoked {
+		if err := h.refreshTokenService.HandleReuse(c.Request.Context(), dbRefreshToken); err != nil {
+			appErr := exceptions.InternalError("Failed to revoke refresh token family", nil, nil)
+			_ = c.Error(appErr)
+			return
+		}
+		appErr := exceptions.UnauthorizedError("Invalid or revoked refresh token", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
 	if dbRefreshToken == nil || !dbRefreshToken.IsValid() {
 		appErr := exceptions.UnauthorizedError("Invalid or revoked refresh token", nil, nil)
 		_ = c.Error(appErr)
@@ -229,45 +567,38 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// ROTATION STEP 1: Revoke the old refresh token
-	err = h.refreshTokenService.RevokeByToken(c.Request.Context(), req.RefreshToken)
-	if err != nil {
-		appErr := exceptions.InternalError("Failed to revoke old refresh token", nil, nil)
+	// Banned, deactivated or deleted accounts cannot obtain new tokens
+	u, err := h.userService.GetUserByID(c.Request.Context(), claims.UserID)
+	if err != nil || u == nil {
+		appErr := exceptions.UnauthorizedError("Invalid refresh token", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	// ROTATION STEP 2: Generate new access token
-	accessToken, err := h.jwtManager.GenerateAccessToken(claims.UserID)
-	if err != nil {
-		appErr := exceptions.InternalError("Failed to generate access token", nil, nil)
+	if u.Status != constant.UserStatusActive {
+		appErr := exceptions.UnauthorizedError("Your account is not active", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	// ROTATION STEP 3: Generate new refresh token
-	newRefreshToken, err := h.jwtManager.GenerateRefreshToken(claims.UserID)
+	// ROTATION STEP 1: Revoke the old refresh token
+	err = h.refreshTokenService.RotateByToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		appErr := exceptions.InternalError("Failed to generate refresh token", nil, nil)
+		appErr := exceptions.InternalError("Failed to revoke old refresh token", nil, nil)
 		_ = c.Error(appErr)
 		return
 	}
 
-	// ROTATION STEP 4: Save new refresh token to database
-	newRefreshTokenModel := &refreshtoken.RefreshToken{
-		UserID:    claims.UserID,
-		Token:     newRefreshToken,
-		ExpiresAt: time.Now().Add(h.jwtManager.GetRefreshExpiry()),
-		Revoked:   false,
-	}
-
-	_, err = h.refreshTokenService.Create(c.Request.Context(), newRefreshTokenModel)
+	// ROTATION STEP 2: Issue a new token pair in the same family
+	// The new token stays in the same family so reuse of any ancestor can revoke it
+	accessToken, newRefreshToken, err := h.issueTokens(c, u, dbRefreshToken, dbRefreshToken.DeviceName)
 	if err != nil {
-		appErr := exceptions.InternalError("Failed to save new refresh token", nil, nil)
-		_ = c.Error(appErr)
+		_ = c.Error(err)
 		return
 	}
 
+	h.recorder.Record(c.Request.Context(), u.ID, audit.EventTokenRefreshed, map[string]string{"session_id": dbRefreshToken.FamilyID})
+
 	// Create response DTO with both new tokens
 	refreshResponseDTO := auth.RefreshTokenResponseDTO{
 		AccessToken:  accessToken,
@@ -276,13 +607,500 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		ExpiresIn:    int64(h.jwtManager.GetAccessExpiry().Seconds()),
 	}
 
+	if h.cookieOptions.Enabled {
+		csrfToken, err := h.setAuthCookies(c, newRefreshToken)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		refreshResponseDTO.RefreshToken = ""
+		refreshResponseDTO.CSRFToken = csrfToken
+	}
+
 	// Send success response
 	response.SendResponse(c, refreshResponseDTO, "Token refreshed successfully")
 }
 
+// issueTokens generates an access/refresh token pair and persists the refresh token with device metadata
+// parent is the token being rotated; nil starts a new session (token family)
+func (h *AuthHandler) issueTokens(c *gin.Context, u *userdomain.User, parent *refreshtoken.RefreshToken, deviceName *string) (string, string, error) {
+	familyID := refreshtoken.NewFamilyID()
+	var parentID *string
+	if parent != nil {
+		familyID = parent.FamilyID
+		parentID = &parent.ID
+	}
+
+	accessToken, err := h.generateAccessToken(u, familyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Generate refresh token
+	refreshToken, err := h.jwtManager.GenerateRefreshToken(u.ID)
+	if err != nil {
+		return "", "", exceptions.InternalError("Failed to generate refresh token", nil, nil)
+	}
+
+	now := time.Now()
+	refreshTokenModel := &refreshtoken.RefreshToken{
+		UserID:     u.ID,
+		FamilyID:   familyID,
+		ParentID:   parentID,
+		TokenHash:  utils.HashToken(refreshToken),
+		ExpiresAt:  now.Add(h.jwtManager.GetRefreshExpiry()),
+		Revoked:    false,
+		UserAgent:  utils.OptionalTruncated(c.Request.UserAgent(), 512),
+		ClientIP:   utils.OptionalTruncated(c.ClientIP(), 45),
+		DeviceName: deviceName,
+		LastUsedAt: &now,
+	}
+
+	if _, err := h.refreshTokenService.Create(c.Request.Context(), refreshTokenModel); err != nil {
+		return "", "", exceptions.InternalError("Failed to save refresh token", nil, nil)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// setAuthCookies stores the refresh token and a new CSRF token in cookies for browser clients
+func (h *AuthHandler) setAuthCookies(c *gin.Context, refreshToken string) (string, error) {
+	csrfToken, err := utils.SetAuthCookies(c, h.cookieOptions, refreshToken, h.jwtManager.GetRefreshExpiry())
+	if err != nil {
+		return "", exceptions.InternalError("Failed to generate CSRF token", nil, nil)
+	}
+	return csrfToken, nil
+}
+
+// generateAccessToken issues an access token bound to the session, the current token version and the user's role
+func (h *AuthHandler) generateAccessToken(u *userdomain.User, sessionID string) (string, error) {
+	accessToken, err := h.jwtManager.GenerateAccessToken(u.ID,
+		utils.WithSessionID(sessionID),
+		utils.WithTokenVersion(u.TokenVersion),
+		utils.WithRole(string(u.Type)),
+	)
+	if err != nil {
+		return "", exceptions.InternalError("Failed to generate access token", nil, nil)
+	}
+	return accessToken, nil
+}
+
+// ListSessions lists the active sessions of the authenticated user
+// @Summary      List sessions
+// @Description  List the devices the authenticated user is logged in on. The session the request was made from is flagged as current.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]refreshtoken.SessionDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	sessions, err := h.refreshTokenService.ListActiveSessions(c.Request.Context(), userID)
+	if err != nil {
+		appErr := exceptions.InternalError("Failed to list sessions", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	response.SendResponse(c, refreshtoken.TransformSessionCollection(sessions, currentSessionID(c)), "Sessions retrieved successfully")
+}
+
+// RevokeSession revokes a single session of the authenticated user
+// @Summary      Revoke session
+// @Description  Log the authenticated user out of one device by revoking its session
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Session ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.refreshTokenService.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "Session revoked successfully", http.StatusOK)
+}
+
+// isIncorrectCurrentPassword reports whether err is the rejection of a wrong current password
+func isIncorrectCurrentPassword(err error) bool {
+	var appErr exceptions.AppError
+	if !errors.As(err, &appErr) || appErr.Type != exceptions.ErrorTypeValidation {
+		return false
+	}
+
+	fields, _ := appErr.Data.([]validators.ValidationError)
+	for _, field := range fields {
+		if field.Field == "current_password" {
+			return true
+		}
+	}
+	return false
+}
+
+// currentSessionID returns the session the access token of the request belongs to
+func currentSessionID(c *gin.Context) string {
+	claims, ok := utils.GetUserClaimsFromContext(c)
+	if !ok {
+		return ""
+	}
+	return claims.SessionID
+}
+
+// ChangePassword changes the password of the authenticated user and signs out every other session
+// @Summary      Change password
+// @Description  Verify the current password, enforce the password policy and set the new password. Repeated wrong current passwords trigger the same backoff and lockout as failed logins. Every other session is revoked. The new password invalidates all access tokens, so a fresh access token for the current session is returned; its refresh token stays valid.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      auth.ChangePasswordRequest  true  "Current and new password"
+// @Success      200      {object}  response.Response{data=auth.AccessTokenResponseDTO}
+// @Failure      401      {object}  response.ErrorResponse
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      429      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /auth/change-password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	var req auth.ChangePasswordRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	// Wrong current passwords are throttled per user, so a stolen session cannot guess the password
+	if err := h.loginThrottleService.CheckUser(c.Request.Context(), userID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.Password); err != nil {
+		if isIncorrectCurrentPassword(err) {
+			if err := h.loginThrottleService.RecordUserFailure(c.Request.Context(), userID); err != nil {
+				appErr := exceptions.InternalError("Failed to record password attempt", nil, nil)
+				_ = c.Error(appErr)
+				return
+			}
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.loginThrottleService.RecordUserSuccess(c.Request.Context(), userID); err != nil {
+		appErr := exceptions.InternalError("Failed to record password attempt", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	// Whoever else is signed in may have known the old password
+	sessionID := currentSessionID(c)
+	if err := h.refreshTokenService.RevokeOtherSessions(c.Request.Context(), userID, sessionID); err != nil {
+		appErr := exceptions.InternalError("Failed to revoke sessions", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	// The password change bumped the token version, so the caller's access token no longer works
+	u, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	accessToken, err := h.generateAccessToken(u, sessionID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, auth.AccessTokenResponseDTO{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.jwtManager.GetAccessExpiry().Seconds()),
+	}, "Password changed successfully")
+}
+
+// EnrollTwoFactor starts TOTP enrollment for the authenticated user
+// @Summary      Enroll in two-factor authentication
+// @Description  Generate a TOTP secret and otpauth:// URI for an authenticator app. Two-factor authentication is only enabled once the enrollment is confirmed with a code.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=twofactor.EnrollmentDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      422  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /auth/2fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactor(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, enrollment, "Scan the QR code with your authenticator app and confirm with a code")
+}
+
+// ConfirmTwoFactor enables two-factor authentication and returns the recovery codes
+// @Summary      Confirm two-factor enrollment
+// @Description  Verify a code from the authenticator app to enable two-factor authentication. The recovery codes are only shown in this response.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        confirmation  body      auth.TwoFactorConfirmRequest  true  "TOTP code"
+// @Success      200           {object}  response.Response{data=twofactor.RecoveryCodesDTO}
+// @Failure      401           {object}  response.ErrorResponse
+// @Failure      422           {object}  response.ErrorResponse
+// @Failure      500           {object}  response.ErrorResponse
+// @Router       /auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	var req auth.TwoFactorConfirmRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	recoveryCodes, err := h.twoFactorService.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, recoveryCodes, "Two-factor authentication enabled. Store your recovery codes in a safe place.")
+}
+
+// DisableTwoFactor turns two-factor authentication off
+// @Summary      Disable two-factor authentication
+// @Description  Turn two-factor authentication off. Requires the account password and a current TOTP or recovery code.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        credentials  body      auth.TwoFactorDisableRequest  true  "Password and code"
+// @Success      200          {object}  response.Response
+// @Failure      401          {object}  response.ErrorResponse
+// @Failure      422          {object}  response.ErrorResponse
+// @Failure      500          {object}  response.ErrorResponse
+// @Router       /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	var req auth.TwoFactorDisableRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req.Password, req.Code); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendSuccess(c, "Two-factor authentication disabled", http.StatusOK)
+}
+
+// VerifyTwoFactor completes a login with the second factor and returns JWT tokens
+// @Summary      Verify two-factor login
+// @Description  Exchange the challenge token returned by login and a TOTP or recovery code for access and refresh tokens. A challenge can be completed once and is invalidated after too many wrong codes. Wrong codes are also counted per user across challenges; too many lock the second factor (429) for TWO_FACTOR_LOCKOUT.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        verification  body      auth.TwoFactorVerifyRequest  true  "Challenge token and code"
+// @Success      200           {object}  response.Response{data=auth.LoginResponseDTO}
+// @Failure      401           {object}  response.ErrorResponse
+// @Failure      422           {object}  response.ErrorResponse
+// @Failure      429           {object}  response.ErrorResponse
+// @Failure      500           {object}  response.ErrorResponse
+// @Router       /auth/2fa/verify [post]
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req auth.TwoFactorVerifyRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	userID, err := h.twoFactorService.VerifyChallenge(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// The account may have been deactivated since the password step
+	u, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil || u == nil {
+		appErr := exceptions.UnauthorizedError("Invalid or expired challenge token", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	if u.Status != constant.UserStatusActive {
+		appErr := exceptions.UnauthorizedError("Your account is not active", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.respondWithLogin(c, u, req.DeviceName)
+}
+
+// SocialLoginRedirect starts a social login
+// @Summary      Start social login
+// @Description  Redirect to the provider's authorization endpoint (authorization-code flow with PKCE). The state, nonce and code verifier are kept server-side until the callback.
+// @Tags         auth
+// @Param        provider  path  string  true  "Provider name (google, github or the configured OIDC provider)"
+// @Success      302
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /auth/oauth/{provider} [get]
+func (h *AuthHandler) SocialLoginRedirect(c *gin.Context) {
+	authorizationURL, err := h.socialLoginService.AuthorizationURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authorizationURL)
+}
+
+// SocialLoginCallback completes a social login and returns JWT tokens
+// @Summary      Complete social login
+// @Description  Redirect target of the provider. Verifies the state, redeems the code, verifies the ID token and signs the linked user in, creating the account on first sign-in. Accounts with two-factor authentication receive a challenge instead of tokens.
+// @Tags         auth
+// @Produce      json
+// @Param        provider  path      string  true  "Provider name"
+// @Param        code      query     string  true  "Authorization code"
+// @Param        state     query     string  true  "State returned by the provider"
+// @Success      200       {object}  response.Response{data=auth.LoginResponseDTO}
+// @Failure      401       {object}  response.ErrorResponse
+// @Failure      403       {object}  response.ErrorResponse
+// @Failure      404       {object}  response.ErrorResponse
+// @Failure      422       {object}  response.ErrorResponse
+// @Failure      500       {object}  response.ErrorResponse
+// @Router       /auth/oauth/{provider}/callback [get]
+func (h *AuthHandler) SocialLoginCallback(c *gin.Context) {
+	// The provider reports a denied or failed authorization through the error parameter
+	if c.Query("error") != "" || c.Query("code") == "" {
+		appErr := exceptions.UnauthorizedError("Sign-in with the provider was not completed", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	u, err := h.socialLoginService.Authenticate(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if u.Status != constant.UserStatusActive {
+		appErr := exceptions.UnauthorizedError("Your account is not active", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.completeLogin(c, u, nil)
+}
+
+// JWKS publishes the public keys used to verify access tokens
+// @Summary      JSON Web Key Set
+// @Description  Public keys for verifying tokens signed with RS256 or EdDSA, selected by the token's kid header. Retired keys stay listed until the tokens they signed have expired. Empty when tokens are signed with the shared HS256 secret.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  utils.JWKSet
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// JWKS consumers expect the bare key set, not the response envelope
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
+
 // Logout revokes all refresh tokens for the authenticated user
 // @Summary      User logout
-// @Description  Revoke all refresh tokens for the authenticated user, effectively logging them out. Requires JWT authentication via Authorization header.
+// @Description  Revoke all refresh tokens and access tokens of the authenticated user, logging them out on every device. Requires JWT authentication via Authorization header. In cookie auth mode the auth cookies are cleared.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -308,5 +1126,18 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
+	// Cut off every access token issued so far, including the one used for this request
+	if err := h.userService.RevokeAccessTokens(c.Request.Context(), userID); err != nil {
+		appErr := exceptions.InternalError("Failed to revoke access tokens", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	h.recorder.Record(c.Request.Context(), userID, audit.EventLogout, nil)
+
+	if h.cookieOptions.Enabled {
+		utils.ClearAuthCookies(c, h.cookieOptions)
+	}
+
 	response.SendResponse(c, nil, "Logout successful")
 }
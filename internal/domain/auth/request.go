@@ -7,8 +7,9 @@ type SignupRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Email      string  `json:"email" binding:"required,email"`
+	Password   string  `json:"password" binding:"required,min=6"`
+	DeviceName *string `json:"device_name,omitempty" binding:"omitempty,max=100"`
 }
 
 // RefreshTokenRequest represents the request payload for refreshing access token
@@ -19,3 +20,67 @@ type RefreshTokenRequest struct {
 type LogoutRequest struct {
 	AccessToken string `json:"access_token" binding:"required"`
 }
+
+// VerifyEmailRequest represents the payload for verifying an email and setting the initial password
+type VerifyEmailRequest struct {
+	Token                string `json:"token" binding:"required"`
+	Password             string `json:"password" binding:"required"`
+	PasswordConfirmation string `json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+// ResendVerificationRequest represents the payload for requesting a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPasswordRequest represents the payload for requesting a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the payload for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token                string `json:"token" binding:"required"`
+	Password             string `json:"password" binding:"required"`
+	PasswordConfirmation string `json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+// ChangePasswordRequest represents the payload for changing the password of the authenticated user
+type ChangePasswordRequest struct {
+	CurrentPassword      string `json:"current_password" binding:"required"`
+	Password             string `json:"password" binding:"required"`
+	PasswordConfirmation string `json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+// MagicLinkRequest represents the payload for requesting a passwordless login link
+// DeviceID is an optional random value kept by the client; when set, only a consume request carrying it succeeds
+type MagicLinkRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	DeviceID string `json:"device_id,omitempty" binding:"omitempty,min=16,max=255"`
+}
+
+// ConsumeMagicLinkRequest represents the payload for signing in with a magic link
+type ConsumeMagicLinkRequest struct {
+	Token      string  `json:"token" binding:"required"`
+	DeviceID   string  `json:"device_id,omitempty" binding:"omitempty,max=255"`
+	DeviceName *string `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// TwoFactorConfirmRequest represents the payload for confirming a TOTP enrollment
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorVerifyRequest represents the payload for completing a login with the second factor
+// Code accepts either a TOTP code or a recovery code
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string  `json:"challenge_token" binding:"required"`
+	Code           string  `json:"code" binding:"required"`
+	DeviceName     *string `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// TwoFactorDisableRequest represents the payload for turning two-factor authentication off
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	refreshsvc "gin/internal/domain/refresh_token/service"
+	usersvc "gin/internal/domain/user/service"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+)
+
+// AccessTokenService decides whether a validly signed access token is still honoured
+type AccessTokenService struct {
+	userService         usersvc.UserServiceInterface
+	refreshTokenService refreshsvc.RefreshTokenServiceInterface
+}
+
+// NewAccessTokenService creates a new access token service
+func NewAccessTokenService(
+	userService usersvc.UserServiceInterface,
+	refreshTokenService refreshsvc.RefreshTokenServiceInterface,
+) AccessTokenServiceInterface {
+	return &AccessTokenService{
+		userService:         userService,
+		refreshTokenService: refreshTokenService,
+	}
+}
+
+// IsAccessTokenValid rejects tokens issued before the user's token version was bumped
+// (logout, ban, password change, deletion) and tokens of individually revoked sessions
+func (s *AccessTokenService) IsAccessTokenValid(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	version, err := s.userService.GetTokenVersion(ctx, claims.UserID)
+	if err != nil {
+		var appErr exceptions.AppError
+		if errors.As(err, &appErr) && appErr.Type == exceptions.ErrorTypeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if claims.TokenVersion != version {
+		return false, nil
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := s.refreshTokenService.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil {
+			return false, err
+		}
+		if revoked {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"gin/internal/shared/utils"
+)
+
+type AccessTokenServiceInterface interface {
+	IsAccessTokenValid(ctx context.Context, claims *utils.JWTClaims) (bool, error)
+}
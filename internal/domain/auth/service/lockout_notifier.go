@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/mail"
+)
+
+// LockoutEvent describes a temporary lockout caused by repeated failed logins
+// Exactly one of Email, UserID and IP is set; UserID marks wrong passwords given by a signed-in user
+type LockoutEvent struct {
+	Email    string
+	UserID   string
+	IP       string
+	Attempts int64
+	Until    time.Time
+}
+
+// LockoutNotifier is called whenever an email or IP gets locked out
+// Replace the default implementation (e.g. with fx.Decorate) to alert a SIEM, Slack or a webhook
+type LockoutNotifier interface {
+	NotifyLockout(ctx context.Context, event LockoutEvent) error
+}
+
+// MailLockoutNotifier warns the account owner by email when their account is locked out
+type MailLockoutNotifier struct {
+	userService usersvc.UserServiceInterface
+	mailer      mail.Mailer
+}
+
+// NewLockoutNotifier creates the default lockout notifier
+func NewLockoutNotifier(userService usersvc.UserServiceInterface, mailer mail.Mailer) LockoutNotifier {
+	return &MailLockoutNotifier{
+		userService: userService,
+		mailer:      mailer,
+	}
+}
+
+// NotifyLockout emails the owner of a locked account; IP lockouts are only logged by the throttle
+func (n *MailLockoutNotifier) NotifyLockout(ctx context.Context, event LockoutEvent) error {
+	var (
+		u   *user.User
+		err error
+	)
+	switch {
+	case event.Email != "":
+		u, err = n.userService.GetUserByEmail(ctx, event.Email)
+	case event.UserID != "":
+		u, err = n.userService.GetUserByID(ctx, event.UserID)
+	default:
+		return nil
+	}
+	if err != nil || u == nil {
+		return err
+	}
+
+	return n.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Your account was temporarily locked",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>We temporarily locked your account until %s after %d incorrect password attempts.</p><p>If this wasn't you, we recommend resetting your password.</p>",
+			html.EscapeString(u.FullName()), event.Until.UTC().Format("15:04 MST"), event.Attempts,
+		),
+	})
+}
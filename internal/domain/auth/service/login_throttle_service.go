@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin/internal/infra/logger"
+	"gin/internal/shared/cache"
+	exceptions "gin/internal/shared/exception"
+)
+
+// LoginThrottleOptions configures brute-force protection for password logins
+type LoginThrottleOptions struct {
+	// MaxAttempts is the number of failures per email within Window that locks the email out
+	MaxAttempts int
+	// IPMaxAttempts is the number of failures per client IP within Window that locks the IP out
+	IPMaxAttempts int
+	Window        time.Duration
+	// LockoutDuration is how long a lockout lasts
+	LockoutDuration time.Duration
+	// BackoffBase is the delay after the second failure; it doubles with every further failure up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// LoginThrottleService tracks failed logins per email and per client IP
+// Emails are tracked whether or not an account exists, so throttling does not reveal registered addresses
+type LoginThrottleService struct {
+	cache    cache.Cache
+	notifier LockoutNotifier
+	options  LoginThrottleOptions
+}
+
+// NewLoginThrottleService creates a new login throttle service
+func NewLoginThrottleService(cache cache.Cache, notifier LockoutNotifier, options LoginThrottleOptions) LoginThrottleServiceInterface {
+	return &LoginThrottleService{
+		cache:    cache,
+		notifier: notifier,
+		options:  options,
+	}
+}
+
+// Check rejects a login attempt while the email or the client IP is in backoff or locked out
+func (s *LoginThrottleService) Check(ctx context.Context, email string, clientIP string) error {
+	for _, key := range []string{blockedEmailKey(email), blockedIPKey(clientIP)} {
+		until, err := s.blockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if wait := time.Until(until); wait > 0 {
+			desc := fmt.Sprintf("Please wait %d seconds before trying again.", int(wait.Seconds())+1)
+			return exceptions.TooManyRequestsError("Too many failed login attempts", &desc, nil)
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login
+// Each failure for an email doubles its backoff; reaching a threshold locks the email or IP out and notifies
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, email string, clientIP string) error {
+	email = normalizeEmail(email)
+
+	if err := s.countFailure(ctx, failuresEmailKey(email), blockedEmailKey(email), LockoutEvent{Email: email}); err != nil {
+		return err
+	}
+
+	// Shared IPs (offices, mobile carriers) only get the lockout, with a higher threshold
+	ipFailures, err := s.cache.Increment(ctx, failuresIPKey(clientIP), s.options.Window)
+	if err != nil {
+		return err
+	}
+
+	if s.options.IPMaxAttempts > 0 && ipFailures >= int64(s.options.IPMaxAttempts) {
+		return s.lockOut(ctx, failuresIPKey(clientIP), blockedIPKey(clientIP), LockoutEvent{IP: clientIP, Attempts: ipFailures})
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the failures of an email after a successful login
+// The IP counter is kept so an attacker cannot reset it by logging into their own account
+func (s *LoginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	if err := s.cache.Delete(ctx, failuresEmailKey(email)); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, blockedEmailKey(email))
+}
+
+// CheckUser rejects a password confirmation by a signed-in user while that user is in backoff or locked out
+func (s *LoginThrottleService) CheckUser(ctx context.Context, userID string) error {
+	until, err := s.blockedUntil(ctx, blockedUserKey(userID))
+	if err != nil {
+		return err
+	}
+
+	if wait := time.Until(until); wait > 0 {
+		desc := fmt.Sprintf("Please wait %d seconds before trying again.", int(wait.Seconds())+1)
+		return exceptions.TooManyRequestsError("Too many incorrect password attempts", &desc, nil)
+	}
+	return nil
+}
+
+// RecordUserFailure counts a wrong password given by a signed-in user, e.g. when changing it
+// A stolen session gets the same backoff and lockout as a password login, keyed by the user instead of the email
+func (s *LoginThrottleService) RecordUserFailure(ctx context.Context, userID string) error {
+	return s.countFailure(ctx, failuresUserKey(userID), blockedUserKey(userID), LockoutEvent{UserID: userID})
+}
+
+// RecordUserSuccess clears the failures of a user after a correct password
+func (s *LoginThrottleService) RecordUserSuccess(ctx context.Context, userID string) error {
+	if err := s.cache.Delete(ctx, failuresUserKey(userID)); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, blockedUserKey(userID))
+}
+
+// countFailure increments a failure counter and either locks the key out or imposes the backoff
+func (s *LoginThrottleService) countFailure(ctx context.Context, failuresKey string, blockedKey string, event LockoutEvent) error {
+	failures, err := s.cache.Increment(ctx, failuresKey, s.options.Window)
+	if err != nil {
+		return err
+	}
+
+	if s.options.MaxAttempts > 0 && failures >= int64(s.options.MaxAttempts) {
+		event.Attempts = failures
+		return s.lockOut(ctx, failuresKey, blockedKey, event)
+	}
+
+	if delay := s.backoff(failures); delay > 0 {
+		until := time.Now().Add(delay)
+		return s.cache.Set(ctx, blockedKey, strconv.FormatInt(until.UnixNano(), 10), delay)
+	}
+	return nil
+}
+
+// lockOut blocks a key for the lockout duration, restarts its failure count and sends the notification
+func (s *LoginThrottleService) lockOut(ctx context.Context, failuresKey string, blockedKey string, event LockoutEvent) error {
+	event.Until = time.Now().Add(s.options.LockoutDuration)
+
+	if err := s.cache.Set(ctx, blockedKey, strconv.FormatInt(event.Until.UnixNano(), 10), s.options.LockoutDuration); err != nil {
+		return err
+	}
+	if err := s.cache.Delete(ctx, failuresKey); err != nil {
+		return err
+	}
+
+	logger.LogInfo("Login locked out", map[string]interface{}{
+		"email":    event.Email,
+		"user_id":  event.UserID,
+		"ip":       event.IP,
+		"attempts": event.Attempts,
+		"until":    event.Until.Format(time.RFC3339),
+	})
+
+	// Notify in the background so the response time does not depend on whether an account exists
+	// The request context is not reused: it is cancelled, and its transaction closed, once the response is sent
+	go func() {
+		if err := s.notifier.NotifyLockout(context.Background(), event); err != nil {
+			logger.LogError(err, "Failed to send lockout notification", map[string]interface{}{"email": event.Email, "user_id": event.UserID, "ip": event.IP})
+		}
+	}()
+
+	return nil
+}
+
+// backoff returns the delay imposed after the given number of consecutive failures
+// The first failure is free; the second waits BackoffBase, and each further failure doubles the wait
+func (s *LoginThrottleService) backoff(failures int64) time.Duration {
+	if failures < 2 || s.options.BackoffBase <= 0 {
+		return 0
+	}
+
+	delay := s.options.BackoffBase
+	for i := int64(2); i < failures; i++ {
+		delay *= 2
+		if s.options.BackoffMax > 0 && delay >= s.options.BackoffMax {
+			return s.options.BackoffMax
+		}
+	}
+	return delay
+}
+
+// blockedUntil returns the end of the backoff or lockout stored under key
+func (s *LoginThrottleService) blockedUntil(ctx context.Context, key string) (time.Time, error) {
+	value, ok, err := s.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return time.Time{}, err
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// normalizeEmail makes counters case-insensitive so "A@x.com" and "a@x.com" share a limit
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func failuresEmailKey(email string) string {
+	return "login:failures:email:" + normalizeEmail(email)
+}
+
+func failuresIPKey(clientIP string) string {
+	return "login:failures:ip:" + clientIP
+}
+
+func blockedEmailKey(email string) string {
+	return "login:blocked:email:" + normalizeEmail(email)
+}
+
+func blockedIPKey(clientIP string) string {
+	return "login:blocked:ip:" + clientIP
+}
+
+func failuresUserKey(userID string) string {
+	return "login:failures:user:" + userID
+}
+
+func blockedUserKey(userID string) string {
+	return "login:blocked:user:" + userID
+}
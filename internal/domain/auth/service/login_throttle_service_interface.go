@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+)
+
+type LoginThrottleServiceInterface interface {
+	Check(ctx context.Context, email string, clientIP string) error
+	RecordFailure(ctx context.Context, email string, clientIP string) error
+	RecordSuccess(ctx context.Context, email string) error
+	CheckUser(ctx context.Context, userID string) error
+	RecordUserFailure(ctx context.Context, userID string) error
+	RecordUserSuccess(ctx context.Context, userID string) error
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/cache"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/oauth"
+	"gin/internal/shared/utils"
+)
+
+// SocialLoginOptions configures the redirect URI and how long a started sign-in stays valid
+type SocialLoginOptions struct {
+	// CallbackURL is the redirect URI registered with the providers; {provider} is replaced by the provider name
+	CallbackURL string
+	StateTTL    time.Duration
+}
+
+// SocialLoginService signs users in through OAuth2/OIDC providers
+type SocialLoginService struct {
+	userService usersvc.UserServiceInterface
+	providers   *oauth.Registry
+	cache       cache.Cache
+	recorder    audit.Recorder
+	options     SocialLoginOptions
+}
+
+// NewSocialLoginService creates a new social login service
+func NewSocialLoginService(
+	userService usersvc.UserServiceInterface,
+	providers *oauth.Registry,
+	cache cache.Cache,
+	recorder audit.Recorder,
+	options SocialLoginOptions,
+) SocialLoginServiceInterface {
+	return &SocialLoginService{
+		userService: userService,
+		providers:   providers,
+		cache:       cache,
+		recorder:    recorder,
+		options:     options,
+	}
+}
+
+// pendingSignIn is stored under the state parameter until the provider redirects back
+type pendingSignIn struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"codeVerifier"`
+	Nonce        string `json:"nonce"`
+}
+
+// AuthorizationURL starts a sign-in and returns the provider URL to redirect the user to
+// The PKCE verifier and nonce stay server-side, keyed by the random state
+func (s *SocialLoginService) AuthorizationURL(ctx context.Context, providerName string) (string, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oauth.GenerateCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	pending, err := json.Marshal(pendingSignIn{Provider: providerName, CodeVerifier: verifier, Nonce: nonce})
+	if err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, stateCacheKey(state), string(pending), s.options.StateTTL); err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(ctx, oauth.AuthorizationRequest{
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: oauth.CodeChallengeS256(verifier),
+		RedirectURI:   s.redirectURI(providerName),
+	})
+}
+
+// Authenticate completes a sign-in and returns the linked user, creating one on first sign-in
+// An existing account with the same verified email is linked to the provider account
+func (s *SocialLoginService) Authenticate(ctx context.Context, providerName string, code string, state string) (*user.User, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.consumeState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil || pending.Provider != providerName {
+		return nil, exceptions.UnauthorizedError("Invalid or expired sign-in state", nil, nil)
+	}
+
+	identity, err := provider.Exchange(ctx, oauth.ExchangeRequest{
+		Code:         code,
+		CodeVerifier: pending.CodeVerifier,
+		Nonce:        pending.Nonce,
+		RedirectURI:  s.redirectURI(providerName),
+	})
+	if err != nil {
+		logger.LogError(err, "Social login failed", map[string]interface{}{"provider": providerName})
+		return nil, exceptions.UnauthorizedError("Sign-in with the provider failed", nil, nil)
+	}
+
+	linkedUser, err := s.userService.GetUserBySocialProvider(ctx, providerName, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linkedUser != nil {
+		return linkedUser, nil
+	}
+
+	// Accounts are matched by email, so only addresses the provider has verified are trusted
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, exceptions.ForbiddenError("The provider did not confirm a verified email address", nil, nil)
+	}
+
+	existingUser, err := s.userService.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser == nil {
+		createdUser, err := s.userService.CreateSocialUser(ctx, user.SocialSignupInput{
+			FirstName:        identity.FirstName,
+			LastName:         identity.LastName,
+			Email:            identity.Email,
+			SocialProvider:   providerName,
+			SocialProviderID: identity.Subject,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The event is written once the callback transaction commits, since the user row only exists inside it
+		s.recorder.Record(ctx, createdUser.ID, audit.EventSocialSignup, map[string]string{"provider": providerName})
+		return createdUser, nil
+	}
+
+	if existingUser.SocialProvider != nil && *existingUser.SocialProvider != "" && *existingUser.SocialProvider != constant.SocialProviderEmailPassword {
+		return nil, exceptions.ValidationError("An account with this email is already linked to another sign-in provider", nil, nil)
+	}
+
+	if err := s.userService.LinkSocialProvider(ctx, existingUser.ID, providerName, identity.Subject); err != nil {
+		return nil, err
+	}
+	existingUser.SocialProvider = &providerName
+	existingUser.SocialProviderID = &identity.Subject
+
+	// A pending signup is completed by the provider's email verification
+	if existingUser.Status == constant.UserStatusInactive {
+		if err := s.userService.UpdateStatus(ctx, existingUser.ID, constant.UserStatusActive); err != nil {
+			return nil, err
+		}
+		existingUser.Status = constant.UserStatusActive
+	}
+
+	s.recorder.Record(ctx, existingUser.ID, audit.EventSocialProviderLinked, map[string]string{"provider": providerName})
+	return existingUser, nil
+}
+
+// provider looks up a configured provider
+func (s *SocialLoginService) provider(name string) (oauth.Provider, error) {
+	provider, ok := s.providers.Get(name)
+	if !ok {
+		return nil, exceptions.NotFoundError("Sign-in provider not found", nil, nil)
+	}
+	return provider, nil
+}
+
+// consumeState returns the pending sign-in for a state exactly once
+func (s *SocialLoginService) consumeState(ctx context.Context, state string) (*pendingSignIn, error) {
+	if state == "" {
+		return nil, nil
+	}
+
+	raw, ok, err := s.cache.Get(ctx, stateCacheKey(state))
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// The counter makes the state single-use even when two callbacks race
+	uses, err := s.cache.Increment(ctx, stateCacheKey(state)+":used", s.options.StateTTL)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cache.Delete(ctx, stateCacheKey(state))
+	if uses > 1 {
+		return nil, nil
+	}
+
+	var pending pendingSignIn
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return nil, nil
+	}
+	return &pending, nil
+}
+
+// redirectURI returns the callback URL registered for a provider
+func (s *SocialLoginService) redirectURI(providerName string) string {
+	return strings.ReplaceAll(s.options.CallbackURL, "{provider}", providerName)
+}
+
+// stateCacheKey returns the cache key holding a pending sign-in
+func stateCacheKey(state string) string {
+	return "oauth:state:" + state
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/user"
+)
+
+type SocialLoginServiceInterface interface {
+	AuthorizationURL(ctx context.Context, provider string) (string, error)
+	Authenticate(ctx context.Context, provider string, code string, state string) (*user.User, error)
+}
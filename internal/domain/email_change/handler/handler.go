@@ -0,0 +1,61 @@
+package handler
+
+import (
+	emailchange "gin/internal/domain/email_change"
+	emailchangesvc "gin/internal/domain/email_change/service"
+	"gin/internal/domain/user"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/response"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailChangeHandler handles HTTP requests for confirming email changes
+type EmailChangeHandler struct {
+	emailChangeService emailchangesvc.EmailChangeServiceInterface
+}
+
+// NewEmailChangeHandler creates a new email change handler
+func NewEmailChangeHandler(emailChangeService emailchangesvc.EmailChangeServiceInterface) *EmailChangeHandler {
+	return &EmailChangeHandler{
+		emailChangeService: emailChangeService,
+	}
+}
+
+// ConfirmEmailChange swaps a user's email for the address the confirmation link was sent to
+// @Summary      Confirm email change
+// @Description  Consume the single-use token emailed to the new address and make it the account's email. Fails with 422 when the token is invalid or expired, or when another account has taken the address since the change was requested.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      emailchange.ConfirmEmailChangeRequest  true  "Confirmation token"
+// @Success      200      {object}  response.Response{data=user.UserDTO}
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /auth/email-change/confirm [post]
+func (h *EmailChangeHandler) ConfirmEmailChange(c *gin.Context) {
+	var req emailchange.ConfirmEmailChangeRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	updatedUser, err := h.emailChangeService.Confirm(c.Request.Context(), req.Token)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, user.FromUserModel(*updatedUser), "email address changed successfully")
+}
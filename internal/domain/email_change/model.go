@@ -0,0 +1,47 @@
+package emailchange
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// EmailChangeRequest represents a pending change of a user's email address
+// The address is only swapped once the link sent to the new address is confirmed;
+// only the SHA-256 digest of the confirmation token is stored
+type EmailChangeRequest struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID    string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	NewEmail  string     `json:"new_email" gorm:"type:varchar(255);not null"`
+	TokenHash string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (r *EmailChangeRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		r.ID = id.String()
+	}
+	return nil
+}
+
+// IsExpired checks if the confirmation link is expired
+func (r *EmailChangeRequest) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// IsUsed checks if the change was already confirmed or superseded
+func (r *EmailChangeRequest) IsUsed() bool {
+	return r.UsedAt != nil
+}
+
+// TableName specifies the table name for the EmailChangeRequest model
+func (EmailChangeRequest) TableName() string {
+	return "email_change_requests"
+}
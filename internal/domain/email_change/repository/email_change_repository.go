@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	emailchange "gin/internal/domain/email_change"
+
+	"gorm.io/gorm"
+)
+
+// EmailChangeRepository handles pending email change database operations
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *EmailChangeRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new pending email change
+func (r *EmailChangeRepository) Create(ctx context.Context, request *emailchange.EmailChangeRequest) (*emailchange.EmailChangeRequest, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(request).Error; err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// FindByTokenHash finds a pending email change by the digest of its confirmation token
+func (r *EmailChangeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*emailchange.EmailChangeRequest, error) {
+	var request emailchange.EmailChangeRequest
+	err := r.getDB(ctx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&request).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// MarkUsed marks a pending email change as consumed
+// Returns false when it was already consumed by a concurrent request
+func (r *EmailChangeRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&emailchange.EmailChangeRequest{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateUserRequests consumes every pending email change of a user
+func (r *EmailChangeRepository) InvalidateUserRequests(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&emailchange.EmailChangeRequest{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
@@ -0,0 +1,6 @@
+package emailchange
+
+// ConfirmEmailChangeRequest represents the payload for confirming a pending email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
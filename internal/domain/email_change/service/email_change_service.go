@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	emailchange "gin/internal/domain/email_change"
+	emailChangeRepository "gin/internal/domain/email_change/repository"
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/mail"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// Options configures the confirmation link lifetime and the frontend confirmation URL
+type Options struct {
+	Expiry time.Duration
+	URL    string
+}
+
+// EmailChangeService implements EmailChangeServiceInterface
+type EmailChangeService struct {
+	changeRepo  *emailChangeRepository.EmailChangeRepository
+	userService usersvc.UserServiceInterface
+	mailer      mail.Mailer
+	recorder    audit.Recorder
+	options     Options
+}
+
+// NewEmailChangeService creates a new email change service
+func NewEmailChangeService(
+	changeRepo *emailChangeRepository.EmailChangeRepository,
+	userService usersvc.UserServiceInterface,
+	mailer mail.Mailer,
+	recorder audit.Recorder,
+	options Options,
+) EmailChangeServiceInterface {
+	return &EmailChangeService{
+		changeRepo:  changeRepo,
+		userService: userService,
+		mailer:      mailer,
+		recorder:    recorder,
+		options:     options,
+	}
+}
+
+// RequestChange records a pending email change, emails a confirmation link to the new
+// address and a notice to the current one. The address is not changed until confirmation
+// Any earlier pending change is superseded so only the latest link works
+// Reports false when the new address is the current one and there is nothing to change
+func (s *EmailChangeService) RequestChange(ctx context.Context, userID string, newEmail string) (bool, error) {
+	u, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if u.Type == constant.AccountTypeService {
+		return false, exceptions.ForbiddenError("Service accounts have no email address to change", nil, nil)
+	}
+
+	if u.Email == newEmail {
+		return false, nil
+	}
+
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return false, err
+	}
+
+	if err := s.changeRepo.InvalidateUserRequests(ctx, u.ID); err != nil {
+		return false, err
+	}
+
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.changeRepo.Create(ctx, &emailchange.EmailChangeRequest{
+		UserID:    u.ID,
+		NewEmail:  newEmail,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.options.Expiry),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	link := s.options.URL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      []string{newEmail},
+		Subject: "Confirm your new email address",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>Please confirm that you want to use this address for your account by visiting the link below:</p><p><a href=\"%s\">%s</a></p><p>This link expires in %s. Until then your account keeps its current email address.</p>",
+			html.EscapeString(u.FullName()), link, link, s.options.Expiry,
+		),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Warn the current address so an unexpected change can be caught before it is confirmed
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Your email address is about to change",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>We received a request to change the email address of your account to %s. The change takes effect once the new address is confirmed.</p><p>If you did not request this, reset your password right away.</p>",
+			html.EscapeString(u.FullName()), html.EscapeString(newEmail),
+		),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.recorder.Record(ctx, u.ID, audit.EventEmailChangeRequested, nil)
+	return true, nil
+}
+
+// Confirm consumes a confirmation token and swaps the user's email for the new address
+// The new address is checked again, since another account may have claimed it in the meantime
+func (s *EmailChangeService) Confirm(ctx context.Context, token string) (*user.User, error) {
+	request, err := s.changeRepo.FindByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if request == nil || request.IsUsed() || request.IsExpired() {
+		return nil, invalidTokenError()
+	}
+
+	consumed, err := s.changeRepo.MarkUsed(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, invalidTokenError()
+	}
+
+	if err := s.ensureEmailAvailable(ctx, request.NewEmail); err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := s.userService.UpdateUser(ctx, map[string]interface{}{
+		"email": request.NewEmail,
+	}, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recorder.Record(ctx, request.UserID, audit.EventEmailChanged, nil)
+	return updatedUser, nil
+}
+
+// ensureEmailAvailable rejects addresses already used by another account, including deleted ones
+func (s *EmailChangeService) ensureEmailAvailable(ctx context.Context, email string) error {
+	taken, err := s.userService.IsEmailTaken(ctx, email)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "email", Message: "The email has already been taken."},
+		})
+	}
+	return nil
+}
+
+// invalidTokenError returns the validation error for unknown, used or expired confirmation tokens
+func invalidTokenError() error {
+	return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+		{Field: "token", Message: "The email change token is invalid or has expired."},
+	})
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/user"
+)
+
+type EmailChangeServiceInterface interface {
+	RequestChange(ctx context.Context, userID string, newEmail string) (bool, error)
+	Confirm(ctx context.Context, token string) (*user.User, error)
+}
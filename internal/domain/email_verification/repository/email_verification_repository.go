@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	emailverification "gin/internal/domain/email_verification"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerificationRepository handles email verification token database operations
+type EmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationRepository creates a new email verification repository
+func NewEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *EmailVerificationRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new verification token
+func (r *EmailVerificationRepository) Create(ctx context.Context, token *emailverification.EmailVerificationToken) (*emailverification.EmailVerificationToken, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// FindByTokenHash finds a verification token by its digest
+func (r *EmailVerificationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*emailverification.EmailVerificationToken, error) {
+	var token emailverification.EmailVerificationToken
+	err := r.getDB(ctx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindLatestByUserID finds the most recently issued verification token for a user
+func (r *EmailVerificationRepository) FindLatestByUserID(ctx context.Context, userID string) (*emailverification.EmailVerificationToken, error) {
+	var token emailverification.EmailVerificationToken
+	err := r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a verification token as consumed
+// Returns false when the token was already consumed by a concurrent request
+func (r *EmailVerificationRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&emailverification.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateUserTokens consumes every outstanding verification token of a user
+func (r *EmailVerificationRepository) InvalidateUserTokens(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&emailverification.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	emailverification "gin/internal/domain/email_verification"
+	emailVerificationRepository "gin/internal/domain/email_verification/repository"
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/mail"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// Options configures token lifetime, resend cooldown and the frontend verification URL
+type Options struct {
+	Expiry         time.Duration
+	ResendCooldown time.Duration
+	URL            string
+}
+
+// EmailVerificationService implements EmailVerificationServiceInterface
+type EmailVerificationService struct {
+	verificationRepo *emailVerificationRepository.EmailVerificationRepository
+	userService      usersvc.UserServiceInterface
+	mailer           mail.Mailer
+	options          Options
+}
+
+// NewEmailVerificationService creates a new email verification service
+func NewEmailVerificationService(
+	verificationRepo *emailVerificationRepository.EmailVerificationRepository,
+	userService usersvc.UserServiceInterface,
+	mailer mail.Mailer,
+	options Options,
+) EmailVerificationServiceInterface {
+	return &EmailVerificationService{
+		verificationRepo: verificationRepo,
+		userService:      userService,
+		mailer:           mailer,
+		options:          options,
+	}
+}
+
+// SendVerification issues a new verification token for the user and emails the link
+// Any previously issued token is invalidated so only the latest link works
+func (s *EmailVerificationService) SendVerification(ctx context.Context, u *user.User) error {
+	if err := s.verificationRepo.InvalidateUserTokens(ctx, u.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.verificationRepo.Create(ctx, &emailverification.EmailVerificationToken{
+		UserID:    u.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.options.Expiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.options.URL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Verify your email address",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>Please verify your email address and set your password by visiting the link below:</p><p><a href=\"%s\">%s</a></p><p>This link expires in %s.</p>",
+			html.EscapeString(u.FullName()), link, link, s.options.Expiry,
+		),
+	})
+}
+
+// Resend sends a fresh verification email to an inactive account
+// Unknown and already verified emails are ignored, and so are requests inside the cooldown
+// window, so the response does not reveal account state
+func (s *EmailVerificationService) Resend(ctx context.Context, email string) error {
+	u, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if u == nil || u.Status != constant.UserStatusInactive {
+		return nil
+	}
+
+	latest, err := s.verificationRepo.FindLatestByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && time.Now().Before(latest.CreatedAt.Add(s.options.ResendCooldown)) {
+		return nil
+	}
+
+	return s.SendVerification(ctx, u)
+}
+
+// Verify consumes a verification token, sets the user's password and activates the account
+func (s *EmailVerificationService) Verify(ctx context.Context, token string, password string) (*user.User, error) {
+	record, err := s.verificationRepo.FindByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil || record.IsUsed() {
+		return nil, invalidTokenError("The verification token is invalid or has already been used.")
+	}
+
+	if record.IsExpired() {
+		return nil, invalidTokenError("The verification token has expired. Please request a new verification email.")
+	}
+
+	u, err := s.userService.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Status == constant.UserStatusBanned {
+		return nil, exceptions.ForbiddenError("This account has been suspended", nil, nil)
+	}
+
+	consumed, err := s.verificationRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, invalidTokenError("The verification token is invalid or has already been used.")
+	}
+
+	if err := s.userService.SetPassword(ctx, u.ID, password); err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.UpdateStatus(ctx, u.ID, constant.UserStatusActive); err != nil {
+		return nil, err
+	}
+
+	// Older links for the same account must not remain usable
+	if err := s.verificationRepo.InvalidateUserTokens(ctx, u.ID); err != nil {
+		return nil, err
+	}
+
+	u.Status = constant.UserStatusActive
+	return u, nil
+}
+
+// invalidTokenError builds a Laravel-style validation error for the token field
+func invalidTokenError(message string) error {
+	return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+		{Field: "token", Message: message},
+	})
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/user"
+)
+
+type EmailVerificationServiceInterface interface {
+	SendVerification(ctx context.Context, u *user.User) error
+	Resend(ctx context.Context, email string) error
+	Verify(ctx context.Context, token string, password string) (*user.User, error)
+}
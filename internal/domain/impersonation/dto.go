@@ -0,0 +1,13 @@
+package impersonation
+
+import "gin/internal/domain/user"
+
+// ImpersonationTokenDTO is the short-lived access token of an impersonation session
+// No refresh token is issued; a new session must be started once the token expires
+type ImpersonationTokenDTO struct {
+	SessionID   string       `json:"sessionId"`
+	AccessToken string       `json:"accessToken"`
+	TokenType   string       `json:"tokenType"`
+	ExpiresIn   int64        `json:"expiresIn"`
+	User        user.UserDTO `json:"user"`
+}
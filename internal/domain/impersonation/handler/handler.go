@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"gin/internal/domain/impersonation"
+	impersonationsvc "gin/internal/domain/impersonation/service"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/response"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHandler handles HTTP requests for admin impersonation
+type ImpersonationHandler struct {
+	impersonationService impersonationsvc.ImpersonationServiceInterface
+}
+
+// NewImpersonationHandler creates a new impersonation handler
+func NewImpersonationHandler(impersonationService impersonationsvc.ImpersonationServiceInterface) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+	}
+}
+
+// Impersonate issues an access token acting as another user
+// @Summary      Impersonate user
+// @Description  Issue a short-lived access token for the user, with the calling admin named in the "act" claim. The token cannot be refreshed and cannot change credentials, end sessions or delete the account. Every session is recorded in the audit trail. Requires the admin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId  path      string                            true  "User ID"
+// @Param        reason  body      impersonation.ImpersonateRequest  true  "Reason kept in the audit trail"
+// @Success      201     {object}  response.Response{data=impersonation.ImpersonationTokenDTO}
+// @Failure      401     {object}  response.ErrorResponse
+// @Failure      403     {object}  response.ErrorResponse
+// @Failure      404     {object}  response.ErrorResponse
+// @Failure      422     {object}  response.ErrorResponse
+// @Failure      500     {object}  response.ErrorResponse
+// @Router       /admin/impersonate/{userId} [post]
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	adminID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	var req impersonation.ImpersonateRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	token, err := h.impersonationService.Start(c.Request.Context(), impersonation.StartInput{
+		AdminID:   adminID,
+		UserID:    c.Param("userId"),
+		Reason:    req.Reason,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, token, "Impersonation started", response.HTTPCreated)
+}
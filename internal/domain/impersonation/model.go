@@ -0,0 +1,36 @@
+package impersonation
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ImpersonationSession is the audit record of an admin acting as a user
+// Its ID is the session ID carried by the impersonation token
+type ImpersonationSession struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(26)"`
+	AdminID   string    `json:"admin_id" gorm:"type:char(26);not null;index"`
+	UserID    string    `json:"user_id" gorm:"type:char(26);not null;index"`
+	Reason    string    `json:"reason" gorm:"type:varchar(255);not null"`
+	ClientIP  *string   `json:"client_ip,omitempty" gorm:"type:varchar(45)"`
+	UserAgent *string   `json:"user_agent,omitempty" gorm:"type:varchar(512)"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (s *ImpersonationSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		s.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the ImpersonationSession model
+func (ImpersonationSession) TableName() string {
+	return "impersonation_sessions"
+}
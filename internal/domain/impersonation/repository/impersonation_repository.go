@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"gin/internal/domain/impersonation"
+
+	"gorm.io/gorm"
+)
+
+// ImpersonationRepository handles impersonation audit database operations
+type ImpersonationRepository struct {
+	db *gorm.DB
+}
+
+// NewImpersonationRepository creates a new impersonation repository
+func NewImpersonationRepository(db *gorm.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *ImpersonationRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new impersonation session
+func (r *ImpersonationRepository) Create(ctx context.Context, session *impersonation.ImpersonationSession) (*impersonation.ImpersonationSession, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,16 @@
+package impersonation
+
+// ImpersonateRequest represents the payload for starting an impersonation session
+// The reason is kept in the audit trail
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// StartInput represents the data needed to start an impersonation session
+type StartInput struct {
+	AdminID   string
+	UserID    string
+	Reason    string
+	ClientIP  string
+	UserAgent string
+}
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/impersonation"
+)
+
+type ImpersonationServiceInterface interface {
+	Start(ctx context.Context, input impersonation.StartInput) (*impersonation.ImpersonationTokenDTO, error)
+}
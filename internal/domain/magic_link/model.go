@@ -0,0 +1,51 @@
+package magiclink
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// MagicLinkToken represents a single-use passwordless login link
+// Only the SHA-256 digests of the token and of the optional device binding are stored
+type MagicLinkToken struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID     string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	TokenHash  string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	DeviceHash *string    `json:"-" gorm:"type:char(64)"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (t *MagicLinkToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		t.ID = id.String()
+	}
+	return nil
+}
+
+// IsExpired checks if the link is expired
+func (t *MagicLinkToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the link has already been consumed
+func (t *MagicLinkToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsDeviceBound reports whether the link only works on the device that requested it
+func (t *MagicLinkToken) IsDeviceBound() bool {
+	return t.DeviceHash != nil
+}
+
+// TableName specifies the table name for the MagicLinkToken model
+func (MagicLinkToken) TableName() string {
+	return "magic_link_tokens"
+}
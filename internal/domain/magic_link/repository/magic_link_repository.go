@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	magiclink "gin/internal/domain/magic_link"
+
+	"gorm.io/gorm"
+)
+
+// MagicLinkRepository handles magic link database operations
+type MagicLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewMagicLinkRepository creates a new magic link repository
+func NewMagicLinkRepository(db *gorm.DB) *MagicLinkRepository {
+	return &MagicLinkRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *MagicLinkRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new magic link
+func (r *MagicLinkRepository) Create(ctx context.Context, token *magiclink.MagicLinkToken) (*magiclink.MagicLinkToken, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// FindByTokenHash finds a magic link by its digest
+func (r *MagicLinkRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*magiclink.MagicLinkToken, error) {
+	var token magiclink.MagicLinkToken
+	err := r.getDB(ctx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindLatestByUserID finds the most recently issued magic link for a user
+func (r *MagicLinkRepository) FindLatestByUserID(ctx context.Context, userID string) (*magiclink.MagicLinkToken, error) {
+	var token magiclink.MagicLinkToken
+	err := r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a magic link as consumed
+// Returns false when the link was already consumed by a concurrent request
+func (r *MagicLinkRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&magiclink.MagicLinkToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateUserLinks consumes every outstanding magic link of a user
+func (r *MagicLinkRepository) InvalidateUserLinks(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&magiclink.MagicLinkToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
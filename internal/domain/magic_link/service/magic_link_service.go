@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	magiclink "gin/internal/domain/magic_link"
+	magicLinkRepository "gin/internal/domain/magic_link/repository"
+	"gin/internal/domain/user"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/cache"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/mail"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// Options configures link lifetime, request limits and the frontend URL of the link
+type Options struct {
+	Expiry time.Duration
+	// Cooldown is the minimum time between two links sent to the same account
+	Cooldown time.Duration
+	// MaxRequests is the number of requests per email within Window; zero disables the limit
+	MaxRequests int
+	// IPMaxRequests is the number of requests per client IP within Window; zero disables the limit
+	IPMaxRequests int
+	Window        time.Duration
+	URL           string
+}
+
+// MagicLinkService implements MagicLinkServiceInterface
+type MagicLinkService struct {
+	magicLinkRepo *magicLinkRepository.MagicLinkRepository
+	userService   usersvc.UserServiceInterface
+	cache         cache.Cache
+	mailer        mail.Mailer
+	recorder      audit.Recorder
+	options       Options
+}
+
+// NewMagicLinkService creates a new magic link service
+func NewMagicLinkService(
+	magicLinkRepo *magicLinkRepository.MagicLinkRepository,
+	userService usersvc.UserServiceInterface,
+	cache cache.Cache,
+	mailer mail.Mailer,
+	recorder audit.Recorder,
+	options Options,
+) MagicLinkServiceInterface {
+	return &MagicLinkService{
+		magicLinkRepo: magicLinkRepo,
+		userService:   userService,
+		cache:         cache,
+		mailer:        mailer,
+		recorder:      recorder,
+		options:       options,
+	}
+}
+
+// RequestLink emails a single-use login link
+// A non-empty deviceID binds the link to the requesting device, which must present it again on consume.
+// Unknown, banned and service accounts are silently ignored, and so are requests inside the cooldown
+// window and mail failures, so the caller cannot tell whether the email is registered
+func (s *MagicLinkService) RequestLink(ctx context.Context, email string, deviceID string, clientIP string) error {
+	if err := s.throttle(ctx, email, clientIP); err != nil {
+		return err
+	}
+
+	u, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	// Inactive accounts are included: consuming the link proves the email and activates them
+	if u == nil || u.Status == constant.UserStatusBanned || u.Type == constant.AccountTypeService {
+		return nil
+	}
+
+	latest, err := s.magicLinkRepo.FindLatestByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && time.Now().Before(latest.CreatedAt.Add(s.options.Cooldown)) {
+		return nil
+	}
+
+	// Only the most recent link should work
+	if err := s.magicLinkRepo.InvalidateUserLinks(ctx, u.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	record := &magiclink.MagicLinkToken{
+		UserID:    u.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.options.Expiry),
+	}
+	if deviceID != "" {
+		deviceHash := utils.HashToken(deviceID)
+		record.DeviceHash = &deviceHash
+	}
+
+	if _, err := s.magicLinkRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	link := s.options.URL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Your sign-in link",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>Use the link below to sign in. It can be used once.</p><p><a href=\"%s\">%s</a></p><p>This link expires in %s. If you did not request it, you can ignore this email.</p>",
+			html.EscapeString(u.FullName()), link, link, s.options.Expiry,
+		),
+	})
+
+	// A failed send is logged, not returned: an error only registered emails can cause would reveal them
+	if err != nil {
+		logger.LogError(err, "Failed to send magic link", map[string]interface{}{"user_id": u.ID})
+	}
+	return nil
+}
+
+// Consume redeems a login link and returns its user
+// An inactive account is activated, since following the link proves ownership of the email
+func (s *MagicLinkService) Consume(ctx context.Context, token string, deviceID string) (*user.User, error) {
+	record, err := s.magicLinkRepo.FindByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil || record.IsUsed() || record.IsExpired() {
+		return nil, invalidLinkError("The login link is invalid or has expired.")
+	}
+
+	// A link opened on another device is rejected without being consumed, so intercepting
+	// the email alone is not enough and the requester can still use it
+	if record.IsDeviceBound() && subtle.ConstantTimeCompare([]byte(*record.DeviceHash), []byte(utils.HashToken(deviceID))) != 1 {
+		return nil, invalidLinkError("The login link must be opened on the device that requested it.")
+	}
+
+	consumed, err := s.magicLinkRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, invalidLinkError("The login link is invalid or has expired.")
+	}
+
+	u, err := s.userService.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Status == constant.UserStatusBanned {
+		return nil, exceptions.ForbiddenError("This account has been suspended", nil, nil)
+	}
+
+	if u.Status != constant.UserStatusActive {
+		if err := s.userService.UpdateStatus(ctx, u.ID, constant.UserStatusActive); err != nil {
+			return nil, err
+		}
+		u.Status = constant.UserStatusActive
+	}
+
+	s.recorder.Record(ctx, u.ID, audit.EventMagicLinkUsed, map[string]string{"device_bound": strconv.FormatBool(record.IsDeviceBound())})
+	return u, nil
+}
+
+// throttle counts a link request per email and per client IP and rejects it above the limits
+// Emails are counted whether or not an account exists, so the limit does not reveal registered addresses
+func (s *MagicLinkService) throttle(ctx context.Context, email string, clientIP string) error {
+	limits := []struct {
+		key   string
+		limit int
+	}{
+		{key: "magic_link:requests:email:" + strings.ToLower(strings.TrimSpace(email)), limit: s.options.MaxRequests},
+		{key: "magic_link:requests:ip:" + clientIP, limit: s.options.IPMaxRequests},
+	}
+
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+
+		requests, err := s.cache.Increment(ctx, l.key, s.options.Window)
+		if err != nil {
+			return err
+		}
+		if requests > int64(l.limit) {
+			desc := "Please wait before requesting another login link."
+			return exceptions.TooManyRequestsError("Too many login link requests", &desc, nil)
+		}
+	}
+
+	return nil
+}
+
+// invalidLinkError builds a Laravel-style validation error for the token field
+func invalidLinkError(message string) error {
+	return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+		{Field: "token", Message: message},
+	})
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/user"
+)
+
+type MagicLinkServiceInterface interface {
+	RequestLink(ctx context.Context, email string, deviceID string, clientIP string) error
+	Consume(ctx context.Context, token string, deviceID string) (*user.User, error)
+}
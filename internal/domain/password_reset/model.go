@@ -0,0 +1,45 @@
+package passwordreset
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken represents a single-use password reset token
+// Only the SHA-256 digest of the token is stored
+type PasswordResetToken struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID    string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	TokenHash string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (t *PasswordResetToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		t.ID = id.String()
+	}
+	return nil
+}
+
+// IsExpired checks if the reset token is expired
+func (t *PasswordResetToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the reset token has already been consumed
+func (t *PasswordResetToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// TableName specifies the table name for the PasswordResetToken model
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
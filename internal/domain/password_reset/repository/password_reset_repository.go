@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	passwordreset "gin/internal/domain/password_reset"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository handles password reset token database operations
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new password reset repository
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *PasswordResetRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a new reset token
+func (r *PasswordResetRepository) Create(ctx context.Context, token *passwordreset.PasswordResetToken) (*passwordreset.PasswordResetToken, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// FindByTokenHash finds a reset token by its digest
+func (r *PasswordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*passwordreset.PasswordResetToken, error) {
+	var token passwordreset.PasswordResetToken
+	err := r.getDB(ctx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindLatestByUserID finds the most recently issued reset token for a user
+func (r *PasswordResetRepository) FindLatestByUserID(ctx context.Context, userID string) (*passwordreset.PasswordResetToken, error) {
+	var token passwordreset.PasswordResetToken
+	err := r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a reset token as consumed
+// Returns false when the token was already consumed by a concurrent request
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&passwordreset.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateUserTokens consumes every outstanding reset token of a user
+func (r *PasswordResetRepository) InvalidateUserTokens(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&passwordreset.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
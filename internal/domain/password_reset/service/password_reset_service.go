@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	passwordreset "gin/internal/domain/password_reset"
+	passwordResetRepository "gin/internal/domain/password_reset/repository"
+	refreshsvc "gin/internal/domain/refresh_token/service"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/mail"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// Options configures token lifetime, request cooldown and the frontend reset URL
+type Options struct {
+	Expiry   time.Duration
+	Cooldown time.Duration
+	URL      string
+}
+
+// PasswordResetService implements PasswordResetServiceInterface
+type PasswordResetService struct {
+	resetRepo           *passwordResetRepository.PasswordResetRepository
+	userService         usersvc.UserServiceInterface
+	refreshTokenService refreshsvc.RefreshTokenServiceInterface
+	mailer              mail.Mailer
+	recorder            audit.Recorder
+	options             Options
+}
+
+// NewPasswordResetService creates a new password reset service
+func NewPasswordResetService(
+	resetRepo *passwordResetRepository.PasswordResetRepository,
+	userService usersvc.UserServiceInterface,
+	refreshTokenService refreshsvc.RefreshTokenServiceInterface,
+	mailer mail.Mailer,
+	recorder audit.Recorder,
+	options Options,
+) PasswordResetServiceInterface {
+	return &PasswordResetService{
+		resetRepo:           resetRepo,
+		userService:         userService,
+		refreshTokenService: refreshTokenService,
+		mailer:              mailer,
+		recorder:            recorder,
+		options:             options,
+	}
+}
+
+// RequestReset emails a password reset link to an active account
+// Unknown, inactive and banned accounts are silently ignored, and so are requests
+// inside the cooldown window, so the caller cannot tell whether the email is registered
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	u, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if u == nil || u.Status != constant.UserStatusActive {
+		return nil
+	}
+
+	latest, err := s.resetRepo.FindLatestByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && time.Now().Before(latest.CreatedAt.Add(s.options.Cooldown)) {
+		return nil
+	}
+
+	// Only the most recent link should work
+	if err := s.resetRepo.InvalidateUserTokens(ctx, u.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.resetRepo.Create(ctx, &passwordreset.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.options.Expiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.options.URL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Reset your password",
+		HTML: fmt.Sprintf(
+			"<p>Hi %s,</p><p>We received a request to reset your password. Use the link below to choose a new one:</p><p><a href=\"%s\">%s</a></p><p>This link expires in %s. If you did not request a reset, you can ignore this email.</p>",
+			html.EscapeString(u.FullName()), link, link, s.options.Expiry,
+		),
+	})
+}
+
+// Reset consumes a reset token, sets the new password and signs the user out everywhere
+func (s *PasswordResetService) Reset(ctx context.Context, token string, password string) error {
+	record, err := s.resetRepo.FindByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if record == nil || record.IsUsed() || record.IsExpired() {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "token", Message: "The password reset token is invalid or has expired."},
+		})
+	}
+
+	consumed, err := s.resetRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "token", Message: "The password reset token is invalid or has expired."},
+		})
+	}
+
+	if err := s.userService.SetPassword(ctx, record.UserID, password); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.InvalidateUserTokens(ctx, record.UserID); err != nil {
+		return err
+	}
+
+	// Existing sessions may belong to whoever knew the old password
+	if err := s.refreshTokenService.RevokeAllUserTokens(ctx, record.UserID); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, record.UserID, audit.EventPasswordReset, nil)
+	return nil
+}
@@ -0,0 +1,8 @@
+package service
+
+import "context"
+
+type PasswordResetServiceInterface interface {
+	RequestReset(ctx context.Context, email string) error
+	Reset(ctx context.Context, token string, password string) error
+}
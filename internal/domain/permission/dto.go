@@ -0,0 +1,14 @@
+package permission
+
+// PermissionDTO describes a permission of the catalog and the roles it is assigned to
+type PermissionDTO struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Roles       []string `json:"roles"`
+}
+
+// RolePermissionsDTO lists the permissions assigned to a role
+type RolePermissionsDTO struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"gin/internal/domain/permission"
+	permissionsvc "gin/internal/domain/permission/service"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/response"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionHandler handles HTTP requests for managing role permissions
+type PermissionHandler struct {
+	permissionService permissionsvc.PermissionServiceInterface
+}
+
+// NewPermissionHandler creates a new permission handler
+func NewPermissionHandler(permissionService permissionsvc.PermissionServiceInterface) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// ListPermissions lists the permission catalog
+// @Summary      List permissions
+// @Description  List every permission known to the policy engine and the roles it is assigned to. Requires the admin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]permission.PermissionDTO}
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /admin/permissions [get]
+func (h *PermissionHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.ListPermissions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, permissions, "Permissions retrieved successfully")
+}
+
+// ListRolePermissions lists the permissions assigned to a role
+// @Summary      List role permissions
+// @Description  List the permissions assigned to a role. Requires the admin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  path      string  true  "Role"  Enums(user, staff, admin)
+// @Success      200   {object}  response.Response{data=permission.RolePermissionsDTO}
+// @Failure      401   {object}  response.ErrorResponse
+// @Failure      403   {object}  response.ErrorResponse
+// @Failure      404   {object}  response.ErrorResponse
+// @Failure      500   {object}  response.ErrorResponse
+// @Router       /admin/roles/{role}/permissions [get]
+func (h *PermissionHandler) ListRolePermissions(c *gin.Context) {
+	rolePermissions, err := h.permissionService.ListRolePermissions(c.Request.Context(), constant.AccountTypeEnum(c.Param("role")))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, rolePermissions, "Role permissions retrieved successfully")
+}
+
+// GrantPermission assigns a permission to a role
+// @Summary      Grant permission to role
+// @Description  Assign a permission to every user of a role. Requires the admin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role        path      string                             true  "Role"  Enums(user, staff, admin)
+// @Param        permission  body      permission.GrantPermissionRequest  true  "Permission to grant"
+// @Success      200         {object}  response.Response{data=permission.RolePermissionsDTO}
+// @Failure      401         {object}  response.ErrorResponse
+// @Failure      403         {object}  response.ErrorResponse
+// @Failure      404         {object}  response.ErrorResponse
+// @Failure      422         {object}  response.ErrorResponse
+// @Failure      500         {object}  response.ErrorResponse
+// @Router       /admin/roles/{role}/permissions [post]
+func (h *PermissionHandler) GrantPermission(c *gin.Context) {
+	var req permission.GrantPermissionRequest
+
+	// Bind and validate JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		errMsg := "Invalid request format. Please check your JSON syntax."
+		appErr := exceptions.ValidationError(errMsg, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	rolePermissions, err := h.permissionService.GrantPermission(c.Request.Context(), constant.AccountTypeEnum(c.Param("role")), req.Permission)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, rolePermissions, "Permission granted successfully")
+}
+
+// RevokePermission removes a permission from a role
+// @Summary      Revoke permission from role
+// @Description  Remove a permission from a role. Requires the admin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role        path      string  true  "Role"  Enums(user, staff, admin)
+// @Param        permission  path      string  true  "Permission name, e.g. users:delete"
+// @Success      200         {object}  response.Response{data=permission.RolePermissionsDTO}
+// @Failure      401         {object}  response.ErrorResponse
+// @Failure      403         {object}  response.ErrorResponse
+// @Failure      404         {object}  response.ErrorResponse
+// @Failure      500         {object}  response.ErrorResponse
+// @Router       /admin/roles/{role}/permissions/{permission} [delete]
+func (h *PermissionHandler) RevokePermission(c *gin.Context) {
+	rolePermissions, err := h.permissionService.RevokePermission(c.Request.Context(), constant.AccountTypeEnum(c.Param("role")), c.Param("permission"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.SendResponse(c, rolePermissions, "Permission revoked successfully")
+}
@@ -0,0 +1,45 @@
+package permission
+
+import (
+	"time"
+
+	"gin/internal/shared/constant"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// Permission is an action the policy engine can grant, such as "users:delete"
+type Permission struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description *string   `json:"description,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (p *Permission) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		p.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the Permission model
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// RolePermission assigns a permission to every user of a role
+type RolePermission struct {
+	Role       constant.AccountTypeEnum `json:"role" gorm:"primaryKey;type:varchar(20)"`
+	Permission string                   `json:"permission" gorm:"primaryKey;type:varchar(100)"`
+	CreatedAt  time.Time                `json:"created_at"`
+}
+
+// TableName specifies the table name for the RolePermission model
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
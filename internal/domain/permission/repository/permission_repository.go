@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"gin/internal/domain/permission"
+	"gin/internal/shared/constant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermissionRepository handles permission and role assignment database operations
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository creates a new permission repository
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *PermissionRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// FindAllPermissions returns the permission catalog ordered by name
+func (r *PermissionRepository) FindAllPermissions(ctx context.Context) ([]*permission.Permission, error) {
+	var permissions []*permission.Permission
+	err := r.getDB(ctx).WithContext(ctx).Order("name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// FindPermissionByName finds a permission of the catalog by name
+func (r *PermissionRepository) FindPermissionByName(ctx context.Context, name string) (*permission.Permission, error) {
+	var p permission.Permission
+	err := r.getDB(ctx).WithContext(ctx).Where("name = ?", name).First(&p).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindAllRolePermissions returns every role assignment
+func (r *PermissionRepository) FindAllRolePermissions(ctx context.Context) ([]*permission.RolePermission, error) {
+	var assignments []*permission.RolePermission
+	err := r.getDB(ctx).WithContext(ctx).Order("role ASC, permission ASC").Find(&assignments).Error
+	return assignments, err
+}
+
+// GrantToRole assigns a permission to a role; granting an existing assignment is a no-op
+func (r *PermissionRepository) GrantToRole(ctx context.Context, role constant.AccountTypeEnum, name string) error {
+	return r.getDB(ctx).WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&permission.RolePermission{Role: role, Permission: name}).Error
+}
+
+// RevokeFromRole removes a permission from a role
+func (r *PermissionRepository) RevokeFromRole(ctx context.Context, role constant.AccountTypeEnum, name string) error {
+	return r.getDB(ctx).WithContext(ctx).
+		Where("role = ? AND permission = ?", role, name).
+		Delete(&permission.RolePermission{}).Error
+}
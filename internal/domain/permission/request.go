@@ -0,0 +1,6 @@
+package permission
+
+// GrantPermissionRequest assigns a permission to a role
+type GrantPermissionRequest struct {
+	Permission string `json:"permission" binding:"required,max=100"`
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gin/internal/domain/permission"
+	permissionRepository "gin/internal/domain/permission/repository"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+)
+
+// Options configures the in-memory grant cache and the resource rules of the policy
+type Options struct {
+	// CacheTTL is how long role assignments are kept in memory before being reloaded,
+	// which bounds how long other instances take to see a change
+	CacheTTL time.Duration
+	// Rules grant actions on resources independently of role assignments, e.g. to their owner
+	Rules map[string][]authz.Rule
+}
+
+// PermissionService implements PermissionServiceInterface
+type PermissionService struct {
+	permissionRepo *permissionRepository.PermissionRepository
+	options        Options
+
+	mu       sync.Mutex
+	grants   map[constant.AccountTypeEnum]map[string]bool
+	loadedAt time.Time
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(permissionRepo *permissionRepository.PermissionRepository, options Options) PermissionServiceInterface {
+	return &PermissionService{
+		permissionRepo: permissionRepo,
+		options:        options,
+	}
+}
+
+// Can reports whether the principal may perform the action on the resource
+func (s *PermissionService) Can(ctx context.Context, principal *authz.Principal, action string, resource authz.Resource) (bool, error) {
+	if principal == nil {
+		return false, nil
+	}
+
+	grants, err := s.loadGrants(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	policy := authz.Policy{Grants: grants, Rules: s.options.Rules}
+	return policy.Allows(principal, action, resource), nil
+}
+
+// Authorize returns an error unless the principal may perform the action on the resource
+func (s *PermissionService) Authorize(ctx context.Context, principal *authz.Principal, action string, resource authz.Resource) error {
+	if principal == nil {
+		return exceptions.UnauthorizedError("Authentication required", nil, nil)
+	}
+
+	allowed, err := s.Can(ctx, principal, action, resource)
+	if err != nil {
+		return exceptions.InternalError("Failed to check permissions", nil, nil)
+	}
+	if !allowed {
+		return exceptions.ForbiddenError("You do not have permission to perform this action", nil, nil)
+	}
+	return nil
+}
+
+// ListPermissions returns the permission catalog with the roles each permission is assigned to
+func (s *PermissionService) ListPermissions(ctx context.Context) ([]permission.PermissionDTO, error) {
+	permissions, err := s.permissionRepo.FindAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.permissionRepo.FindAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string][]string)
+	for _, assignment := range assignments {
+		roles[assignment.Permission] = append(roles[assignment.Permission], string(assignment.Role))
+	}
+
+	dtos := make([]permission.PermissionDTO, 0, len(permissions))
+	for _, p := range permissions {
+		dto := permission.PermissionDTO{Name: p.Name, Description: p.Description, Roles: roles[p.Name]}
+		if dto.Roles == nil {
+			dto.Roles = []string{}
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+// ListRolePermissions returns the permissions assigned to a role
+func (s *PermissionService) ListRolePermissions(ctx context.Context, role constant.AccountTypeEnum) (*permission.RolePermissionsDTO, error) {
+	if !role.IsValid() {
+		return nil, exceptions.NotFoundError("Role not found", nil, nil)
+	}
+
+	// Read from the database rather than the cache so changes made in this request are visible
+	assignments, err := s.permissionRepo.FindAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{}}
+	for _, assignment := range assignments {
+		if assignment.Role == role {
+			dto.Permissions = append(dto.Permissions, assignment.Permission)
+		}
+	}
+	return dto, nil
+}
+
+// GrantPermission assigns a permission of the catalog to a role
+func (s *PermissionService) GrantPermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+	if !role.IsValid() {
+		return nil, exceptions.NotFoundError("Role not found", nil, nil)
+	}
+
+	p, err := s.permissionRepo.FindPermissionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "permission", Message: "The selected permission is invalid."},
+		})
+	}
+
+	if err := s.permissionRepo.GrantToRole(ctx, role, p.Name); err != nil {
+		return nil, err
+	}
+	utils.AfterCommit(ctx, s.invalidate)
+
+	logger.LogInfo("Permission granted", map[string]interface{}{"role": role, "permission": p.Name, "actor_id": actorID(ctx)})
+	return s.ListRolePermissions(ctx, role)
+}
+
+// RevokePermission removes a permission from a role
+func (s *PermissionService) RevokePermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+	if !role.IsValid() {
+		return nil, exceptions.NotFoundError("Role not found", nil, nil)
+	}
+
+	p, err := s.permissionRepo.FindPermissionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, exceptions.NotFoundError("Permission not found", nil, nil)
+	}
+
+	if err := s.permissionRepo.RevokeFromRole(ctx, role, p.Name); err != nil {
+		return nil, err
+	}
+	utils.AfterCommit(ctx, s.invalidate)
+
+	logger.LogInfo("Permission revoked", map[string]interface{}{"role": role, "permission": p.Name, "actor_id": actorID(ctx)})
+	return s.ListRolePermissions(ctx, role)
+}
+
+// loadGrants returns the cached role assignments, reloading them once they are older than the cache TTL
+func (s *PermissionService) loadGrants(ctx context.Context) (map[constant.AccountTypeEnum]map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.grants != nil && time.Since(s.loadedAt) < s.options.CacheTTL {
+		return s.grants, nil
+	}
+
+	assignments, err := s.permissionRepo.FindAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make(map[constant.AccountTypeEnum]map[string]bool)
+	for _, assignment := range assignments {
+		if grants[assignment.Role] == nil {
+			grants[assignment.Role] = make(map[string]bool)
+		}
+		grants[assignment.Role][assignment.Permission] = true
+	}
+
+	s.grants = grants
+	s.loadedAt = time.Now()
+	return grants, nil
+}
+
+// invalidate drops the cached role assignments so the next check reloads them
+// It must run after the change commits: a check in between would cache the old assignments again
+func (s *PermissionService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants = nil
+}
+
+// actorID identifies the caller in permission change logs
+func actorID(ctx context.Context) string {
+	if principal, ok := authz.PrincipalFromContext(ctx); ok {
+		return principal.UserID
+	}
+	return "system"
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"gin/internal/domain/permission"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
+)
+
+type PermissionServiceInterface interface {
+	authz.Authorizer
+	ListPermissions(ctx context.Context) ([]permission.PermissionDTO, error)
+	ListRolePermissions(ctx context.Context, role constant.AccountTypeEnum) (*permission.RolePermissionsDTO, error)
+	GrantPermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error)
+	RevokePermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error)
+}
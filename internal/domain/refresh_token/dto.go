@@ -2,12 +2,15 @@ package refreshtoken
 
 import (
 	"time"
+
+	"github.com/oklog/ulid/v2"
 )
 
 // RefreshTokenDTO represents the data transfer object for RefreshToken
 type RefreshTokenDTO struct {
 	ID        string     `json:"id"`
 	UserID    string     `json:"userId"`
+	FamilyID  string     `json:"familyId"`
 	ExpiresAt time.Time  `json:"expiresAt"`
 	Revoked   bool       `json:"revoked"`
 	RevokedAt *time.Time `json:"revokedAt,omitempty"`
@@ -20,6 +23,7 @@ func FromRefreshTokenModel(token RefreshToken) RefreshTokenDTO {
 	return RefreshTokenDTO{
 		ID:        token.ID,
 		UserID:    token.UserID,
+		FamilyID:  token.FamilyID,
 		ExpiresAt: token.ExpiresAt,
 		Revoked:   token.Revoked,
 		RevokedAt: token.RevokedAt,
@@ -33,6 +37,7 @@ func (dto RefreshTokenDTO) ToRefreshTokenModel() RefreshToken {
 	return RefreshToken{
 		ID:        dto.ID,
 		UserID:    dto.UserID,
+		FamilyID:  dto.FamilyID,
 		ExpiresAt: dto.ExpiresAt,
 		Revoked:   dto.Revoked,
 		RevokedAt: dto.RevokedAt,
@@ -40,3 +45,46 @@ func (dto RefreshTokenDTO) ToRefreshTokenModel() RefreshToken {
 		UpdatedAt: dto.UpdatedAt,
 	}
 }
+
+// SessionDTO represents a login session (the active refresh token of a token family)
+type SessionDTO struct {
+	ID         string     `json:"id"`
+	DeviceName *string    `json:"deviceName,omitempty"`
+	UserAgent  *string    `json:"userAgent,omitempty"`
+	ClientIP   *string    `json:"clientIp,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	Current    bool       `json:"current"`
+}
+
+// FromSessionToken converts the active refresh token of a family to a SessionDTO
+// currentSessionID marks the session the request was made from
+func FromSessionToken(token RefreshToken, currentSessionID string) SessionDTO {
+	startedAt := token.CreatedAt
+	if id, err := ulid.ParseStrict(token.FamilyID); err == nil {
+		startedAt = ulid.Time(id.Time())
+	}
+
+	return SessionDTO{
+		ID:         token.FamilyID,
+		DeviceName: token.DeviceName,
+		UserAgent:  token.UserAgent,
+		ClientIP:   token.ClientIP,
+		StartedAt:  startedAt,
+		LastUsedAt: token.LastUsedAt,
+		ExpiresAt:  token.ExpiresAt,
+		Current:    currentSessionID != "" && token.FamilyID == currentSessionID,
+	}
+}
+
+// TransformSessionCollection transforms active refresh tokens to SessionDTOs
+func TransformSessionCollection(tokens []*RefreshToken, currentSessionID string) []SessionDTO {
+	sessions := make([]SessionDTO, 0, len(tokens))
+	for _, token := range tokens {
+		if token != nil {
+			sessions = append(sessions, FromSessionToken(*token, currentSessionID))
+		}
+	}
+	return sessions
+}
@@ -8,15 +8,37 @@ import (
 )
 
 // RefreshToken represents a refresh token in the system
+// Only the SHA-256 digest of the token is persisted, never the signed JWT itself
 type RefreshToken struct {
-	ID        string     `json:"id" gorm:"primaryKey;type:char(26)"`
-	UserID    string     `json:"user_id" gorm:"type:char(26);not null;index"`
-	Token     string     `json:"-" gorm:"type:text;not null;uniqueIndex"`
-	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
-	Revoked   bool       `json:"revoked" gorm:"default:false;index"`
-	RevokedAt *time.Time `json:"revoked_at,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID         string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID     string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	FamilyID   string     `json:"family_id" gorm:"type:char(26);not null;index"`
+	ParentID   *string    `json:"parent_id,omitempty" gorm:"type:char(26)"`
+	TokenHash  string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"` // SHA-256 digest of the signed JWT
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	Revoked    bool       `json:"revoked" gorm:"default:false;index"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  *string    `json:"revoked_by,omitempty" gorm:"type:varchar(20)"` // One of the Revoked* reasons
+	UserAgent  *string    `json:"user_agent,omitempty" gorm:"type:varchar(512)"`
+	ClientIP   *string    `json:"client_ip,omitempty" gorm:"type:varchar(45)"`
+	DeviceName *string    `json:"device_name,omitempty" gorm:"type:varchar(100)"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Reasons a refresh token was revoked; only rotated tokens count as reuse when presented again
+const (
+	RevokedRotated   = "rotated"    // Replaced by the next token of its family on refresh
+	RevokedSignedOut = "signed_out" // Logout or a password reset revoked every session of the user
+	RevokedSession   = "session"    // The session was revoked by the user or evicted by the session limit
+	RevokedReuse     = "reuse"      // The family was cut off after a rotated token was replayed
+)
+
+// NewFamilyID generates the ID of a new token family
+// Family IDs are ULIDs, so they also order sessions by start time
+func NewFamilyID() string {
+	return ulid.Make().String()
 }
 
 // BeforeCreate hook for generating ID
@@ -26,6 +48,10 @@ func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
 		id := ulid.Make()
 		rt.ID = id.String()
 	}
+	// A token without a family starts a new one (e.g. on login)
+	if rt.FamilyID == "" {
+		rt.FamilyID = rt.ID
+	}
 	return nil
 }
 
@@ -39,6 +65,16 @@ func (rt *RefreshToken) IsValid() bool {
 	return !rt.Revoked && !rt.IsExpired()
 }
 
+// IsReplayed reports whether a revoked token was rotated out more than grace ago
+// Tokens revoked by logout, session revocation or eviction are merely stale, and a token rotated
+// within the grace period is most likely a concurrent refresh by the same client
+func (rt *RefreshToken) IsReplayed(grace time.Duration) bool {
+	if !rt.Revoked || rt.RevokedBy == nil || *rt.RevokedBy != RevokedRotated {
+		return false
+	}
+	return rt.RevokedAt == nil || time.Since(*rt.RevokedAt) > grace
+}
+
 // Revoke marks the refresh token as revoked
 func (rt *RefreshToken) Revoke() {
 	rt.Revoked = true
@@ -0,0 +1,33 @@
+package refreshtoken
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsReplayed(t *testing.T) {
+	reason := func(value string) *string { return &value }
+	revokedAgo := func(d time.Duration) *time.Time {
+		at := time.Now().Add(-d)
+		return &at
+	}
+
+	cases := []struct {
+		name  string
+		token RefreshToken
+		want  bool
+	}{
+		{name: "active", token: RefreshToken{}},
+		{name: "rotated long ago", token: RefreshToken{Revoked: true, RevokedBy: reason(RevokedRotated), RevokedAt: revokedAgo(time.Minute)}, want: true},
+		{name: "rotated within the grace period", token: RefreshToken{Revoked: true, RevokedBy: reason(RevokedRotated), RevokedAt: revokedAgo(time.Second)}},
+		{name: "signed out", token: RefreshToken{Revoked: true, RevokedBy: reason(RevokedSignedOut), RevokedAt: revokedAgo(time.Minute)}},
+		{name: "session revoked or evicted", token: RefreshToken{Revoked: true, RevokedBy: reason(RevokedSession), RevokedAt: revokedAgo(time.Minute)}},
+		{name: "revoked before reasons were recorded", token: RefreshToken{Revoked: true, RevokedAt: revokedAgo(time.Minute)}},
+	}
+
+	for _, tc := range cases {
+		if got := tc.token.IsReplayed(10 * time.Second); got != tc.want {
+			t.Errorf("%s: IsReplayed = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -17,19 +17,28 @@ func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
 	return &RefreshTokenRepository{db: db}
 }
 
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *RefreshTokenRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
 // Create creates a new refresh token
 func (r *RefreshTokenRepository) Create(ctx context.Context, token *tokenmodel.RefreshToken) (*tokenmodel.RefreshToken, error) {
-	err := r.db.WithContext(ctx).Create(token).Error
+	err := r.getDB(ctx).WithContext(ctx).Create(token).Error
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-// FindByToken finds a refresh token by token string
-func (r *RefreshTokenRepository) FindByToken(ctx context.Context, token string) (*tokenmodel.RefreshToken, error) {
+// FindByTokenHash finds a refresh token by the digest of its token string
+func (r *RefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*tokenmodel.RefreshToken, error) {
 	var refreshToken tokenmodel.RefreshToken
-	err := r.db.WithContext(ctx).Where("token = ?", token).First(&refreshToken).Error
+	err := r.getDB(ctx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&refreshToken).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -39,39 +48,59 @@ func (r *RefreshTokenRepository) FindByToken(ctx context.Context, token string)
 	return &refreshToken, nil
 }
 
-// Revoke revokes a refresh token by setting revoked flag
-func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenID string) error {
-	return r.db.WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
+// Revoke revokes a refresh token by setting revoked flag and the reason
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenID string, reason string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
 		Where("id = ?", tokenID).
-		Updates(map[string]interface{}{
-			"revoked":    true,
-			"revoked_at": gorm.Expr("CURRENT_TIMESTAMP"),
-		}).Error
+		Updates(revokedColumns(reason)).Error
 }
 
-// RevokeByToken revokes a refresh token by token string
-func (r *RefreshTokenRepository) RevokeByToken(ctx context.Context, token string) error {
-	return r.db.WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
-		Where("token = ?", token).
-		Updates(map[string]interface{}{
-			"revoked":    true,
-			"revoked_at": gorm.Expr("CURRENT_TIMESTAMP"),
-		}).Error
+// RevokeByTokenHash revokes a refresh token by the digest of its token string
+func (r *RefreshTokenRepository) RevokeByTokenHash(ctx context.Context, tokenHash string, reason string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
+		Where("token_hash = ?", tokenHash).
+		Updates(revokedColumns(reason)).Error
 }
 
 // RevokeAllUserTokens revokes all refresh tokens for a user
 func (r *RefreshTokenRepository) RevokeAllUserTokens(ctx context.Context, userID string) error {
-	return r.db.WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
+	return r.getDB(ctx).WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
 		Where("user_id = ? AND revoked = ?", userID, false).
-		Updates(map[string]interface{}{
-			"revoked":    true,
-			"revoked_at": gorm.Expr("CURRENT_TIMESTAMP"),
-		}).Error
+		Updates(revokedColumns(tokenmodel.RevokedSignedOut)).Error
+}
+
+// RevokeFamily revokes every refresh token that belongs to a token family
+// It bypasses the request transaction: reuse detection revokes the family and then rejects the
+// request, and the rollback of the rejected request must not undo the revocation
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Updates(revokedColumns(tokenmodel.RevokedReuse)).Error
+}
+
+// FindActiveByUserID finds the non-revoked, unexpired refresh tokens of a user, newest session first
+// Each active token represents one session because rotation revokes the previous token of a family
+func (r *RefreshTokenRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*tokenmodel.RefreshToken, error) {
+	var tokens []*tokenmodel.RefreshToken
+	err := r.getDB(ctx).WithContext(ctx).
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, gorm.Expr("CURRENT_TIMESTAMP")).
+		Order("family_id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeUserFamily revokes a token family only if it belongs to the given user
+// Returns the number of tokens revoked
+func (r *RefreshTokenRepository) RevokeUserFamily(ctx context.Context, userID string, familyID string) (int64, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&tokenmodel.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked = ?", userID, familyID, false).
+		Updates(revokedColumns(tokenmodel.RevokedSession))
+	return result.RowsAffected, result.Error
 }
 
 // DeleteExpiredTokens deletes expired refresh tokens (cleanup job)
 func (r *RefreshTokenRepository) DeleteExpiredTokens(ctx context.Context) error {
-	return r.db.WithContext(ctx).
+	return r.getDB(ctx).WithContext(ctx).
 		Where("expires_at < ?", gorm.Expr("CURRENT_TIMESTAMP")).
 		Delete(&tokenmodel.RefreshToken{}).Error
 }
@@ -79,6 +108,15 @@ func (r *RefreshTokenRepository) DeleteExpiredTokens(ctx context.Context) error
 // FindByUserID finds all refresh tokens for a user
 func (r *RefreshTokenRepository) FindByUserID(ctx context.Context, userID string) ([]*tokenmodel.RefreshToken, error) {
 	var tokens []*tokenmodel.RefreshToken
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&tokens).Error
+	err := r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Find(&tokens).Error
 	return tokens, err
 }
+
+// revokedColumns returns the column updates that revoke a token for the given reason
+func revokedColumns(reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"revoked":    true,
+		"revoked_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		"revoked_by": reason,
+	}
+}
@@ -4,15 +4,37 @@ import (
 	"context"
 	tokenmodel "gin/internal/domain/refresh_token"
 	refreshTokenRepository "gin/internal/domain/refresh_token/repository"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/cache"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+	"time"
 )
 
+// Options configures session limits
+type Options struct {
+	// MaxSessions caps concurrent sessions per user; zero means unlimited
+	MaxSessions int
+	// AccessTokenTTL is how long access tokens of a revoked session stay denylisted
+	AccessTokenTTL time.Duration
+	// ReuseGracePeriod is how long a rotated token may be presented again without counting as reuse
+	ReuseGracePeriod time.Duration
+}
+
 type RefreshTokenService struct {
 	refreshTokenRepo *refreshTokenRepository.RefreshTokenRepository
+	cache            cache.Cache
+	recorder         audit.Recorder
+	options          Options
 }
 
-func NewRefreshTokenService(refreshTokenRepo *refreshTokenRepository.RefreshTokenRepository) RefreshTokenServiceInterface {
+func NewRefreshTokenService(refreshTokenRepo *refreshTokenRepository.RefreshTokenRepository, cache cache.Cache, recorder audit.Recorder, options Options) RefreshTokenServiceInterface {
 	return &RefreshTokenService{
 		refreshTokenRepo: refreshTokenRepo,
+		cache:            cache,
+		recorder:         recorder,
+		options:          options,
 	}
 }
 
@@ -20,14 +42,136 @@ func (s *RefreshTokenService) Create(ctx context.Context, token *tokenmodel.Refr
 	return s.refreshTokenRepo.Create(ctx, token)
 }
 
+// FindByToken looks up a refresh token by the digest of the raw token string
 func (s *RefreshTokenService) FindByToken(ctx context.Context, token string) (*tokenmodel.RefreshToken, error) {
-	return s.refreshTokenRepo.FindByToken(ctx, token)
+	return s.refreshTokenRepo.FindByTokenHash(ctx, utils.HashToken(token))
 }
 
-func (s *RefreshTokenService) RevokeByToken(ctx context.Context, token string) error {
-	return s.refreshTokenRepo.RevokeByToken(ctx, token)
+// RotateByToken revokes a refresh token, identified by the raw token string, that is being replaced
+// on refresh; only tokens revoked this way count as reuse when presented again
+func (s *RefreshTokenService) RotateByToken(ctx context.Context, token string) error {
+	return s.refreshTokenRepo.RevokeByTokenHash(ctx, utils.HashToken(token), tokenmodel.RevokedRotated)
 }
 
 func (s *RefreshTokenService) RevokeAllUserTokens(ctx context.Context, userID string) error {
 	return s.refreshTokenRepo.RevokeAllUserTokens(ctx, userID)
 }
+
+// HandleReuse revokes the whole token family after an already rotated token is presented again
+// A replayed token means the family may have been stolen, so every session derived from it is cut off
+// Tokens revoked by logout, session revocation or eviction, and tokens rotated within ReuseGracePeriod
+// (usually concurrent refreshes by one client), are left alone: the caller only rejects them
+// The repository writes outside the request transaction, so the revocation survives the 401 response
+func (s *RefreshTokenService) HandleReuse(ctx context.Context, token *tokenmodel.RefreshToken) error {
+	if !token.IsReplayed(s.options.ReuseGracePeriod) {
+		return nil
+	}
+
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+
+	// The family was revoked outside the transaction of a request that is about to be rejected,
+	// so its access tokens are denied right away instead of after a commit that never happens
+	if err := s.denySession(ctx, token.FamilyID); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, token.UserID, audit.EventRefreshTokenReuse, map[string]string{
+		"session_id": token.FamilyID,
+		"token_id":   token.ID,
+	})
+	return nil
+}
+
+// ListActiveSessions returns the active session tokens of a user, newest session first
+func (s *RefreshTokenService) ListActiveSessions(ctx context.Context, userID string) ([]*tokenmodel.RefreshToken, error) {
+	return s.refreshTokenRepo.FindActiveByUserID(ctx, userID)
+}
+
+// RevokeSession revokes a single session (token family) of a user
+func (s *RefreshTokenService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	revoked, err := s.refreshTokenRepo.RevokeUserFamily(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if revoked == 0 {
+		return exceptions.NotFoundError("Session not found", nil, nil)
+	}
+
+	s.denySessionAccessTokens(ctx, sessionID)
+
+	s.recorder.Record(ctx, userID, audit.EventSessionRevoked, map[string]string{"session_id": sessionID})
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of a user except keepSessionID
+// An empty keepSessionID revokes all sessions
+func (s *RefreshTokenService) RevokeOtherSessions(ctx context.Context, userID string, keepSessionID string) error {
+	sessions, err := s.refreshTokenRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.FamilyID == keepSessionID {
+			continue
+		}
+		if _, err := s.refreshTokenRepo.RevokeUserFamily(ctx, userID, session.FamilyID); err != nil {
+			return err
+		}
+		s.denySessionAccessTokens(ctx, session.FamilyID)
+	}
+
+	return nil
+}
+
+// EnforceSessionLimit revokes the oldest sessions of a user once MaxSessions is exceeded
+func (s *RefreshTokenService) EnforceSessionLimit(ctx context.Context, userID string) error {
+	if s.options.MaxSessions <= 0 {
+		return nil
+	}
+
+	sessions, err := s.refreshTokenRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Sessions are ordered newest first, so everything past the limit is evicted
+	for i := s.options.MaxSessions; i < len(sessions); i++ {
+		if _, err := s.refreshTokenRepo.RevokeUserFamily(ctx, userID, sessions[i].FamilyID); err != nil {
+			return err
+		}
+		s.denySessionAccessTokens(ctx, sessions[i].FamilyID)
+	}
+
+	return nil
+}
+
+// IsSessionRevoked reports whether access tokens of a session have been cut off
+func (s *RefreshTokenService) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	_, revoked, err := s.cache.Get(ctx, revokedSessionCacheKey(sessionID))
+	return revoked, err
+}
+
+// denySessionAccessTokens denylists the access tokens of a revoked session once the request
+// transaction commits, so a rolled back revocation does not cut off a session that is still valid
+func (s *RefreshTokenService) denySessionAccessTokens(ctx context.Context, sessionID string) {
+	utils.AfterCommit(ctx, func() {
+		if err := s.denySession(ctx, sessionID); err != nil {
+			logger.LogError(err, "Failed to deny the access tokens of a revoked session", map[string]interface{}{"session_id": sessionID})
+		}
+	})
+}
+
+// denySession writes the denylist entry of a session
+// Entries only need to outlive the longest-lived access token of the session
+func (s *RefreshTokenService) denySession(ctx context.Context, sessionID string) error {
+	return s.cache.Set(ctx, revokedSessionCacheKey(sessionID), "1", s.options.AccessTokenTTL)
+}
+
+// revokedSessionCacheKey returns the denylist cache key of a session
+func revokedSessionCacheKey(sessionID string) string {
+	return "session:revoked:" + sessionID
+}
@@ -8,6 +8,12 @@ import (
 type RefreshTokenServiceInterface interface {
 	Create(ctx context.Context, token *tokenmodel.RefreshToken) (*tokenmodel.RefreshToken, error)
 	FindByToken(ctx context.Context, token string) (*tokenmodel.RefreshToken, error)
-	RevokeByToken(ctx context.Context, token string) error
+	RotateByToken(ctx context.Context, token string) error
 	RevokeAllUserTokens(ctx context.Context, userID string) error
+	HandleReuse(ctx context.Context, token *tokenmodel.RefreshToken) error
+	ListActiveSessions(ctx context.Context, userID string) ([]*tokenmodel.RefreshToken, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID string, keepSessionID string) error
+	EnforceSessionLimit(ctx context.Context, userID string) error
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
 }
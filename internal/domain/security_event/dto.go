@@ -0,0 +1,47 @@
+package securityevent
+
+import (
+	"time"
+
+	"gin/internal/shared/pagination"
+)
+
+// SecurityEventDTO represents a security event in API responses
+type SecurityEventDTO struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Details   map[string]string `json:"details,omitempty"`
+	ClientIP  *string           `json:"clientIp,omitempty"`
+	UserAgent *string           `json:"userAgent,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// PaginatedSecurityEventDTO represents a page of a user's security events, newest first
+type PaginatedSecurityEventDTO struct {
+	Events []SecurityEventDTO `json:"events"`
+	Meta   pagination.Meta    `json:"meta"`
+}
+
+// FromSecurityEventModel converts a SecurityEvent model to a SecurityEventDTO
+func FromSecurityEventModel(event SecurityEvent) SecurityEventDTO {
+	return SecurityEventDTO{
+		ID:        event.ID,
+		Type:      event.Type,
+		Details:   event.Details,
+		ClientIP:  event.ClientIP,
+		UserAgent: event.UserAgent,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// ToPaginatedSecurityEventDTO creates a paginated security event DTO
+func ToPaginatedSecurityEventDTO(page pagination.Page[*SecurityEvent]) PaginatedSecurityEventDTO {
+	dtos := pagination.Map(page, func(event *SecurityEvent) SecurityEventDTO {
+		return FromSecurityEventModel(*event)
+	})
+
+	return PaginatedSecurityEventDTO{
+		Events: dtos.Items,
+		Meta:   dtos.Meta,
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	securityevent "gin/internal/domain/security_event"
+	securityeventsvc "gin/internal/domain/security_event/service"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/response"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityEventHandler handles HTTP requests for the security activity log
+type SecurityEventHandler struct {
+	securityEventService securityeventsvc.SecurityEventServiceInterface
+}
+
+// NewSecurityEventHandler creates a new security event handler
+func NewSecurityEventHandler(securityEventService securityeventsvc.SecurityEventServiceInterface) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		securityEventService: securityEventService,
+	}
+}
+
+// ListMySecurityEvents lists the security events of the authenticated user
+// @Summary      List my security events
+// @Description  List the security activity of the authenticated user, newest first: sign-ins and failed sign-ins, token refreshes, logouts, session revocations, password and two-factor changes, with the client IP and user agent.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int     false  "Page number"  default(1)  minimum(1)
+// @Param        per_page  query     int     false  "Items per page"  default(20)  minimum(1)  maximum(100)
+// @Param        count     query     string  false  "How to count the total"  Enums(exact, estimate, none)  default(exact)
+// @Success      200       {object}  response.Response{data=securityevent.PaginatedSecurityEventDTO}
+// @Header       200       {string}  Link  "RFC 8288 links to the first, prev, next and last pages"
+// @Failure      401       {object}  response.ErrorResponse
+// @Failure      422       {object}  response.ErrorResponse
+// @Failure      500       {object}  response.ErrorResponse
+// @Router       /users/me/security-events [get]
+func (h *SecurityEventHandler) ListMySecurityEvents(c *gin.Context) {
+	userID, err := utils.RequireUserID(c)
+	if err != nil {
+		appErr := exceptions.UnauthorizedError("User ID not found in context", nil, nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	params, err := pagination.BindParams(c, 20)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	page, err := h.securityEventService.ListForUser(c.Request.Context(), userID, params)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	pagination.SetLinkHeader(c, page.Meta)
+	response.SendResponse(c, securityevent.ToPaginatedSecurityEventDTO(page), "security events retrieved successfully")
+}
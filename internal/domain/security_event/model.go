@@ -0,0 +1,34 @@
+package securityevent
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// SecurityEvent is an entry in a user's security activity log
+type SecurityEvent struct {
+	ID        string            `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID    string            `json:"user_id" gorm:"type:char(26);not null;index"`
+	Type      string            `json:"type" gorm:"type:varchar(64);not null"`
+	Details   map[string]string `json:"details,omitempty" gorm:"type:jsonb;serializer:json"`
+	ClientIP  *string           `json:"client_ip,omitempty" gorm:"type:varchar(45)"`
+	UserAgent *string           `json:"user_agent,omitempty" gorm:"type:varchar(512)"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (e *SecurityEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		e.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the SecurityEvent model
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	securityevent "gin/internal/domain/security_event"
+	"gin/internal/shared/pagination"
+
+	"gorm.io/gorm"
+)
+
+// SecurityEventRepository handles security event database operations
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *SecurityEventRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Create stores a security event outside the request transaction
+// Failed requests roll their transaction back, and their events (such as failed logins) must be kept;
+// events of successful requests are only created after the commit, when the transaction is closed
+func (r *SecurityEventRepository) Create(ctx context.Context, event *securityevent.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// FindByUserIDPaginated retrieves a page of a user's events, newest first
+func (r *SecurityEventRepository) FindByUserIDPaginated(ctx context.Context, userID string, params pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error) {
+	// ULIDs sort by creation time, so the ID breaks ties between events of the same instant
+	query := r.getDB(ctx).WithContext(ctx).
+		Model(&securityevent.SecurityEvent{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC")
+
+	return pagination.Paginate[*securityevent.SecurityEvent](query, params)
+}
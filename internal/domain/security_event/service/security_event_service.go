@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	securityevent "gin/internal/domain/security_event"
+	securityEventRepository "gin/internal/domain/security_event/repository"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/utils"
+)
+
+// SecurityEventService implements SecurityEventServiceInterface and audit.Recorder
+type SecurityEventService struct {
+	securityEventRepo *securityEventRepository.SecurityEventRepository
+}
+
+// NewSecurityEventService creates a new security event service
+func NewSecurityEventService(securityEventRepo *securityEventRepository.SecurityEventRepository) SecurityEventServiceInterface {
+	return &SecurityEventService{
+		securityEventRepo: securityEventRepo,
+	}
+}
+
+// rejectedRequestEvents are recorded by requests that are then rejected, so their transaction
+// rolls back; they are written at once instead of waiting for a commit that never happens
+var rejectedRequestEvents = map[string]bool{
+	audit.EventLoginFailed:       true,
+	audit.EventRefreshTokenReuse: true,
+}
+
+// Record stores a security event with the client of the request and writes it to the log
+// Events of successful actions are written once the request transaction commits, so a rolled
+// back action leaves no event and events about rows created by the request pass the foreign key
+func (s *SecurityEventService) Record(ctx context.Context, userID string, eventType string, details map[string]string) {
+	client := audit.ClientFromContext(ctx)
+	event := &securityevent.SecurityEvent{
+		UserID:    userID,
+		Type:      eventType,
+		Details:   details,
+		ClientIP:  utils.OptionalTruncated(client.IP, 45),
+		UserAgent: utils.OptionalTruncated(client.UserAgent, 512),
+	}
+
+	if rejectedRequestEvents[eventType] {
+		s.write(ctx, event)
+		return
+	}
+	utils.AfterCommit(ctx, func() { s.write(ctx, event) })
+}
+
+// ListForUser retrieves a page of a user's security events, newest first
+func (s *SecurityEventService) ListForUser(ctx context.Context, userID string, params pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error) {
+	return s.securityEventRepo.FindByUserIDPaginated(ctx, userID, params)
+}
+
+// write logs an event and stores it outside the request transaction
+func (s *SecurityEventService) write(ctx context.Context, event *securityevent.SecurityEvent) {
+	logger.LogInfo("Security event", map[string]interface{}{
+		"event_type": event.Type,
+		"user_id":    event.UserID,
+		"ip":         audit.ClientFromContext(ctx).IP,
+		"details":    event.Details,
+	})
+
+	if err := s.securityEventRepo.Create(ctx, event); err != nil {
+		logger.LogError(err, "Failed to record security event", map[string]interface{}{"event_type": event.Type, "user_id": event.UserID})
+	}
+}
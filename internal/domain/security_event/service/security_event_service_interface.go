@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	securityevent "gin/internal/domain/security_event"
+	"gin/internal/shared/pagination"
+)
+
+type SecurityEventServiceInterface interface {
+	Record(ctx context.Context, userID string, eventType string, details map[string]string)
+	ListForUser(ctx context.Context, userID string, params pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error)
+}
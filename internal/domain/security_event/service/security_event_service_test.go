@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	securityEventRepository "gin/internal/domain/security_event/repository"
+	"gin/internal/domain/security_event/service"
+	"gin/internal/infra/logger"
+	middleware "gin/internal/infra/middleware"
+	"gin/internal/shared/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// insertPattern reads the table and the column list of an INSERT statement
+var insertPattern = regexp.MustCompile(`^INSERT INTO "?(\w+)"? \(([^)]*)\)`)
+
+// fkDatabase keeps users and security events in memory, enforcing the foreign key from
+// security_events.user_id to users.id; rows inserted in a transaction are only visible on
+// its own connection until it commits
+type fkDatabase struct {
+	mu     sync.Mutex
+	users  map[string]bool
+	events []string // "<user_id>:<type>"
+}
+
+type fkDriver struct{ db *fkDatabase }
+
+type fkConn struct {
+	db      *fkDatabase
+	pending *fkPending // Rows of the open transaction, nil outside one
+}
+
+type fkPending struct {
+	users  []string
+	events []string
+}
+
+type fkTx struct{ conn *fkConn }
+
+func (d fkDriver) Open(string) (driver.Conn, error) { return &fkConn{db: d.db}, nil }
+
+func (*fkConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported by the test database")
+}
+func (*fkConn) Close() error { return nil }
+
+func (c *fkConn) Begin() (driver.Tx, error) {
+	c.pending = &fkPending{}
+	return &fkTx{conn: c}, nil
+}
+
+func (c *fkConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	match := insertPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	values := make(map[string]string, len(args))
+	for i, column := range strings.Split(match[2], ",") {
+		if i < len(args) {
+			values[strings.Trim(strings.TrimSpace(column), `"`)] = fmt.Sprint(args[i].Value)
+		}
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	switch match[1] {
+	case "users":
+		if c.pending != nil {
+			c.pending.users = append(c.pending.users, values["id"])
+		} else {
+			c.db.users[values["id"]] = true
+		}
+	case "security_events":
+		if !c.db.users[values["user_id"]] && !c.pendingUser(values["user_id"]) {
+			return nil, errors.New(`insert or update on table "security_events" violates foreign key constraint "fk_security_events_user"`)
+		}
+		event := values["user_id"] + ":" + values["type"]
+		if c.pending != nil {
+			c.pending.events = append(c.pending.events, event)
+		} else {
+			c.db.events = append(c.db.events, event)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected table %s", match[1])
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// pendingUser reports whether the open transaction of this connection inserted the user
+func (c *fkConn) pendingUser(id string) bool {
+	if c.pending == nil {
+		return false
+	}
+	for _, user := range c.pending.users {
+		if user == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *fkTx) Commit() error {
+	db := t.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, id := range t.conn.pending.users {
+		db.users[id] = true
+	}
+	db.events = append(db.events, t.conn.pending.events...)
+	t.conn.pending = nil
+	return nil
+}
+
+func (t *fkTx) Rollback() error {
+	t.conn.pending = nil
+	return nil
+}
+
+func (db *fkDatabase) storedEvents() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.events...)
+}
+
+// newRecordingRouter serves requests through the transaction middleware; each handler inserts
+// its user, if any, in the request transaction and records an event
+func newRecordingRouter(t *testing.T, users map[string]bool) (*gin.Engine, *fkDatabase) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	logger.Logger = logrus.New()
+	logger.ErrorLogger = logrus.New()
+	logger.Logger.SetOutput(io.Discard)
+	logger.ErrorLogger.SetOutput(io.Discard)
+
+	store := &fkDatabase{users: users}
+	driverName := "gin-security-event-test-" + time.Now().Format("150405.000000000")
+	sql.Register(driverName, fkDriver{db: store})
+	sqlDB, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("create gorm test database: %v", err)
+	}
+
+	recorder := service.NewSecurityEventService(securityEventRepository.NewSecurityEventRepository(db))
+
+	engine := gin.New()
+	engine.POST("/signup", middleware.TransactionMiddleware(db), func(c *gin.Context) {
+		tx := middleware.GetTransaction(c, db)
+		if err := tx.Exec(`INSERT INTO "users" ("id") VALUES (?)`, c.Query("id")).Error; err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		recorder.Record(c.Request.Context(), c.Query("id"), audit.EventSocialSignup, nil)
+		if c.Query("fail") != "" {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	engine.POST("/login", middleware.TransactionMiddleware(db), func(c *gin.Context) {
+		recorder.Record(c.Request.Context(), c.Query("id"), audit.EventLoginFailed, nil)
+		c.Status(http.StatusUnauthorized)
+	})
+	return engine, store
+}
+
+func serve(engine *gin.Engine, path string) int {
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+	return recorder.Code
+}
+
+func TestRecordWritesSuccessEventsAfterCommit(t *testing.T) {
+	engine, store := newRecordingRouter(t, map[string]bool{})
+
+	// The user only exists in the open transaction when the event is recorded
+	if status := serve(engine, "/signup?id=user-1"); status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if events := store.storedEvents(); len(events) != 1 || events[0] != "user-1:"+audit.EventSocialSignup {
+		t.Fatalf("events = %v, want the social_signup of user-1", events)
+	}
+
+	// A rolled back sign-up leaves neither the user nor its event
+	if status := serve(engine, "/signup?id=user-2&fail=1"); status != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", status)
+	}
+	if events := store.storedEvents(); len(events) != 1 {
+		t.Fatalf("events = %v; the event of the rolled back sign-up was kept", events)
+	}
+}
+
+func TestRecordKeepsFailureEventsOfRejectedRequests(t *testing.T) {
+	engine, store := newRecordingRouter(t, map[string]bool{"user-1": true})
+
+	if status := serve(engine, "/login?id=user-1"); status != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", status)
+	}
+	if events := store.storedEvents(); len(events) != 1 || events[0] != "user-1:"+audit.EventLoginFailed {
+		t.Fatalf("events = %v, want the login_failed of user-1", events)
+	}
+}
@@ -0,0 +1,19 @@
+package twofactor
+
+// EnrollmentDTO carries the TOTP secret shown once while enrolling
+type EnrollmentDTO struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// RecoveryCodesDTO carries the one-time recovery codes shown once after confirmation
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// ChallengeDTO is returned by login instead of tokens when 2FA is enabled
+type ChallengeDTO struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int64  `json:"expiresIn"`
+}
@@ -0,0 +1,65 @@
+package twofactor
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TwoFactorSecret represents a user's TOTP secret
+// The secret is stored encrypted; it only protects logins once confirmed
+type TwoFactorSecret struct {
+	ID              string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID          string     `json:"user_id" gorm:"type:char(26);not null;uniqueIndex"`
+	SecretEncrypted string     `json:"-" gorm:"type:text;not null"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep    int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (s *TwoFactorSecret) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		s.ID = id.String()
+	}
+	return nil
+}
+
+// IsConfirmed checks if enrollment has been completed
+func (s *TwoFactorSecret) IsConfirmed() bool {
+	return s.ConfirmedAt != nil
+}
+
+// TableName specifies the table name for the TwoFactorSecret model
+func (TwoFactorSecret) TableName() string {
+	return "two_factor_secrets"
+}
+
+// RecoveryCode represents a single-use 2FA recovery code
+// Only the SHA-256 digest of the code is stored
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID    string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:char(64);not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (c *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		c.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
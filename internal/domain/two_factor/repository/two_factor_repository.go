@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	twofactor "gin/internal/domain/two_factor"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository handles TOTP secret and recovery code database operations
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// getDB retrieves the database connection from context if transaction exists, otherwise returns default db
+func (r *TwoFactorRepository) getDB(ctx context.Context) *gorm.DB {
+	// Try to get transaction from context (set by transaction middleware)
+	if tx, ok := ctx.Value("db_transaction").(*gorm.DB); ok {
+		return tx
+	}
+	return r.db
+}
+
+// FindSecretByUserID finds the TOTP secret of a user
+func (r *TwoFactorRepository) FindSecretByUserID(ctx context.Context, userID string) (*twofactor.TwoFactorSecret, error) {
+	var secret twofactor.TwoFactorSecret
+	err := r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).First(&secret).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// CreateSecret stores a new TOTP secret
+func (r *TwoFactorRepository) CreateSecret(ctx context.Context, secret *twofactor.TwoFactorSecret) (*twofactor.TwoFactorSecret, error) {
+	if err := r.getDB(ctx).WithContext(ctx).Create(secret).Error; err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// ConfirmSecret marks a secret as confirmed and records the step of the confirming code
+func (r *TwoFactorRepository) ConfirmSecret(ctx context.Context, id string, step int64) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&twofactor.TwoFactorSecret{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"confirmed_at":   gorm.Expr("CURRENT_TIMESTAMP"),
+			"last_used_step": step,
+		}).Error
+}
+
+// AdvanceLastUsedStep records the step of an accepted code
+// Returns false when the step (or a later one) was already used, so a code cannot be replayed
+func (r *TwoFactorRepository) AdvanceLastUsedStep(ctx context.Context, id string, step int64) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&twofactor.TwoFactorSecret{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteSecretByUserID removes the TOTP secret of a user
+func (r *TwoFactorRepository) DeleteSecretByUserID(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Delete(&twofactor.TwoFactorSecret{}).Error
+}
+
+// ReplaceRecoveryCodes deletes the recovery codes of a user and stores new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*twofactor.RecoveryCode) error {
+	db := r.getDB(ctx).WithContext(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&twofactor.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return db.Create(codes).Error
+}
+
+// FindUnusedRecoveryCode finds an unused recovery code of a user by its digest
+func (r *TwoFactorRepository) FindUnusedRecoveryCode(ctx context.Context, userID string, codeHash string) (*twofactor.RecoveryCode, error) {
+	var code twofactor.RecoveryCode
+	err := r.getDB(ctx).WithContext(ctx).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		First(&code).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkRecoveryCodeUsed marks a recovery code as consumed
+// Returns false when the code was already consumed by a concurrent request
+func (r *TwoFactorRepository) MarkRecoveryCodeUsed(ctx context.Context, id string) (bool, error) {
+	result := r.getDB(ctx).WithContext(ctx).Model(&twofactor.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteRecoveryCodesByUserID removes every recovery code of a user
+func (r *TwoFactorRepository) DeleteRecoveryCodesByUserID(ctx context.Context, userID string) error {
+	return r.getDB(ctx).WithContext(ctx).Where("user_id = ?", userID).Delete(&twofactor.RecoveryCode{}).Error
+}
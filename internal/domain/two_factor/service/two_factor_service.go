@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	twofactor "gin/internal/domain/two_factor"
+	twoFactorRepository "gin/internal/domain/two_factor/repository"
+	usersvc "gin/internal/domain/user/service"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/cache"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Recovery code format: groups of characters without look-alikes (0/O, 1/I)
+const (
+	recoveryCodeAlphabet  = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	recoveryCodeGroupSize = 5
+	recoveryCodeGroups    = 2
+)
+
+// maxChallengeAttempts is how many wrong codes a challenge accepts before the user must log in again
+const maxChallengeAttempts = 5
+
+// Options configures the authenticator issuer name, challenge lifetime and recovery codes
+type Options struct {
+	Issuer            string
+	ChallengeExpiry   time.Duration
+	RecoveryCodeCount int
+	// MaxFailures is the number of wrong codes per user, across challenges, within LockoutDuration
+	// that locks the second factor for LockoutDuration; zero disables the lockout
+	MaxFailures     int
+	LockoutDuration time.Duration
+}
+
+// TwoFactorService implements TwoFactorServiceInterface
+type TwoFactorService struct {
+	twoFactorRepo *twoFactorRepository.TwoFactorRepository
+	userService   usersvc.UserServiceInterface
+	jwtManager    *utils.JWTManager
+	encrypter     *utils.Encrypter
+	cache         cache.Cache
+	recorder      audit.Recorder
+	options       Options
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(
+	twoFactorRepo *twoFactorRepository.TwoFactorRepository,
+	userService usersvc.UserServiceInterface,
+	jwtManager *utils.JWTManager,
+	encrypter *utils.Encrypter,
+	cache cache.Cache,
+	recorder audit.Recorder,
+	options Options,
+) TwoFactorServiceInterface {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userService:   userService,
+		jwtManager:    jwtManager,
+		encrypter:     encrypter,
+		cache:         cache,
+		recorder:      recorder,
+		options:       options,
+	}
+}
+
+// IsEnabled reports whether a user has a confirmed TOTP secret
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	secret, err := s.twoFactorRepo.FindSecretByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return secret != nil && secret.IsConfirmed(), nil
+}
+
+// Enroll generates a new TOTP secret for the user
+// The secret only protects logins after Confirm; enrolling again replaces an unconfirmed secret
+func (s *TwoFactorService) Enroll(ctx context.Context, userID string) (*twofactor.EnrollmentDTO, error) {
+	u, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.twoFactorRepo.FindSecretByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.IsConfirmed() {
+		return nil, exceptions.ValidationError("Two-factor authentication is already enabled", nil, nil)
+	}
+
+	if existing != nil {
+		if err := s.twoFactorRepo.DeleteSecretByUserID(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.encrypter.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.twoFactorRepo.CreateSecret(ctx, &twofactor.TwoFactorSecret{
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &twofactor.EnrollmentDTO{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPAuthURI(s.options.Issuer, u.Email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA once the user proves their authenticator works, and issues recovery codes
+func (s *TwoFactorService) Confirm(ctx context.Context, userID string, code string) (*twofactor.RecoveryCodesDTO, error) {
+	secret, err := s.twoFactorRepo.FindSecretByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil || secret.IsConfirmed() {
+		return nil, exceptions.ValidationError("Two-factor enrollment has not been started", nil, nil)
+	}
+
+	plain, err := s.encrypter.Decrypt(secret.SecretEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(plain, code, time.Now())
+	if !ok {
+		return nil, invalidCodeError()
+	}
+
+	if err := s.twoFactorRepo.ConfirmSecret(ctx, secret.ID, step); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recorder.Record(ctx, userID, audit.EventTwoFactorEnabled, nil)
+	return &twofactor.RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off after re-authenticating with the password and a current code
+func (s *TwoFactorService) Disable(ctx context.Context, userID string, password string, code string) error {
+	u, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "password", Message: "The password is incorrect."},
+		})
+	}
+
+	valid, err := s.verifyCode(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return invalidCodeError()
+	}
+
+	if err := s.twoFactorRepo.DeleteRecoveryCodesByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.twoFactorRepo.DeleteSecretByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, userID, audit.EventTwoFactorDisabled, nil)
+	return nil
+}
+
+// CreateChallenge issues the short-lived token exchanged for real tokens by VerifyChallenge
+func (s *TwoFactorService) CreateChallenge(ctx context.Context, userID string) (*twofactor.ChallengeDTO, error) {
+	token, err := s.jwtManager.GenerateChallengeToken(userID, s.options.ChallengeExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &twofactor.ChallengeDTO{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int64(s.options.ChallengeExpiry.Seconds()),
+	}, nil
+}
+
+// VerifyChallenge checks the second factor for a login challenge and returns the user ID
+// A challenge can be completed once and allows a limited number of wrong codes. Wrong codes are
+// also counted per user, since anyone with the password can request new challenges
+func (s *TwoFactorService) VerifyChallenge(ctx context.Context, challengeToken string, code string) (string, error) {
+	claims, err := s.jwtManager.ValidateToken(challengeToken)
+	if err != nil || claims.Type != utils.TokenTypeTwoFactorChallenge || claims.ID == "" {
+		return "", exceptions.UnauthorizedError("Invalid or expired challenge token", nil, nil)
+	}
+
+	ttl := s.options.ChallengeExpiry
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+
+	usedKey := "2fa:challenge:used:" + claims.ID
+	_, used, err := s.cache.Get(ctx, usedKey)
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return "", exceptions.UnauthorizedError("Invalid or expired challenge token", nil, nil)
+	}
+
+	// A locked out user is rejected before the code is checked, so guesses tell nothing
+	if err := s.checkLockout(ctx, claims.UserID); err != nil {
+		return "", err
+	}
+
+	valid, err := s.verifyCode(ctx, claims.UserID, code)
+	if err != nil {
+		return "", err
+	}
+
+	if !valid {
+		s.recorder.Record(ctx, claims.UserID, audit.EventLoginFailed, map[string]string{"reason": "invalid_two_factor_code"})
+
+		locked, err := s.recordFailure(ctx, claims.UserID)
+		if err != nil {
+			return "", err
+		}
+		if locked {
+			_ = s.cache.Set(ctx, usedKey, "1", ttl)
+			return "", s.checkLockout(ctx, claims.UserID)
+		}
+
+		attempts, err := s.cache.Increment(ctx, "2fa:challenge:attempts:"+claims.ID, ttl)
+		if err != nil {
+			return "", err
+		}
+		if attempts >= maxChallengeAttempts {
+			_ = s.cache.Set(ctx, usedKey, "1", ttl)
+			return "", exceptions.UnauthorizedError("Too many invalid codes. Please log in again.", nil, nil)
+		}
+		return "", invalidCodeError()
+	}
+
+	if err := s.cache.Set(ctx, usedKey, "1", ttl); err != nil {
+		return "", err
+	}
+	if err := s.cache.Delete(ctx, failuresKey(claims.UserID)); err != nil {
+		return "", err
+	}
+
+	return claims.UserID, nil
+}
+
+// checkLockout rejects a user whose second factor is locked after too many wrong codes
+func (s *TwoFactorService) checkLockout(ctx context.Context, userID string) error {
+	value, locked, err := s.cache.Get(ctx, lockedKey(userID))
+	if err != nil || !locked {
+		return err
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	wait := time.Until(time.Unix(0, nanos))
+	if wait <= 0 {
+		return nil
+	}
+
+	desc := fmt.Sprintf("Please wait %d seconds before trying again.", int(wait.Seconds())+1)
+	return exceptions.TooManyRequestsError("Too many invalid two-factor codes", &desc, nil)
+}
+
+// recordFailure counts a wrong code for a user and reports whether it started a lockout
+func (s *TwoFactorService) recordFailure(ctx context.Context, userID string) (bool, error) {
+	if s.options.MaxFailures <= 0 {
+		return false, nil
+	}
+
+	failures, err := s.cache.Increment(ctx, failuresKey(userID), s.options.LockoutDuration)
+	if err != nil {
+		return false, err
+	}
+	if failures < int64(s.options.MaxFailures) {
+		return false, nil
+	}
+
+	until := time.Now().Add(s.options.LockoutDuration)
+	if err := s.cache.Set(ctx, lockedKey(userID), strconv.FormatInt(until.UnixNano(), 10), s.options.LockoutDuration); err != nil {
+		return false, err
+	}
+	return true, s.cache.Delete(ctx, failuresKey(userID))
+}
+
+// verifyCode accepts a current TOTP code or an unused recovery code
+func (s *TwoFactorService) verifyCode(ctx context.Context, userID string, code string) (bool, error) {
+	secret, err := s.twoFactorRepo.FindSecretByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if secret == nil || !secret.IsConfirmed() {
+		return false, nil
+	}
+
+	plain, err := s.encrypter.Decrypt(secret.SecretEncrypted)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := utils.ValidateTOTP(plain, code, time.Now()); ok {
+		// Each code is accepted once, even while it is still within its time window
+		return s.twoFactorRepo.AdvanceLastUsedStep(ctx, secret.ID, step)
+	}
+
+	recoveryCode, err := s.twoFactorRepo.FindUnusedRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil || recoveryCode == nil {
+		return false, err
+	}
+
+	used, err := s.twoFactorRepo.MarkRecoveryCodeUsed(ctx, recoveryCode.ID)
+	if err != nil || !used {
+		return false, err
+	}
+
+	s.recorder.Record(ctx, userID, audit.EventRecoveryCodeUsed, nil)
+	return true, nil
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes and stores their digests
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, 0, s.options.RecoveryCodeCount)
+	records := make([]*twofactor.RecoveryCode, 0, s.options.RecoveryCodeCount)
+
+	for i := 0; i < s.options.RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, &twofactor.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code such as "7KQ2M-XH4TP"
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeGroupSize*recoveryCodeGroups)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	var code strings.Builder
+	for i, b := range buf {
+		if i > 0 && i%recoveryCodeGroupSize == 0 {
+			code.WriteByte('-')
+		}
+		// The alphabet has 32 characters, so the low 5 bits pick one uniformly
+		code.WriteByte(recoveryCodeAlphabet[b&31])
+	}
+	return code.String(), nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes in user input
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// failuresKey returns the cache key counting the wrong codes of a user across challenges
+func failuresKey(userID string) string {
+	return "2fa:failures:user:" + userID
+}
+
+// lockedKey returns the cache key marking a user's second factor as locked
+func lockedKey(userID string) string {
+	return "2fa:locked:user:" + userID
+}
+
+// invalidCodeError is returned for a wrong TOTP or recovery code
+func invalidCodeError() error {
+	return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+		{Field: "code", Message: "The two-factor code is invalid."},
+	})
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	twofactor "gin/internal/domain/two_factor"
+)
+
+type TwoFactorServiceInterface interface {
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+	Enroll(ctx context.Context, userID string) (*twofactor.EnrollmentDTO, error)
+	Confirm(ctx context.Context, userID string, code string) (*twofactor.RecoveryCodesDTO, error)
+	Disable(ctx context.Context, userID string, password string, code string) error
+	CreateChallenge(ctx context.Context, userID string) (*twofactor.ChallengeDTO, error)
+	VerifyChallenge(ctx context.Context, challengeToken string, code string) (string, error)
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"time"
 
+	"gin/internal/shared/pagination"
 	"gin/internal/shared/utils/transformer"
 )
 
@@ -11,10 +12,13 @@ type UserDTO struct {
 	ID               string     `json:"id"`
 	FirstName        *string    `json:"firstName,omitempty"`
 	LastName         *string    `json:"lastName,omitempty"`
-	Email            string     `json:"email"`
+	Email            string     `json:"email,omitempty"` // Omitted when the caller may not read it
 	Phone            *string    `json:"phone,omitempty"`
 	Province         *string    `json:"province,omitempty"`
 	District         *string    `json:"district,omitempty"`
+	City             *string    `json:"city,omitempty"`
+	Zip              *string    `json:"zip,omitempty"`
+	Country          *string    `json:"country,omitempty"`
 	Address          *string    `json:"address,omitempty"`
 	Type             string     `json:"type"`
 	SocialProvider   *string    `json:"socialProvider,omitempty"`
@@ -26,18 +30,18 @@ type UserDTO struct {
 	FullName         string     `json:"fullName,omitempty"`
 }
 
-// PaginationMeta represents pagination metadata
-type PaginationMeta struct {
-	Page       int   `json:"page"`
-	TotalPages int   `json:"totalPages"`
-	PerPage    int   `json:"perPage"`
-	TotalItems int64 `json:"totalItems"`
-}
-
 // PaginatedUserDTO represents a paginated list of users
+// It predates pagination.Page and keeps the users key for existing clients
 type PaginatedUserDTO struct {
-	Users []UserDTO      `json:"users"`
-	Meta  PaginationMeta `json:"meta"`
+	Users []UserDTO       `json:"users"`
+	Meta  pagination.Meta `json:"meta"`
+}
+
+// CursorPaginatedUserDTO represents a cursor-paginated list of users
+// It wraps pagination.CursorPage under the users key, like PaginatedUserDTO
+type CursorPaginatedUserDTO struct {
+	Users []UserDTO             `json:"users"`
+	Meta  pagination.CursorMeta `json:"meta"`
 }
 
 // FromUserModel converts a User model to a UserDTO
@@ -50,6 +54,9 @@ func FromUserModel(user User) UserDTO {
 		Phone:            user.Phone,
 		Province:         user.Province,
 		District:         user.District,
+		City:             user.City,
+		Zip:              user.Zip,
+		Country:          user.Country,
 		Address:          user.Address,
 		Type:             string(user.Type),
 		SocialProvider:   user.SocialProvider,
@@ -77,19 +84,17 @@ func TransformUserCollection(users []*User) []UserDTO {
 }
 
 // ToPaginatedUserDTO creates a paginated user DTO
-func ToPaginatedUserDTO(users []*User, page, perPage int, totalItems int64) PaginatedUserDTO {
-	userDTOs := TransformUserCollection(users)
-
-	// Calculate total pages
-	totalPages := int((totalItems + int64(perPage) - 1) / int64(perPage))
-
+func ToPaginatedUserDTO(page pagination.Page[*User]) PaginatedUserDTO {
 	return PaginatedUserDTO{
-		Users: userDTOs,
-		Meta: PaginationMeta{
-			Page:       page,
-			TotalPages: totalPages,
-			PerPage:    perPage,
-			TotalItems: totalItems,
-		},
+		Users: TransformUserCollection(page.Items),
+		Meta:  page.Meta,
+	}
+}
+
+// ToCursorPaginatedUserDTO creates a cursor-paginated user DTO
+func ToCursorPaginatedUserDTO(page pagination.CursorPage[*User]) CursorPaginatedUserDTO {
+	return CursorPaginatedUserDTO{
+		Users: TransformUserCollection(page.Items),
+		Meta:  page.Meta,
 	}
 }
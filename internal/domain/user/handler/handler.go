@@ -2,71 +2,151 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	exceptions "gin/internal/shared/exception"
 	"gin/internal/shared/response"
 	"gin/internal/domain/user"
 	usersvc "gin/internal/domain/user/service"
+	emailchangesvc "gin/internal/domain/email_change/service"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/patch"
+	"gin/internal/shared/queryspec"
 	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAllUsers handles GET /users request
 // @Summary      List all users
-// @Description  Get a paginated list of all users
+// @Description  Get a paginated list of users. Emails are only included for the caller's own account, or for every account with the users:read:email permission.
+// @Description  Filter with filter[status], filter[type] (comma-separated values), filter[country] and filter[created_at][gt|gte|lt|lte]; search first and last names with q; sort with a comma-separated list of keys, prefixed with - for descending. Email is searchable and sortable with the users:read:email permission. Unknown filters or sort keys are rejected with 422.
+// @Description  Passing limit or cursor switches from page/per_page to cursor pagination: the data is a user.CursorPaginatedUserDTO whose meta holds nextCursor and prevCursor, users are ordered by creation (sort=-created_at for newest first) and no total is counted. In page mode, count=estimate reports the planner's estimate and count=none skips the total.
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        page      query     int  false  "Page number"  default(1)
-// @Param        per_page  query     int  false  "Items per page"  default(10)  maximum(100)
-// @Success      200       {object}  response.Response{data=user.PaginatedUserDTO}
-// @Failure      500       {object}  response.ErrorResponse
+// @Param        page                     query     int     false  "Page number"  default(1)  minimum(1)
+// @Param        per_page                 query     int     false  "Items per page"  default(10)  minimum(1)  maximum(100)
+// @Param        count                    query     string  false  "How to count the total in page mode"  Enums(exact, estimate, none)  default(exact)
+// @Param        limit                    query     int     false  "Items per page in cursor mode"  default(10)  minimum(1)  maximum(100)
+// @Param        cursor                   query     string  false  "nextCursor or prevCursor of the previous response"
+// @Param        filter[status]           query     string  false  "Account statuses, comma-separated"  example(active,banned)
+// @Param        filter[type]             query     string  false  "Account types, comma-separated"  example(user,staff)
+// @Param        filter[country]          query     string  false  "Countries, comma-separated"
+// @Param        filter[created_at][gte]  query     string  false  "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        filter[created_at][lte]  query     string  false  "Created at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param        q                        query     string  false  "Search term"  maxlength(100)
+// @Param        sort                     query     string  false  "Sort keys: created_at, updated_at, last_sign_in_at, first_name, last_name, status, email"  example(-created_at)
+// @Success      200                      {object}  response.Response{data=user.PaginatedUserDTO}
+// @Header       200                      {string}  Link  "RFC 8288 links to the first, prev, next and last pages"
+// @Failure      422                      {object}  response.ErrorResponse
+// @Failure      500                      {object}  response.ErrorResponse
 // @Router       /users [get]
 
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
-	userService usersvc.UserServiceInterface
+	userService        usersvc.UserServiceInterface
+	emailChangeService emailchangesvc.EmailChangeServiceInterface
+	authorizer         authz.Authorizer
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService usersvc.UserServiceInterface) *UserHandler {
+func NewUserHandler(userService usersvc.UserServiceInterface, emailChangeService emailchangesvc.EmailChangeServiceInterface, authorizer authz.Authorizer) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:        userService,
+		emailChangeService: emailChangeService,
+		authorizer:         authorizer,
 	}
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	// Get pagination parameters from query string
-	page := 1
-	perPage := 10
+	canReadEmails, err := h.canReadAllEmails(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	spec, err := user.ListQuery(canReadEmails).Parse(c.Request.URL.Query())
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	if perPageStr := c.Query("per_page"); perPageStr != "" {
-		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 100 {
-			perPage = pp
-		}
+	// A cursor or a limit switches to keyset pagination
+	if c.Query("cursor") != "" || c.Query("limit") != "" {
+		h.getUsersByCursor(c, spec)
+		return
 	}
 
-	users, total, err := h.userService.GetAllUsersPaginated(c.Request.Context(), page, perPage)
+	params, err := pagination.BindParams(c, pagination.DefaultPerPage)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	paginatedDTO := user.ToPaginatedUserDTO(users, page, perPage, total)
+	page, err := h.userService.GetAllUsersPaginated(c.Request.Context(), spec, params)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	paginatedDTO := user.ToPaginatedUserDTO(page)
+	if err := h.redactEmails(c, paginatedDTO.Users); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	pagination.SetLinkHeader(c, page.Meta)
+	response.SendResponse(c, paginatedDTO, "users retrieved successfully")
+}
+
+// getUsersByCursor lists users with keyset pagination on their ULIDs, in creation order
+func (h *UserHandler) getUsersByCursor(c *gin.Context, spec queryspec.Spec) {
+	params, err := pagination.BindCursorParams(c, pagination.DefaultPerPage)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var fieldErrors []validators.ValidationError
+	if c.Query("page") != "" || c.Query("per_page") != "" || c.Query("count") != "" {
+		fieldErrors = append(fieldErrors, validators.ValidationError{Field: "cursor", Message: "The cursor cannot be combined with page, per_page or count."})
+	}
+
+	desc := false
+	switch c.Query("sort") {
+	case "", "created_at":
+	case "-created_at":
+		desc = true
+	default:
+		fieldErrors = append(fieldErrors, validators.ValidationError{Field: "sort", Message: "Cursor pagination can only sort on created_at."})
+	}
+
+	if len(fieldErrors) > 0 {
+		appErr := exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+		_ = c.Error(appErr)
+		return
+	}
+
+	result, err := h.userService.GetAllUsersByCursor(c.Request.Context(), spec, params, desc)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	paginatedDTO := user.ToCursorPaginatedUserDTO(pagination.NewCursorPage(result, params.Limit))
+	if err := h.redactEmails(c, paginatedDTO.Users); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	pagination.SetCursorLinkHeader(c, result.NextCursor, result.PrevCursor)
 	response.SendResponse(c, paginatedDTO, "users retrieved successfully")
 }
 
 // GetUserByID handles GET /users/:id request
 // @Summary      Get user by ID
-// @Description  Get a specific user by their ID
+// @Description  Get a specific user by their ID. The email is only included for the caller's own account, or with the users:read:email permission.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -89,13 +169,47 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	userDTO := user.FromUserModel(*u)
-	response.SendResponse(c, userDTO, "user retrieved successfully")
+	userDTOs := []user.UserDTO{user.FromUserModel(*u)}
+	if err := h.redactEmails(c, userDTOs); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.SendResponse(c, userDTOs[0], "user retrieved successfully")
+}
+
+// redactEmails clears the email of every user the caller may not read it from
+// Users can always read their own email; reading others' requires the users:read:email permission
+func (h *UserHandler) redactEmails(c *gin.Context, users []user.UserDTO) error {
+	ctx := c.Request.Context()
+	principal, _ := authz.PrincipalFromContext(ctx)
+
+	for i := range users {
+		allowed, err := h.authorizer.Can(ctx, principal, constant.PermissionUsersReadEmail, user.Resource(users[i].ID))
+		if err != nil {
+			return exceptions.InternalError("Failed to check permissions", nil, nil)
+		}
+		if !allowed {
+			users[i].Email = ""
+		}
+	}
+	return nil
+}
+
+// canReadAllEmails reports whether the caller may read the email of any account, not only its own
+func (h *UserHandler) canReadAllEmails(c *gin.Context) (bool, error) {
+	ctx := c.Request.Context()
+	principal, _ := authz.PrincipalFromContext(ctx)
+
+	allowed, err := h.authorizer.Can(ctx, principal, constant.PermissionUsersReadEmail, authz.Resource{Type: user.ResourceType})
+	if err != nil {
+		return false, exceptions.InternalError("Failed to check permissions", nil, nil)
+	}
+	return allowed, nil
 }
 
 // UpdateUser handles PUT /users/:id request
 // @Summary      Update user
-// @Description  Update an existing user's information
+// @Description  Update an existing user's information. Only the account owner or an admin may update an account. A new email is not applied right away: a confirmation link is sent to the new address and a notice to the current one, and the address changes once the link is confirmed (POST /auth/email-change/confirm). An email used by another account is rejected with 422. API keys cannot change the email (403), and impersonation tokens cannot update the user at all. Passwords are changed with POST /auth/change-password.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -105,6 +219,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 // @Success      200   {object}  response.Response{data=user.UserDTO}
 // @Failure      400   {object}  response.ErrorResponse
 // @Failure      401   {object}  response.ErrorResponse
+// @Failure      403   {object}  response.ErrorResponse
 // @Failure      404   {object}  response.ErrorResponse
 // @Failure      422   {object}  response.ErrorResponse
 // @Failure      500   {object}  response.ErrorResponse
@@ -133,29 +248,194 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if req.Email != nil {
+		if err := credentialChangeError(c); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+
 	// Convert request to update map
 	updates := make(map[string]interface{})
 	if req.Name != nil {
 		updates["first_name"] = *req.Name
 	}
-	if req.Email != nil {
-		updates["email"] = *req.Email
+
+	h.applyUpdate(c, id, updates, req.Email)
+}
+
+// PatchUser handles PATCH /users/:id request
+// @Summary      Patch user
+// @Description  Partially update a user with a JSON merge patch (RFC 7386, Content-Type application/merge-patch+json) or a JSON patch (RFC 6902, Content-Type application/json-patch+json). Only firstName, lastName, email, phone, province, district, city, zip, country and address can be patched; null, or a remove operation, clears every field but the email. Phones, countries and zips are checked and normalized as for PUT /users/{id}/profile.
+// @Description  A new email starts the same confirmation flow as PUT /users/{id}, and is likewise refused (403) to API keys. Impersonation tokens cannot patch the user (403). JSON patch operations are validated before any is applied, errors name the operation index (e.g. 1.path), and a failed test operation returns 409 without changing anything. Other content types get 415 with an Accept-Patch header.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string  true  "User ID"
+// @Param        patch  body      object  true  "Merge patch object or array of JSON patch operations"
+// @Success      200    {object}  response.Response{data=user.UserDTO}
+// @Failure      401    {object}  response.ErrorResponse
+// @Failure      403    {object}  response.ErrorResponse
+// @Failure      404    {object}  response.ErrorResponse
+// @Failure      409    {object}  response.ErrorResponse
+// @Failure      415    {object}  response.ErrorResponse
+// @Failure      422    {object}  response.ErrorResponse
+// @Failure      500    {object}  response.ErrorResponse
+// @Router       /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id := c.Param("id")
+
+	contentType := c.ContentType()
+	if err := patch.CheckContentType(contentType); err != nil {
+		c.Header("Accept-Patch", patch.AcceptPatch)
+		_ = c.Error(err)
+		return
 	}
 
-	updatedUser, err := h.userService.UpdateUser(c.Request.Context(), updates, req.Password, id)
+	body, err := c.GetRawData()
+	if err != nil {
+		appErr := exceptions.ValidationError("Invalid request format. Please check your JSON syntax.", nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	existingUser, err := h.userService.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	schema := user.PatchSchema()
+	before := user.PatchDocument(existingUser)
+	after, err := schema.Apply(contentType, body, before)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := user.NormalizeProfile(before, after); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	updates, err := schema.Changes(before, after)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
+	// The email goes through the confirmation flow instead of the update
+	var newEmail *string
+	if email, ok := updates["email"].(string); ok {
+		newEmail = &email
+		delete(updates, "email")
+	}
+
+	if newEmail != nil {
+		if err := credentialChangeError(c); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	h.applyUpdate(c, id, updates, newEmail)
+}
+
+// UpdateProfile handles PUT /users/:id/profile request
+// @Summary      Update user profile
+// @Description  Replace a user's profile: names, phone, province, district, city, zip, country and address. Omitted, null and blank fields are cleared; the email and credentials are not touched. Only the account owner or an admin may update a profile, and not through an impersonation token (403).
+// @Description  Phones must include the country calling code and are stored in E.164 form (+9779812345678); spaces, dashes, dots, parentheses and a leading 00 are accepted. Countries are ISO 3166-1 alpha-2 codes (NP), and a zip requires a country and must match its postal code format. Only changed values are checked, and a zip is checked again when the country changes.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true  "User ID"
+// @Param        profile  body      user.UserProfileRequest  true  "Profile data"
+// @Success      200      {object}  response.Response{data=user.UserDTO}
+// @Failure      401      {object}  response.ErrorResponse
+// @Failure      403      {object}  response.ErrorResponse
+// @Failure      404      {object}  response.ErrorResponse
+// @Failure      422      {object}  response.ErrorResponse
+// @Failure      500      {object}  response.ErrorResponse
+// @Router       /users/{id}/profile [put]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	id := c.Param("id")
+
+	var req user.UserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrors := utils.ExtractBindingErrors(err)
+		if len(validationErrors) > 0 {
+			appErr := exceptions.ValidationError("The given data was invalid.", nil, validationErrors)
+			_ = c.Error(appErr)
+			return
+		}
+		appErr := exceptions.ValidationError("Invalid request format. Please check your JSON syntax.", nil)
+		_ = c.Error(appErr)
+		return
+	}
+
+	existingUser, err := h.userService.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	before := user.PatchDocument(existingUser)
+	after := req.Document(before)
+	if err := user.NormalizeProfile(before, after); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	updates, err := user.PatchSchema().Changes(before, after)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	h.applyUpdate(c, id, updates, nil)
+}
+
+// credentialChangeError rejects email changes made with an API key
+// A leaked API key must not be enough to take over the account; impersonation tokens are
+// already refused by the route
+func credentialChangeError(c *gin.Context) error {
+	if principal, ok := authz.PrincipalFromContext(c.Request.Context()); ok && principal.APIKeyID != "" {
+		return exceptions.ForbiddenError("Email changes are not available to API keys", nil, nil)
+	}
+	return nil
+}
+
+// applyUpdate writes the updates and starts an email change when a new email is given
+func (h *UserHandler) applyUpdate(c *gin.Context, id string, updates map[string]interface{}, newEmail *string) {
+	updatedUser, err := h.userService.UpdateUser(c.Request.Context(), updates, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// A new email only replaces the current one once the link sent to it is confirmed
+	message := "user updated successfully"
+	if newEmail != nil {
+		pending, err := h.emailChangeService.RequestChange(c.Request.Context(), id, *newEmail)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if pending {
+			message = "user updated successfully; confirm the new email address to complete the change"
+		}
+	}
+
 	// Convert model to response DTO
 	userDTO := user.FromUserModel(*updatedUser)
-	response.SendResponse(c, userDTO, "user updated successfully")
+	response.SendResponse(c, userDTO, message)
 }
 
 // DeleteUser handles DELETE /users/:id request
 // @Summary      Delete user
-// @Description  Delete a user account
+// @Description  Delete a user account. Only the account owner or an admin may delete an account.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -164,6 +444,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Success      200  {object}  response.Response
 // @Failure      400  {object}  response.ErrorResponse
 // @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
 // @Failure      404  {object}  response.ErrorResponse
 // @Failure      500  {object}  response.ErrorResponse
 // @Router       /users/{id} [delete]
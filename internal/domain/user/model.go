@@ -28,6 +28,7 @@ type User struct {
 	SocialProviderID *string                  `json:"social_provider_id,omitempty" gorm:"type:varchar(100)"`
 	LastSignInAt     *time.Time               `json:"last_sign_in_at,omitempty"`
 	Status           constant.UserStatusEnum  `json:"status" gorm:"type:varchar(20);default:'inactive'"`
+	TokenVersion     int                      `json:"-" gorm:"not null;default:0"`
 	CreatedAt        time.Time                `json:"created_at"`
 	UpdatedAt        time.Time                `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt           `json:"deleted_at,omitempty" gorm:"index"`
@@ -69,3 +70,26 @@ func (u *User) FullName() string {
 func (User) TableName() string {
 	return "users"
 }
+
+// PasswordHistory holds the hash of a password a user has set, to prevent its reuse
+type PasswordHistory struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID       string    `json:"user_id" gorm:"type:char(26);not null;index"`
+	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for generating ID
+func (h *PasswordHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		h.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the PasswordHistory model
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"gin/internal/shared/patch"
+)
+
+// PatchSchema describes the members accepted by PATCH /users/:id, named as in UserDTO
+// A new email is not written directly: the handler turns it into a pending email change.
+// Phones, countries and zips are normalized by NormalizeProfile before Changes checks these rules.
+// Credentials, the account type and the status are deliberately left out
+func PatchSchema() patch.Schema {
+	return patch.Schema{
+		"firstName": {Column: "first_name", Nullable: true, Rules: "min=1,max=100"},
+		"lastName":  {Column: "last_name", Nullable: true, Rules: "min=1,max=100"},
+		"email":     {Column: "email", Rules: "email,max=255"},
+		"phone":     {Column: "phone", Nullable: true, Rules: "min=1,max=20"},
+		"province":  {Column: "province", Nullable: true, Rules: "min=1,max=100"},
+		"district":  {Column: "district", Nullable: true, Rules: "min=1,max=100"},
+		"city":      {Column: "city", Nullable: true, Rules: "min=1,max=100"},
+		"zip":       {Column: "zip", Nullable: true, Rules: "min=1,max=10"},
+		"country":   {Column: "country", Nullable: true, Rules: "len=2"},
+		"address":   {Column: "address", Nullable: true, Rules: "min=1,max=255"},
+	}
+}
+
+// PatchDocument returns the patchable state of a user; unset columns are left out
+func PatchDocument(u *User) patch.Document {
+	doc := patch.Document{"email": u.Email}
+	optional := map[string]*string{
+		"firstName": u.FirstName,
+		"lastName":  u.LastName,
+		"phone":     u.Phone,
+		"province":  u.Province,
+		"district":  u.District,
+		"city":      u.City,
+		"zip":       u.Zip,
+		"country":   u.Country,
+		"address":   u.Address,
+	}
+	for name, value := range optional {
+		if value != nil {
+			doc[name] = *value
+		}
+	}
+	return doc
+}
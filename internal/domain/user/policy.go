@@ -0,0 +1,11 @@
+package user
+
+import "gin/internal/shared/authz"
+
+// ResourceType identifies user accounts in authorization checks
+const ResourceType = "user"
+
+// Resource describes a user account to the policy engine; an account is owned by its user
+func Resource(id string) authz.Resource {
+	return authz.Resource{Type: ResourceType, ID: id, OwnerID: id}
+}
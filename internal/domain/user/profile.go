@@ -0,0 +1,84 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"gin/internal/shared/contact"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/patch"
+	validators "gin/internal/shared/validator"
+)
+
+// Document returns the profile as a patch document on top of the current one
+// Every profile field is replaced: omitted, null and blank fields are cleared, and the email is kept
+func (r UserProfileRequest) Document(current patch.Document) patch.Document {
+	doc := patch.Document{}
+	if email, ok := current["email"]; ok {
+		doc["email"] = email
+	}
+	fields := map[string]*string{
+		"firstName": r.FirstName,
+		"lastName":  r.LastName,
+		"phone":     r.Phone,
+		"province":  r.Province,
+		"district":  r.District,
+		"city":      r.City,
+		"zip":       r.Zip,
+		"country":   r.Country,
+		"address":   r.Address,
+	}
+	for name, value := range fields {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			doc[name] = strings.TrimSpace(*value)
+		}
+	}
+	return doc
+}
+
+// NormalizeProfile checks the contact fields that changed between before and after and rewrites
+// them in canonical form: phones in E.164, countries as ISO 3166-1 alpha-2 codes and zips in the
+// postal code format of the country. A zip is checked again whenever the country changes, and
+// unchanged values stored before these rules existed are left alone
+func NormalizeProfile(before, after patch.Document) error {
+	changed := func(name string) bool {
+		oldValue, wasSet := before[name]
+		newValue, isSet := after[name]
+		return isSet && (!wasSet || oldValue != newValue)
+	}
+
+	var fieldErrors []validators.ValidationError
+	if changed("phone") {
+		if phone, ok := contact.NormalizePhone(after["phone"]); ok {
+			after["phone"] = phone
+		} else {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "phone", Message: "The phone must be a valid international phone number, such as +9779812345678."})
+		}
+	}
+
+	countryValid := true
+	if changed("country") {
+		if country, ok := contact.NormalizeCountry(after["country"]); ok {
+			after["country"] = country
+		} else {
+			countryValid = false
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "country", Message: "The country must be a valid ISO 3166-1 alpha-2 code, such as NP."})
+		}
+	}
+
+	if zip, isSet := after["zip"]; isSet && countryValid && (changed("zip") || changed("country")) {
+		country, hasCountry := after["country"]
+		if !hasCountry {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "zip", Message: "The country field is required when zip is present."})
+		} else if code, ok := contact.NormalizePostalCode(country, zip); ok {
+			after["zip"] = code
+		} else {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "zip", Message: fmt.Sprintf("The zip is not a valid postal code for %s.", country)})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"gin/internal/shared/constant"
+	"gin/internal/shared/queryspec"
+)
+
+// ListQuery describes the filters, sorts and search accepted when listing users
+// Email is only searchable and sortable for callers who may read every email,
+// so neither can be used to probe the addresses of other accounts
+func ListQuery(canReadEmails bool) queryspec.Schema {
+	schema := queryspec.Schema{
+		Filters: map[string]queryspec.Filter{
+			"status": {Column: "status", Allowed: []string{
+				string(constant.UserStatusActive), string(constant.UserStatusInactive), string(constant.UserStatusBanned),
+			}},
+			"type": {Column: "type", Allowed: []string{
+				string(constant.AccountTypeCustomer), string(constant.AccountTypeStaff), string(constant.AccountTypeAdmin), string(constant.AccountTypeService),
+			}},
+			"country":    {Column: "country"},
+			"created_at": {Column: "created_at", Kind: queryspec.TimeRange},
+		},
+		Sorts: map[string]string{
+			"created_at":      "created_at",
+			"updated_at":      "updated_at",
+			"last_sign_in_at": "last_sign_in_at",
+			"first_name":      "first_name",
+			"last_name":       "last_name",
+			"status":          "status",
+		},
+		Search:     []string{"first_name", "last_name"},
+		TieBreaker: "id",
+	}
+
+	if canReadEmails {
+		schema.Sorts["email"] = "email"
+		schema.Search = append(schema.Search, "email")
+	}
+	return schema
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"gin/internal/domain/user"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/queryspec"
 
 	"gorm.io/gorm"
 )
@@ -33,27 +35,22 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*user.User, error) {
 	return users, err
 }
 
-// GetAllPaginated retrieves users with pagination
-func (r *UserRepository) GetAllPaginated(ctx context.Context, page, perPage int) ([]*user.User, int64, error) {
-	var users []*user.User
-	var total int64
-
-	// Get total count
-	err := r.getDB(ctx).WithContext(ctx).Model(&user.User{}).Count(&total).Error
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// Calculate offset
-	offset := (page - 1) * perPage
+// GetAllPaginated retrieves a page of the users matching the query spec
+func (r *UserRepository) GetAllPaginated(ctx context.Context, spec queryspec.Spec, params pagination.Params) (pagination.Page[*user.User], error) {
+	query := r.getDB(ctx).WithContext(ctx).Model(&user.User{}).Scopes(spec.Filter, spec.Sort)
+	return pagination.Paginate[*user.User](query, params)
+}
 
-	// Get paginated users
-	err = r.getDB(ctx).WithContext(ctx).Offset(offset).Limit(perPage).Find(&users).Error
+// GetAllByCursor retrieves the users matching the query spec after or before a cursor
+// Users are ordered by their ULID, which follows creation time, so no count or offset is needed
+func (r *UserRepository) GetAllByCursor(ctx context.Context, spec queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*user.User], error) {
+	var users []*user.User
+	err := r.getDB(ctx).WithContext(ctx).Scopes(spec.Filter, pagination.Keyset("id", params.Cursor, params.Limit, desc)).Find(&users).Error
 	if err != nil {
-		return nil, 0, err
+		return pagination.CursorResult[*user.User]{}, err
 	}
 
-	return users, total, err
+	return pagination.NewCursorResult(users, func(u *user.User) string { return u.ID }, params.Cursor, params.Limit), nil
 }
 
 // Create creates a new user
@@ -75,6 +72,12 @@ func (r *UserRepository) UpdateFields(ctx context.Context, id string, updates ma
 	return r.getDB(ctx).WithContext(ctx).Model(&user.User{}).Where("id = ?", id).Updates(updates).Error
 }
 
+// IncrementTokenVersion bumps the access token version of a user, invalidating every issued access token
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	return r.getDB(ctx).WithContext(ctx).Model(&user.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).Error
+}
+
 // Delete deletes a user by ID
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	return r.getDB(ctx).WithContext(ctx).Where("id = ?", id).Delete(&user.User{}).Error
@@ -105,6 +108,55 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*user.U
 	return &user, nil
 }
 
+// ExistsByEmail reports whether any account, including a soft-deleted one, uses the email
+// Deleted accounts keep their row, so their address still collides with the unique index
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.getDB(ctx).WithContext(ctx).Unscoped().Model(&user.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+// FindBySocialProvider finds the user linked to an account at a social login provider
+func (r *UserRepository) FindBySocialProvider(ctx context.Context, provider string, providerID string) (*user.User, error) {
+	var user user.User
+	err := r.getDB(ctx).WithContext(ctx).Where("social_provider = ? AND social_provider_id = ?", provider, providerID).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AddPasswordHistory records the hash of a password set for a user
+func (r *UserRepository) AddPasswordHistory(ctx context.Context, entry *user.PasswordHistory) error {
+	return r.getDB(ctx).WithContext(ctx).Create(entry).Error
+}
+
+// FindPasswordHistory returns the most recent password hashes of a user, newest first
+func (r *UserRepository) FindPasswordHistory(ctx context.Context, userID string, limit int) ([]*user.PasswordHistory, error) {
+	var entries []*user.PasswordHistory
+	err := r.getDB(ctx).WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// PrunePasswordHistory deletes all but the most recent password hashes of a user
+func (r *UserRepository) PrunePasswordHistory(ctx context.Context, userID string, keep int) error {
+	db := r.getDB(ctx).WithContext(ctx)
+	recent := db.Model(&user.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(keep)
+
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&user.PasswordHistory{}).Error
+}
+
 // WithTransaction executes a function within a database transaction
 // If the function returns an error, the transaction is rolled back
 func (r *UserRepository) WithTransaction(ctx context.Context, fn func(*gorm.DB) error) error {
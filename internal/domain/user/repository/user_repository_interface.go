@@ -3,17 +3,21 @@ package repository
 import (
 	"context"
 	"gin/internal/domain/user"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/queryspec"
 )
 
 // UserRepositoryInterface defines user repository operations
 type UserRepositoryInterface interface {
 	// Basic CRUD operations
 	GetAll(ctx context.Context) ([]*user.User, error)
-	GetAllPaginated(ctx context.Context, page, perPage int) ([]*user.User, int64, error)
+	GetAllPaginated(ctx context.Context, spec queryspec.Spec, params pagination.Params) (pagination.Page[*user.User], error)
+	GetAllByCursor(ctx context.Context, spec queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*user.User], error)
 	Create(ctx context.Context, user *user.User) (*user.User, error)
 	Update(ctx context.Context, user *user.User) error
 	UpdateFields(ctx context.Context, id string, updates map[string]interface{}) error
 	Delete(ctx context.Context, id string) error
+	IncrementTokenVersion(ctx context.Context, id string) error
 
 	// Find operations
 	FindByID(ctx context.Context, id string) (*user.User, error)
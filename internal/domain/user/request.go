@@ -4,14 +4,28 @@ package user
 type UserCreateRequest struct {
 	Name     string `json:"name" binding:"required,min=2,max=100"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6,max=100"`
+	Password string `json:"password" binding:"required"`
 }
 
 // UserUpdateRequest represents the request payload for updating an existing user
+// Passwords are only changed with the current one, through the change password endpoint
 type UserUpdateRequest struct {
-	Name     *string `json:"name,omitempty" binding:"omitempty,min=2,max=100"`
-	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
-	Password *string `json:"password,omitempty" binding:"omitempty,min=6,max=100"`
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=2,max=100"`
+	Email *string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+// UserProfileRequest represents the request payload for replacing a user's profile
+// Omitted, null and blank fields are cleared
+type UserProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,max=100"`
+	LastName  *string `json:"last_name" binding:"omitempty,max=100"`
+	Phone     *string `json:"phone" binding:"omitempty,max=32"` // International number, stored in E.164 form
+	Province  *string `json:"province" binding:"omitempty,max=100"`
+	District  *string `json:"district" binding:"omitempty,max=100"`
+	City      *string `json:"city" binding:"omitempty,max=100"`
+	Zip       *string `json:"zip" binding:"omitempty,max=10"`    // Checked against the postal code format of the country
+	Country   *string `json:"country" binding:"omitempty,max=2"` // ISO 3166-1 alpha-2 code
+	Address   *string `json:"address" binding:"omitempty,max=255"`
 }
 
 // SignupInput represents data needed to create a user during signup
@@ -20,3 +34,12 @@ type SignupInput struct {
 	LastName  string
 	Email     string
 }
+
+// SocialSignupInput represents data needed to create a user from a social login identity
+type SocialSignupInput struct {
+	FirstName        string
+	LastName         string
+	Email            string
+	SocialProvider   string
+	SocialProviderID string
+}
@@ -3,27 +3,56 @@ package service
 import (
 	"context"
 	"errors"
-	"gin/internal/shared/constant"
-	exceptions "gin/internal/shared/exception"
+	"fmt"
 	"gin/internal/domain/user"
 	userRepository "gin/internal/domain/user/repository"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/cache"
+	"gin/internal/shared/constant"
+	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/password"
+	"gin/internal/shared/queryspec"
+	validators "gin/internal/shared/validator"
+	"strconv"
+	"strings"
+	"time"
 
 	"gin/internal/shared/utils"
 
-	"fmt"
-
+	"github.com/oklog/ulid/v2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// serviceAccountEmailDomain is a reserved domain that never receives mail
+const serviceAccountEmailDomain = "service-accounts.invalid"
+
+// Options configures the user service
+type Options struct {
+	// TokenVersionCacheTTL bounds how long a cached access token version is trusted
+	TokenVersionCacheTTL time.Duration
+	// PasswordHistory is how many previous passwords a user may not reuse; zero allows reuse
+	PasswordHistory int
+}
+
 // UserService implements UserServiceInterface
 type UserService struct {
-	userRepo *userRepository.UserRepository
+	userRepo       *userRepository.UserRepository
+	cache          cache.Cache
+	passwordPolicy *password.Policy
+	recorder       audit.Recorder
+	options        Options
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *userRepository.UserRepository) UserServiceInterface {
+func NewUserService(userRepo *userRepository.UserRepository, cache cache.Cache, passwordPolicy *password.Policy, recorder audit.Recorder, options Options) UserServiceInterface {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		cache:          cache,
+		passwordPolicy: passwordPolicy,
+		recorder:       recorder,
+		options:        options,
 	}
 }
 
@@ -32,20 +61,21 @@ func (s *UserService) GetAllUsers(ctx context.Context) ([]*user.User, error) {
 	return s.userRepo.GetAll(ctx)
 }
 
-// GetAllUsersPaginated retrieves users with pagination
-func (s *UserService) GetAllUsersPaginated(ctx context.Context, page, perPage int) ([]*user.User, int64, error) {
-	// Validate pagination parameters
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 {
-		perPage = 10 // Default page size
+// GetAllUsersPaginated retrieves the users matching the query spec with pagination
+func (s *UserService) GetAllUsersPaginated(ctx context.Context, spec queryspec.Spec, params pagination.Params) (pagination.Page[*user.User], error) {
+	return s.userRepo.GetAllPaginated(ctx, spec, params)
+}
+
+// GetAllUsersByCursor retrieves the users matching the query spec with cursor pagination
+func (s *UserService) GetAllUsersByCursor(ctx context.Context, spec queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*user.User], error) {
+	if params.Limit < 1 {
+		params.Limit = pagination.DefaultPerPage
 	}
-	if perPage > 100 {
-		perPage = 100 // Maximum page size
+	if params.Limit > pagination.MaxPerPage {
+		params.Limit = pagination.MaxPerPage
 	}
 
-	return s.userRepo.GetAllPaginated(ctx, page, perPage)
+	return s.userRepo.GetAllByCursor(ctx, spec, params, desc)
 }
 
 // GetUserByID retrieves a user by ID
@@ -83,7 +113,6 @@ func (s *UserService) CreateUser(ctx context.Context, req user.SignupInput) (*us
 	// Generate a random password since password field is required in the database
 	// User will set their own password after email verification
 	randomPassword := utils.GeneratePassword()
-	fmt.Println("randomPassword", randomPassword)
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -101,8 +130,64 @@ func (s *UserService) CreateUser(ctx context.Context, req user.SignupInput) (*us
 	return s.userRepo.Create(ctx, &user)
 }
 
+// CreateSocialUser creates an active user from an identity verified by a social login provider
+// The random password only satisfies the column; the user can set a real one via forgot-password
+func (s *UserService) CreateSocialUser(ctx context.Context, req user.SocialSignupInput) (*user.User, error) {
+	existingUser, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		return nil, exceptions.ValidationError("User already exists with this email", nil, nil)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(utils.GeneratePassword()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider has verified the email, so the account is active right away
+	user := user.User{
+		FirstName:        optionalString(req.FirstName),
+		LastName:         optionalString(req.LastName),
+		Email:            req.Email,
+		Password:         string(hashedPassword),
+		SocialProvider:   &req.SocialProvider,
+		SocialProviderID: &req.SocialProviderID,
+		Status:           constant.UserStatusActive,
+	}
+
+	return s.userRepo.Create(ctx, &user)
+}
+
+// CreateServiceAccount creates an account for a machine client that authenticates with API keys only
+// It has no password and an undeliverable address, so it can neither log in nor reset a password
+func (s *UserService) CreateServiceAccount(ctx context.Context, name string) (*user.User, error) {
+	id := ulid.Make().String()
+
+	account := user.User{
+		ID:        id,
+		FirstName: &name,
+		Email:     strings.ToLower(id) + "@" + serviceAccountEmailDomain,
+		Type:      constant.AccountTypeService,
+		Status:    constant.UserStatusActive,
+	}
+
+	return s.userRepo.Create(ctx, &account)
+}
+
+// LinkSocialProvider links an existing user to an account at a social login provider
+func (s *UserService) LinkSocialProvider(ctx context.Context, id string, provider string, providerID string) error {
+	return s.userRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"social_provider":    provider,
+		"social_provider_id": providerID,
+	})
+}
+
 // UpdateUser updates an existing user
-func (s *UserService) UpdateUser(ctx context.Context, updates map[string]interface{}, password *string, id string) (*user.User, error) {
+// Passwords are not updated here; ChangePassword checks the current one first
+func (s *UserService) UpdateUser(ctx context.Context, updates map[string]interface{}, id string) (*user.User, error) {
 	if id == "" {
 		return nil, errors.New("user ID is required")
 	}
@@ -116,20 +201,18 @@ func (s *UserService) UpdateUser(ctx context.Context, updates map[string]interfa
 		return nil, exceptions.NotFoundError("User not found", nil, nil)
 	}
 
-	// Handle password separately if provided
-	if password != nil && *password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
-		}
-		updates["password"] = string(hashedPassword)
-	}
-
 	err = s.userRepo.UpdateFields(ctx, id, updates)
 	if err != nil {
 		return nil, err
 	}
 
+	// A new account type cuts off every token still carrying the old role
+	if _, typeChanged := updates["type"]; typeChanged {
+		if err := s.RevokeAccessTokens(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedUser, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -151,9 +234,240 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 		return exceptions.NotFoundError("User not found", nil, nil)
 	}
 
-	return s.userRepo.Delete(ctx, id)
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Drop the cached token version once the deletion commits, so the deleted account's
+	// access tokens are rejected
+	utils.AfterCommit(ctx, func() {
+		if err := s.cache.Delete(ctx, tokenVersionCacheKey(id)); err != nil {
+			logger.LogError(err, "Failed to evict the cached token version", map[string]interface{}{"user_id": id})
+		}
+	})
+	return nil
+}
+
+// SetPassword checks a new password against the password policy, then hashes and stores it
+func (s *UserService) SetPassword(ctx context.Context, id string, password string) error {
+	existingUser, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashNewPassword(ctx, existingUser, password)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"password": hashedPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordPasswordHistory(ctx, id, hashedPassword); err != nil {
+		return err
+	}
+
+	return s.RevokeAccessTokens(ctx, id)
+}
+
+// ChangePassword sets a new password after verifying the current one
+func (s *UserService) ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error {
+	existingUser, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existingUser.Password == "" || bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(currentPassword)) != nil {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: "current_password", Message: "The current password is incorrect."},
+		})
+	}
+
+	if err := s.SetPassword(ctx, id, newPassword); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, id, audit.EventPasswordChanged, nil)
+	return nil
+}
+
+// hashNewPassword enforces the password policy and the reuse rule, then hashes the password
+// Violations are returned as Laravel-style validation errors on the password field
+func (s *UserService) hashNewPassword(ctx context.Context, u *user.User, password string) (string, error) {
+	messages, err := s.passwordPolicy.Validate(password, u.Email, u.FullName())
+	if err != nil {
+		return "", err
+	}
+
+	if len(messages) == 0 {
+		reused, err := s.isRecentPassword(ctx, u, password)
+		if err != nil {
+			return "", err
+		}
+		if reused {
+			messages = append(messages, fmt.Sprintf("The password must not match any of your last %d passwords.", s.options.PasswordHistory))
+		}
+	}
+
+	if len(messages) > 0 {
+		fieldErrors := make([]validators.ValidationError, 0, len(messages))
+		for _, message := range messages {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "password", Message: message})
+		}
+		return "", exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+// isRecentPassword reports whether the password matches the current one or one kept in the history
+func (s *UserService) isRecentPassword(ctx context.Context, u *user.User, password string) (bool, error) {
+	if s.options.PasswordHistory <= 0 {
+		return false, nil
+	}
+
+	if u.Password != "" && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil {
+		return true, nil
+	}
+
+	history, err := s.userRepo.FindPasswordHistory(ctx, u.ID, s.options.PasswordHistory)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range history {
+		if bcrypt.CompareHashAndPassword([]byte(entry.PasswordHash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// recordPasswordHistory remembers a newly set password and forgets those beyond the history size
+func (s *UserService) recordPasswordHistory(ctx context.Context, id string, hashedPassword string) error {
+	if s.options.PasswordHistory <= 0 {
+		return nil
+	}
+
+	err := s.userRepo.AddPasswordHistory(ctx, &user.PasswordHistory{
+		UserID:       id,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.PrunePasswordHistory(ctx, id, s.options.PasswordHistory)
+}
+
+// UpdateStatus changes the account status of a user
+// Leaving the active status (e.g. a ban) immediately invalidates the user's access tokens
+func (s *UserService) UpdateStatus(ctx context.Context, id string, status constant.UserStatusEnum) error {
+	err := s.userRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"status": status,
+	})
+	if err != nil {
+		return err
+	}
+
+	if status != constant.UserStatusActive {
+		return s.RevokeAccessTokens(ctx, id)
+	}
+	return nil
+}
+
+// RecordSignIn stores the time of the user's latest successful sign-in
+func (s *UserService) RecordSignIn(ctx context.Context, id string) error {
+	return s.userRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"last_sign_in_at": time.Now(),
+	})
+}
+
+// GetTokenVersion returns the current access token version of a user
+// The version is cached so the per-request check in the auth middleware stays cheap
+func (s *UserService) GetTokenVersion(ctx context.Context, id string) (int, error) {
+	key := tokenVersionCacheKey(id)
+
+	if cached, ok, err := s.cache.Get(ctx, key); err == nil && ok {
+		if version, err := strconv.Atoi(cached); err == nil {
+			return version, nil
+		}
+	}
+
+	existingUser, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if existingUser == nil {
+		return 0, exceptions.NotFoundError("User not found", nil, nil)
+	}
+
+	// A cache failure only costs a database lookup on the next request
+	_ = s.cache.Set(ctx, key, strconv.Itoa(existingUser.TokenVersion), s.options.TokenVersionCacheTTL)
+
+	return existingUser.TokenVersion, nil
+}
+
+// RevokeAccessTokens invalidates every access token issued to a user so far
+func (s *UserService) RevokeAccessTokens(ctx context.Context, id string) error {
+	if err := s.userRepo.IncrementTokenVersion(ctx, id); err != nil {
+		return err
+	}
+
+	existingUser, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The cache only learns the new version once the transaction commits, so a rollback
+	// cannot leave it ahead of the database. Setting it (rather than evicting it) also
+	// replaces any old version a concurrent request cached before the commit
+	utils.AfterCommit(ctx, func() {
+		key := tokenVersionCacheKey(id)
+		if existingUser != nil {
+			if err := s.cache.Set(ctx, key, strconv.Itoa(existingUser.TokenVersion), s.options.TokenVersionCacheTTL); err == nil {
+				return
+			}
+		}
+		if err := s.cache.Delete(ctx, key); err != nil {
+			logger.LogError(err, "Failed to update the cached token version", map[string]interface{}{"user_id": id})
+		}
+	})
+	return nil
+}
+
+// tokenVersionCacheKey returns the cache key holding a user's access token version
+func tokenVersionCacheKey(id string) string {
+	return "user:token_version:" + id
 }
 
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
 	return s.userRepo.FindByEmail(ctx, email)
 }
+
+// IsEmailTaken reports whether the email is already used by an account
+func (s *UserService) IsEmailTaken(ctx context.Context, email string) (bool, error) {
+	return s.userRepo.ExistsByEmail(ctx, email)
+}
+
+// GetUserBySocialProvider retrieves the user linked to a social login account; nil when none is linked
+func (s *UserService) GetUserBySocialProvider(ctx context.Context, provider string, providerID string) (*user.User, error) {
+	return s.userRepo.FindBySocialProvider(ctx, provider, providerID)
+}
+
+// optionalString returns nil for empty strings
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -3,14 +3,29 @@ package service
 import (
 	"context"
 	"gin/internal/domain/user"
+	"gin/internal/shared/constant"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/queryspec"
 )
 
 type UserServiceInterface interface {
 	GetAllUsers(ctx context.Context) ([]*user.User, error)
-	GetAllUsersPaginated(ctx context.Context, page, perPage int) ([]*user.User, int64, error)
+	GetAllUsersPaginated(ctx context.Context, spec queryspec.Spec, params pagination.Params) (pagination.Page[*user.User], error)
+	GetAllUsersByCursor(ctx context.Context, spec queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*user.User], error)
 	GetUserByID(ctx context.Context, id string) (*user.User, error)
 	CreateUser(ctx context.Context, req user.SignupInput) (*user.User, error)
-	UpdateUser(ctx context.Context, updates map[string]interface{}, password *string, id string) (*user.User, error)
+	UpdateUser(ctx context.Context, updates map[string]interface{}, id string) (*user.User, error)
 	DeleteUser(ctx context.Context, id string) error
 	GetUserByEmail(ctx context.Context, email string) (*user.User, error)
+	IsEmailTaken(ctx context.Context, email string) (bool, error)
+	GetUserBySocialProvider(ctx context.Context, provider string, providerID string) (*user.User, error)
+	CreateSocialUser(ctx context.Context, req user.SocialSignupInput) (*user.User, error)
+	LinkSocialProvider(ctx context.Context, id string, provider string, providerID string) error
+	CreateServiceAccount(ctx context.Context, name string) (*user.User, error)
+	SetPassword(ctx context.Context, id string, password string) error
+	ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error
+	UpdateStatus(ctx context.Context, id string, status constant.UserStatusEnum) error
+	RecordSignIn(ctx context.Context, id string) error
+	GetTokenVersion(ctx context.Context, id string) (int, error)
+	RevokeAccessTokens(ctx context.Context, id string) error
 }
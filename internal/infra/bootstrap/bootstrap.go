@@ -19,10 +19,21 @@ var Module = fx.Options(
 	// Core infrastructure modules (must be first)
 	modules.ConfigModule,
 	modules.UtilsModule,
+	modules.CacheModule,
+	modules.MailModule,
 
 	// Domain modules
+	modules.PermissionModule,
+	modules.SecurityEventModule,
 	modules.UserModule,
 	modules.RefreshTokenModule,
+	modules.EmailVerificationModule,
+	modules.PasswordResetModule,
+	modules.EmailChangeModule,
+	modules.MagicLinkModule,
+	modules.TwoFactorModule,
+	modules.APIKeyModule,
+	modules.ImpersonationModule,
 	modules.AuthModule,
 	modules.HealthModule,
 
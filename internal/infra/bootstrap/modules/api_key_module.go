@@ -0,0 +1,25 @@
+package modules
+
+import (
+	"gin/internal/domain/api_key/handler"
+	apiKeyRepository "gin/internal/domain/api_key/repository"
+	apiKeyService "gin/internal/domain/api_key/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// APIKeyModule provides API key dependencies (repository, service, handler)
+var APIKeyModule = fx.Options(
+	fx.Provide(apiKeyRepository.NewAPIKeyRepository),
+	fx.Provide(newAPIKeyOptions),
+	fx.Provide(apiKeyService.NewAPIKeyService),
+	fx.Provide(handler.NewAPIKeyHandler),
+)
+
+// newAPIKeyOptions maps configuration onto the API key service options
+func newAPIKeyOptions(cfg *config.Config) apiKeyService.Options {
+	return apiKeyService.Options{
+		Prefix: cfg.APIKeys().Prefix,
+	}
+}
@@ -2,12 +2,57 @@ package modules
 
 import (
 	"gin/internal/domain/auth/handler"
+	authService "gin/internal/domain/auth/service"
+	"gin/internal/infra/config"
+	"gin/internal/infra/socialauth"
+	"gin/internal/shared/utils"
 
 	"go.uber.org/fx"
 )
 
-// AuthModule provides authentication-related dependencies (handler)
+// AuthModule provides authentication-related dependencies (access token service, login throttling, social login, handler)
 // Note: AuthHandler depends on UserService and RefreshTokenService which are provided in other modules
 var AuthModule = fx.Options(
+	fx.Provide(authService.NewAccessTokenService),
+	fx.Provide(authService.NewLockoutNotifier),
+	fx.Provide(newLoginThrottleOptions),
+	fx.Provide(authService.NewLoginThrottleService),
+	fx.Provide(socialauth.NewProviders),
+	fx.Provide(newSocialLoginOptions),
+	fx.Provide(authService.NewSocialLoginService),
+	fx.Provide(newAuthCookieOptions),
 	fx.Provide(handler.NewAuthHandler),
 )
+
+// newSocialLoginOptions maps configuration onto the social login service options
+func newSocialLoginOptions(cfg *config.Config) authService.SocialLoginOptions {
+	oauthConfig := cfg.OAuth()
+	return authService.SocialLoginOptions{
+		CallbackURL: oauthConfig.CallbackURL,
+		StateTTL:    oauthConfig.StateTTL,
+	}
+}
+
+// newLoginThrottleOptions maps configuration onto the login throttle options
+func newLoginThrottleOptions(cfg *config.Config) authService.LoginThrottleOptions {
+	throttleConfig := cfg.LoginThrottle()
+	return authService.LoginThrottleOptions{
+		MaxAttempts:     throttleConfig.MaxAttempts,
+		IPMaxAttempts:   throttleConfig.IPMaxAttempts,
+		Window:          throttleConfig.Window,
+		LockoutDuration: throttleConfig.LockoutDuration,
+		BackoffBase:     throttleConfig.BackoffBase,
+		BackoffMax:      throttleConfig.BackoffMax,
+	}
+}
+
+// newAuthCookieOptions maps configuration onto the cookie auth mode options
+func newAuthCookieOptions(cfg *config.Config) utils.AuthCookieOptions {
+	cookieConfig := cfg.AuthCookies()
+	return utils.AuthCookieOptions{
+		Enabled:  cookieConfig.Enabled,
+		Domain:   cookieConfig.Domain,
+		Secure:   cookieConfig.Secure,
+		SameSite: cookieConfig.SameSite,
+	}
+}
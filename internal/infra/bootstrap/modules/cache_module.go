@@ -0,0 +1,12 @@
+package modules
+
+import (
+	"gin/internal/infra/cache"
+
+	"go.uber.org/fx"
+)
+
+// CacheModule provides the key/value cache used for hot-path lookups
+var CacheModule = fx.Options(
+	fx.Provide(cache.NewCache),
+)
@@ -0,0 +1,27 @@
+package modules
+
+import (
+	"gin/internal/domain/email_change/handler"
+	emailChangeRepository "gin/internal/domain/email_change/repository"
+	emailChangeService "gin/internal/domain/email_change/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// EmailChangeModule provides email change dependencies (repository, service, handler)
+var EmailChangeModule = fx.Options(
+	fx.Provide(emailChangeRepository.NewEmailChangeRepository),
+	fx.Provide(newEmailChangeOptions),
+	fx.Provide(emailChangeService.NewEmailChangeService),
+	fx.Provide(handler.NewEmailChangeHandler),
+)
+
+// newEmailChangeOptions maps configuration onto the email change service options
+func newEmailChangeOptions(cfg *config.Config) emailChangeService.Options {
+	changeConfig := cfg.EmailChange()
+	return emailChangeService.Options{
+		Expiry: changeConfig.Expiry,
+		URL:    changeConfig.URL,
+	}
+}
@@ -0,0 +1,26 @@
+package modules
+
+import (
+	emailVerificationRepository "gin/internal/domain/email_verification/repository"
+	emailVerificationService "gin/internal/domain/email_verification/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// EmailVerificationModule provides email verification dependencies (repository, service)
+var EmailVerificationModule = fx.Options(
+	fx.Provide(emailVerificationRepository.NewEmailVerificationRepository),
+	fx.Provide(newEmailVerificationOptions),
+	fx.Provide(emailVerificationService.NewEmailVerificationService),
+)
+
+// newEmailVerificationOptions maps configuration onto the email verification service options
+func newEmailVerificationOptions(cfg *config.Config) emailVerificationService.Options {
+	verificationConfig := cfg.EmailVerification()
+	return emailVerificationService.Options{
+		Expiry:         verificationConfig.Expiry,
+		ResendCooldown: verificationConfig.ResendCooldown,
+		URL:            verificationConfig.URL,
+	}
+}
@@ -0,0 +1,25 @@
+package modules
+
+import (
+	"gin/internal/domain/impersonation/handler"
+	impersonationRepository "gin/internal/domain/impersonation/repository"
+	impersonationService "gin/internal/domain/impersonation/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// ImpersonationModule provides admin impersonation dependencies (repository, service, handler)
+var ImpersonationModule = fx.Options(
+	fx.Provide(impersonationRepository.NewImpersonationRepository),
+	fx.Provide(newImpersonationOptions),
+	fx.Provide(impersonationService.NewImpersonationService),
+	fx.Provide(handler.NewImpersonationHandler),
+)
+
+// newImpersonationOptions maps configuration onto the impersonation service options
+func newImpersonationOptions(cfg *config.Config) impersonationService.Options {
+	return impersonationService.Options{
+		TokenExpiry: cfg.Impersonation().TokenExpiry,
+	}
+}
@@ -0,0 +1,29 @@
+package modules
+
+import (
+	magicLinkRepository "gin/internal/domain/magic_link/repository"
+	magicLinkService "gin/internal/domain/magic_link/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// MagicLinkModule provides passwordless login dependencies (repository, service)
+var MagicLinkModule = fx.Options(
+	fx.Provide(magicLinkRepository.NewMagicLinkRepository),
+	fx.Provide(newMagicLinkOptions),
+	fx.Provide(magicLinkService.NewMagicLinkService),
+)
+
+// newMagicLinkOptions maps configuration onto the magic link service options
+func newMagicLinkOptions(cfg *config.Config) magicLinkService.Options {
+	linkConfig := cfg.MagicLink()
+	return magicLinkService.Options{
+		Expiry:        linkConfig.Expiry,
+		Cooldown:      linkConfig.Cooldown,
+		MaxRequests:   linkConfig.MaxRequests,
+		IPMaxRequests: linkConfig.IPMaxRequests,
+		Window:        linkConfig.Window,
+		URL:           linkConfig.URL,
+	}
+}
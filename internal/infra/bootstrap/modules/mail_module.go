@@ -0,0 +1,12 @@
+package modules
+
+import (
+	"gin/internal/infra/mailer"
+
+	"go.uber.org/fx"
+)
+
+// MailModule provides the transactional mailer
+var MailModule = fx.Options(
+	fx.Provide(mailer.NewMailer),
+)
@@ -0,0 +1,26 @@
+package modules
+
+import (
+	passwordResetRepository "gin/internal/domain/password_reset/repository"
+	passwordResetService "gin/internal/domain/password_reset/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// PasswordResetModule provides password reset dependencies (repository, service)
+var PasswordResetModule = fx.Options(
+	fx.Provide(passwordResetRepository.NewPasswordResetRepository),
+	fx.Provide(newPasswordResetOptions),
+	fx.Provide(passwordResetService.NewPasswordResetService),
+)
+
+// newPasswordResetOptions maps configuration onto the password reset service options
+func newPasswordResetOptions(cfg *config.Config) passwordResetService.Options {
+	resetConfig := cfg.PasswordReset()
+	return passwordResetService.Options{
+		Expiry:   resetConfig.Expiry,
+		Cooldown: resetConfig.Cooldown,
+		URL:      resetConfig.URL,
+	}
+}
@@ -0,0 +1,36 @@
+package modules
+
+import (
+	"gin/internal/domain/permission/handler"
+	permissionRepository "gin/internal/domain/permission/repository"
+	permissionService "gin/internal/domain/permission/service"
+	"gin/internal/infra/config"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
+
+	"go.uber.org/fx"
+)
+
+// PermissionModule provides the policy engine (repository, service, handler)
+// The service is also provided as authz.Authorizer for domains that only check permissions
+var PermissionModule = fx.Options(
+	fx.Provide(permissionRepository.NewPermissionRepository),
+	fx.Provide(newPermissionOptions),
+	fx.Provide(permissionService.NewPermissionService),
+	fx.Provide(func(s permissionService.PermissionServiceInterface) authz.Authorizer { return s }),
+	fx.Provide(handler.NewPermissionHandler),
+)
+
+// newPermissionOptions maps configuration onto the permission service options
+// Resource rules live here rather than in the database because they depend on the resource, not the role
+func newPermissionOptions(cfg *config.Config) permissionService.Options {
+	return permissionService.Options{
+		CacheTTL: cfg.Authorization().PermissionCacheTTL,
+		Rules: map[string][]authz.Rule{
+			// Users can always manage and read their own account
+			constant.PermissionUsersUpdate:    {authz.IsOwner},
+			constant.PermissionUsersDelete:    {authz.IsOwner},
+			constant.PermissionUsersReadEmail: {authz.IsOwner},
+		},
+	}
+}
@@ -3,6 +3,7 @@ package modules
 import (
 	refreshTokenRepository "gin/internal/domain/refresh_token/repository"
 	refreshTokenService "gin/internal/domain/refresh_token/service"
+	"gin/internal/infra/config"
 
 	"go.uber.org/fx"
 )
@@ -10,5 +11,15 @@ import (
 // RefreshTokenModule provides refresh token-related dependencies (repository, service)
 var RefreshTokenModule = fx.Options(
 	fx.Provide(refreshTokenRepository.NewRefreshTokenRepository),
+	fx.Provide(newRefreshTokenOptions),
 	fx.Provide(refreshTokenService.NewRefreshTokenService),
 )
+
+// newRefreshTokenOptions maps configuration onto the refresh token service options
+func newRefreshTokenOptions(cfg *config.Config) refreshTokenService.Options {
+	return refreshTokenService.Options{
+		MaxSessions:      cfg.Session().MaxSessions,
+		AccessTokenTTL:   cfg.JWT().AccessExpiry,
+		ReuseGracePeriod: cfg.Session().RefreshReuseGrace,
+	}
+}
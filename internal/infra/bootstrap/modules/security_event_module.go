@@ -0,0 +1,19 @@
+package modules
+
+import (
+	"gin/internal/domain/security_event/handler"
+	securityEventRepository "gin/internal/domain/security_event/repository"
+	securityEventService "gin/internal/domain/security_event/service"
+	"gin/internal/shared/audit"
+
+	"go.uber.org/fx"
+)
+
+// SecurityEventModule provides the security activity log (repository, service, handler)
+// The service is also provided as audit.Recorder for domains that only record events
+var SecurityEventModule = fx.Options(
+	fx.Provide(securityEventRepository.NewSecurityEventRepository),
+	fx.Provide(securityEventService.NewSecurityEventService),
+	fx.Provide(func(s securityEventService.SecurityEventServiceInterface) audit.Recorder { return s }),
+	fx.Provide(handler.NewSecurityEventHandler),
+)
@@ -0,0 +1,28 @@
+package modules
+
+import (
+	twoFactorRepository "gin/internal/domain/two_factor/repository"
+	twoFactorService "gin/internal/domain/two_factor/service"
+	"gin/internal/infra/config"
+
+	"go.uber.org/fx"
+)
+
+// TwoFactorModule provides two-factor authentication dependencies (repository, service)
+var TwoFactorModule = fx.Options(
+	fx.Provide(twoFactorRepository.NewTwoFactorRepository),
+	fx.Provide(newTwoFactorOptions),
+	fx.Provide(twoFactorService.NewTwoFactorService),
+)
+
+// newTwoFactorOptions maps configuration onto the two-factor service options
+func newTwoFactorOptions(cfg *config.Config) twoFactorService.Options {
+	twoFactorConfig := cfg.TwoFactor()
+	return twoFactorService.Options{
+		Issuer:            twoFactorConfig.Issuer,
+		ChallengeExpiry:   twoFactorConfig.ChallengeExpiry,
+		RecoveryCodeCount: twoFactorConfig.RecoveryCodeCount,
+		MaxFailures:       twoFactorConfig.MaxFailures,
+		LockoutDuration:   twoFactorConfig.LockoutDuration,
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"gin/internal/domain/user/handler"
 	userRepository "gin/internal/domain/user/repository"
 	userService "gin/internal/domain/user/service"
+	"gin/internal/infra/config"
+	"gin/internal/shared/password"
 
 	"go.uber.org/fx"
 )
@@ -11,6 +13,35 @@ import (
 // UserModule provides user-related dependencies (repository, service, handler)
 var UserModule = fx.Options(
 	fx.Provide(userRepository.NewUserRepository),
+	fx.Provide(newUserOptions),
+	fx.Provide(newPasswordPolicy),
 	fx.Provide(userService.NewUserService),
 	fx.Provide(handler.NewUserHandler),
 )
+
+// newUserOptions maps configuration onto the user service options
+func newUserOptions(cfg *config.Config) userService.Options {
+	return userService.Options{
+		TokenVersionCacheTTL: cfg.Session().TokenVersionCacheTTL,
+		PasswordHistory:      cfg.PasswordPolicy().History,
+	}
+}
+
+// newPasswordPolicy builds the password policy enforced whenever a password is set
+func newPasswordPolicy(cfg *config.Config) *password.Policy {
+	policyConfig := cfg.PasswordPolicy()
+
+	var breached *password.BreachedList
+	if policyConfig.CheckBreached {
+		breached = password.NewBreachedList(policyConfig.BreachedHashDir)
+	}
+
+	return password.NewPolicy(password.Options{
+		MinLength:          policyConfig.MinLength,
+		MaxLength:          policyConfig.MaxLength,
+		RequireMixedCase:   policyConfig.RequireMixedCase,
+		RequireNumbers:     policyConfig.RequireNumbers,
+		RequireSymbols:     policyConfig.RequireSymbols,
+		RejectPersonalInfo: policyConfig.RejectPersonalInfo,
+	}, breached)
+}
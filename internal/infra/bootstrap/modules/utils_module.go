@@ -1,26 +1,116 @@
 package modules
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"gin/internal/infra/config"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/cache"
 	"gin/internal/shared/utils"
 	validators "gin/internal/shared/validator"
 
 	"go.uber.org/fx"
 )
 
-// UtilsModule provides utility dependencies (JWT manager, validator)
+// keyRotationCheckInterval is how often the key ring checks for due rotations
+// and picks up keys rotated by other instances sharing the key directory
+const keyRotationCheckInterval = time.Minute
+
+// keyRotationLockKey is the cache counter that lets a single instance rotate per check interval
+const keyRotationLockKey = "jwt:key_rotation:lock"
+
+// UtilsModule provides utility dependencies (JWT manager, encrypter, validator)
 var UtilsModule = fx.Options(
 	fx.Provide(newJWTManager),
+	fx.Provide(newEncrypter),
 	fx.Provide(validators.NewValidator),
 	fx.Provide(utils.GeneratePassword),
+	fx.Invoke(registerKeyRotation),
 )
 
 // newJWTManager creates a JWT manager with configuration
-func newJWTManager(cfg *config.Config) *utils.JWTManager {
+// HS256 signs with the shared secret; RS256 and EdDSA sign with a rotating key ring
+func newJWTManager(cfg *config.Config) (*utils.JWTManager, error) {
 	jwtConfig := cfg.JWT()
-	return utils.NewJWTManagerFromConfig(
-		jwtConfig.SecretKey,
-		jwtConfig.AccessExpiry,
-		jwtConfig.RefreshExpiry,
-	)
+
+	switch jwtConfig.Algorithm {
+	case utils.AlgorithmHS256:
+		return utils.NewJWTManagerFromConfig(
+			jwtConfig.SecretKey,
+			jwtConfig.AccessExpiry,
+			jwtConfig.RefreshExpiry,
+		), nil
+	case utils.AlgorithmRS256, utils.AlgorithmEdDSA:
+		keyRing, err := utils.NewKeyRing(utils.KeyRingOptions{
+			Algorithm:        jwtConfig.Algorithm,
+			Dir:              jwtConfig.KeysDir,
+			RotationInterval: jwtConfig.KeyRotationInterval,
+			Retention:        jwtConfig.KeyRetention,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT signing keys: %w", err)
+		}
+		return utils.NewJWTManagerWithKeyRing(keyRing, jwtConfig.AccessExpiry, jwtConfig.RefreshExpiry), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", jwtConfig.Algorithm)
+	}
+}
+
+// newEncrypter creates the encrypter for secrets stored at rest
+func newEncrypter(cfg *config.Config) (*utils.Encrypter, error) {
+	key, err := cfg.EncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewEncrypter(key)
+}
+
+// registerKeyRotation runs scheduled signing key rotation while the application is up
+// Every instance runs its own ticker. With a shared cache only the first instance to tick in an
+// interval may rotate, so instances sharing the key directory do not each mint a new key; the
+// others just pick the new key up. The memory cache is per process and does not coordinate
+func registerKeyRotation(lifecycle fx.Lifecycle, jwtManager *utils.JWTManager, store cache.Cache) {
+	keyRing := jwtManager.KeyRing()
+	if keyRing == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				ticker := time.NewTicker(keyRotationCheckInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-stop:
+						return
+					case now := <-ticker.C:
+						holders, err := store.Increment(context.Background(), keyRotationLockKey, keyRotationCheckInterval)
+						if err != nil || holders > 1 {
+							if err := keyRing.Refresh(now); err != nil {
+								logger.LogError(err, "JWT key refresh failed", nil)
+							}
+							continue
+						}
+
+						rotated, err := keyRing.RotateIfDue(now)
+						if err != nil {
+							logger.LogError(err, "JWT key rotation failed", nil)
+						} else if rotated {
+							logger.LogInfo("JWT signing key rotated", map[string]interface{}{"kid": keyRing.Current().ID})
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(stop)
+			return nil
+		},
+	})
 }
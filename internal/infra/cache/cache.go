@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"log"
+	"strings"
+
+	"gin/internal/infra/config"
+	"gin/internal/shared/cache"
+)
+
+// NewCache creates the cache selected by CACHE_DRIVER
+// Only the in-process "memory" driver is bundled; a shared driver (e.g. Redis)
+// is required for immediate invalidation across several API instances
+func NewCache(cfg *config.Config) cache.Cache {
+	driver := strings.ToLower(cfg.Cache().Driver)
+
+	switch driver {
+	case "memory":
+		return NewMemoryCache()
+	default:
+		log.Printf("Unknown cache driver %q, falling back to memory", driver)
+		return NewMemoryCache()
+	}
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -26,11 +30,108 @@ type Config struct {
 	// CORS config
 	CORSAllowedOrigins string `mapstructure:"CORS_ALLOWED_ORIGINS"`
 
+	// Frontend config (used to build links sent by email)
+	AppFrontendURL string `mapstructure:"APP_FRONTEND_URL"`
+
+	// Mail config
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	ResendAPIKey string `mapstructure:"RESEND_API_KEY"`
+
 	// JWT config
 	JWTSecretKey     string        `mapstructure:"JWT_SECRET_KEY"`
 	JWTAccessExpiry  time.Duration `mapstructure:"JWT_ACCESS_EXPIRY"`
 	JWTRefreshExpiry time.Duration `mapstructure:"JWT_REFRESH_EXPIRY"`
 
+	// JWT signing key config (RS256/EdDSA)
+	JWTAlgorithm           string        `mapstructure:"JWT_ALGORITHM"`
+	JWTKeysDir             string        `mapstructure:"JWT_KEYS_DIR"`
+	JWTKeyRotationInterval time.Duration `mapstructure:"JWT_KEY_ROTATION_INTERVAL"`
+	JWTKeyRetention        time.Duration `mapstructure:"JWT_KEY_RETENTION"`
+
+	// Encryption config (base64-encoded 32-byte key for secrets stored at rest)
+	AppEncryptionKey string `mapstructure:"APP_ENCRYPTION_KEY"`
+
+	// Two-factor authentication config
+	TwoFactorIssuer          string        `mapstructure:"TWO_FACTOR_ISSUER"`
+	TwoFactorChallengeExpiry time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_EXPIRY"`
+	TwoFactorRecoveryCodes   int           `mapstructure:"TWO_FACTOR_RECOVERY_CODES"`
+	TwoFactorMaxFailures     int           `mapstructure:"TWO_FACTOR_MAX_FAILURES"`
+	TwoFactorLockout         time.Duration `mapstructure:"TWO_FACTOR_LOCKOUT"`
+
+	// Social login config
+	OAuthCallbackURL   string        `mapstructure:"OAUTH_CALLBACK_URL"`
+	OAuthStateTTL      time.Duration `mapstructure:"OAUTH_STATE_TTL"`
+	GoogleClientID     string        `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret string        `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	GitHubClientID     string        `mapstructure:"GITHUB_CLIENT_ID"`
+	GitHubClientSecret string        `mapstructure:"GITHUB_CLIENT_SECRET"`
+	OIDCProviderName   string        `mapstructure:"OIDC_PROVIDER_NAME"`
+	OIDCIssuerURL      string        `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID       string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret   string        `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCScopes         string        `mapstructure:"OIDC_SCOPES"`
+
+	// Session config
+	AuthMaxSessions          int           `mapstructure:"AUTH_MAX_SESSIONS"`
+	AuthTokenVersionCacheTTL time.Duration `mapstructure:"AUTH_TOKEN_VERSION_CACHE_TTL"`
+	AuthRefreshReuseGrace    time.Duration `mapstructure:"AUTH_REFRESH_REUSE_GRACE"`
+
+	// Authorization config
+	AuthPermissionCacheTTL time.Duration `mapstructure:"AUTH_PERMISSION_CACHE_TTL"`
+
+	// API key config
+	APIKeyPrefix string `mapstructure:"API_KEY_PREFIX"`
+
+	// Login throttling config
+	AuthLoginMaxAttempts     int           `mapstructure:"AUTH_LOGIN_MAX_ATTEMPTS"`
+	AuthLoginIPMaxAttempts   int           `mapstructure:"AUTH_LOGIN_IP_MAX_ATTEMPTS"`
+	AuthLoginAttemptWindow   time.Duration `mapstructure:"AUTH_LOGIN_ATTEMPT_WINDOW"`
+	AuthLoginLockoutDuration time.Duration `mapstructure:"AUTH_LOGIN_LOCKOUT_DURATION"`
+	AuthLoginBackoffBase     time.Duration `mapstructure:"AUTH_LOGIN_BACKOFF_BASE"`
+	AuthLoginBackoffMax      time.Duration `mapstructure:"AUTH_LOGIN_BACKOFF_MAX"`
+
+	// Cookie auth config
+	AuthCookieMode     bool   `mapstructure:"AUTH_COOKIE_MODE"`
+	AuthCookieDomain   string `mapstructure:"AUTH_COOKIE_DOMAIN"`
+	AuthCookieSecure   bool   `mapstructure:"AUTH_COOKIE_SECURE"`
+	AuthCookieSameSite string `mapstructure:"AUTH_COOKIE_SAME_SITE"`
+
+	// Cache config
+	CacheDriver string `mapstructure:"CACHE_DRIVER"`
+
+	// Email verification config
+	EmailVerificationExpiry         time.Duration `mapstructure:"EMAIL_VERIFICATION_EXPIRY"`
+	EmailVerificationResendCooldown time.Duration `mapstructure:"EMAIL_VERIFICATION_RESEND_COOLDOWN"`
+
+	// Password reset config
+	PasswordResetExpiry   time.Duration `mapstructure:"PASSWORD_RESET_EXPIRY"`
+	PasswordResetCooldown time.Duration `mapstructure:"PASSWORD_RESET_COOLDOWN"`
+
+	// Email change config
+	EmailChangeExpiry time.Duration `mapstructure:"EMAIL_CHANGE_EXPIRY"`
+
+	// Magic link config
+	MagicLinkExpiry        time.Duration `mapstructure:"MAGIC_LINK_EXPIRY"`
+	MagicLinkCooldown      time.Duration `mapstructure:"MAGIC_LINK_COOLDOWN"`
+	MagicLinkMaxRequests   int           `mapstructure:"MAGIC_LINK_MAX_REQUESTS"`
+	MagicLinkIPMaxRequests int           `mapstructure:"MAGIC_LINK_IP_MAX_REQUESTS"`
+	MagicLinkWindow        time.Duration `mapstructure:"MAGIC_LINK_WINDOW"`
+
+	// Password policy config
+	PasswordMinLength          int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength          int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireMixedCase   bool   `mapstructure:"PASSWORD_REQUIRE_MIXED_CASE"`
+	PasswordRequireNumbers     bool   `mapstructure:"PASSWORD_REQUIRE_NUMBERS"`
+	PasswordRequireSymbols     bool   `mapstructure:"PASSWORD_REQUIRE_SYMBOLS"`
+	PasswordRejectPersonalInfo bool   `mapstructure:"PASSWORD_REJECT_PERSONAL_INFO"`
+	PasswordHistory            int    `mapstructure:"PASSWORD_HISTORY"`
+	PasswordCheckBreached      bool   `mapstructure:"PASSWORD_CHECK_BREACHED"`
+	PasswordBreachedHashDir    string `mapstructure:"PASSWORD_BREACHED_HASH_DIR"`
+
+	// Impersonation config
+	ImpersonationTokenExpiry time.Duration `mapstructure:"AUTH_IMPERSONATION_TOKEN_EXPIRY"`
+
 	// Swagger basic auth config
 	SwaggerBasicAuthUsername string `mapstructure:"SWAGGER_BASIC_AUTH_USERNAME"`
 	SwaggerBasicAuthPassword string `mapstructure:"SWAGGER_BASIC_AUTH_PASSWORD"`
@@ -79,10 +180,296 @@ func (c *Config) CORS() CORSConfig {
 
 // JWT returns the JWT configuration
 func (c *Config) JWT() JWTConfig {
+	algorithm := strings.TrimSpace(c.JWTAlgorithm)
+	if algorithm == "" {
+		algorithm = "HS256"
+	}
+
+	// Retired keys must outlive every token they signed
+	retention := c.JWTKeyRetention
+	if retention <= 0 {
+		retention = c.JWTRefreshExpiry
+		if c.JWTAccessExpiry > retention {
+			retention = c.JWTAccessExpiry
+		}
+	}
+
 	return JWTConfig{
-		SecretKey:     c.JWTSecretKey,
-		AccessExpiry:  c.JWTAccessExpiry,
-		RefreshExpiry: c.JWTRefreshExpiry,
+		SecretKey:           c.JWTSecretKey,
+		AccessExpiry:        c.JWTAccessExpiry,
+		RefreshExpiry:       c.JWTRefreshExpiry,
+		Algorithm:           algorithm,
+		KeysDir:             c.JWTKeysDir,
+		KeyRotationInterval: c.JWTKeyRotationInterval,
+		KeyRetention:        retention,
+	}
+}
+
+// Session returns the session configuration
+func (c *Config) Session() SessionConfig {
+	maxSessions := c.AuthMaxSessions
+	if maxSessions < 0 {
+		maxSessions = 0
+	}
+
+	return SessionConfig{
+		MaxSessions:          maxSessions,
+		TokenVersionCacheTTL: c.AuthTokenVersionCacheTTL,
+		RefreshReuseGrace:    c.AuthRefreshReuseGrace,
+	}
+}
+
+// Authorization returns the policy engine configuration
+func (c *Config) Authorization() AuthorizationConfig {
+	return AuthorizationConfig{
+		PermissionCacheTTL: c.AuthPermissionCacheTTL,
+	}
+}
+
+// APIKeys returns the API key configuration
+func (c *Config) APIKeys() APIKeyConfig {
+	prefix := strings.Trim(strings.TrimSpace(c.APIKeyPrefix), "_")
+	if prefix == "" {
+		prefix = "gsk"
+	}
+
+	return APIKeyConfig{
+		Prefix: prefix,
+	}
+}
+
+// LoginThrottle returns the brute-force protection configuration
+func (c *Config) LoginThrottle() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxAttempts:     c.AuthLoginMaxAttempts,
+		IPMaxAttempts:   c.AuthLoginIPMaxAttempts,
+		Window:          c.AuthLoginAttemptWindow,
+		LockoutDuration: c.AuthLoginLockoutDuration,
+		BackoffBase:     c.AuthLoginBackoffBase,
+		BackoffMax:      c.AuthLoginBackoffMax,
+	}
+}
+
+// AuthCookies returns the cookie auth mode configuration for browser clients
+func (c *Config) AuthCookies() AuthCookieConfig {
+	secure := c.AuthCookieSecure
+
+	var sameSite http.SameSite
+	switch strings.ToLower(strings.TrimSpace(c.AuthCookieSameSite)) {
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "none":
+		// Browsers drop SameSite=None cookies that are not Secure
+		sameSite = http.SameSiteNoneMode
+		secure = true
+	default:
+		sameSite = http.SameSiteStrictMode
+	}
+
+	return AuthCookieConfig{
+		Enabled:  c.AuthCookieMode,
+		Domain:   strings.TrimSpace(c.AuthCookieDomain),
+		Secure:   secure,
+		SameSite: sameSite,
+	}
+}
+
+// EncryptionKey returns the 32-byte key used to encrypt secrets at rest
+// Without APP_ENCRYPTION_KEY the key is derived from the JWT secret, so changing that secret
+// makes previously encrypted values unreadable
+func (c *Config) EncryptionKey() ([]byte, error) {
+	encoded := strings.TrimSpace(c.AppEncryptionKey)
+	if encoded == "" {
+		sum := sha256.Sum256([]byte(c.JWTSecretKey))
+		return sum[:], nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("APP_ENCRYPTION_KEY must be base64 encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("APP_ENCRYPTION_KEY must decode to 32 bytes, got %d", len(key))
+	}
+
+	return key, nil
+}
+
+// TwoFactor returns the two-factor authentication configuration
+func (c *Config) TwoFactor() TwoFactorConfig {
+	issuer := strings.TrimSpace(c.TwoFactorIssuer)
+	if issuer == "" {
+		issuer = "Gin Skeleton"
+	}
+
+	recoveryCodes := c.TwoFactorRecoveryCodes
+	if recoveryCodes <= 0 {
+		recoveryCodes = 10
+	}
+
+	lockout := c.TwoFactorLockout
+	if lockout <= 0 {
+		lockout = 15 * time.Minute
+	}
+
+	return TwoFactorConfig{
+		Issuer:            issuer,
+		ChallengeExpiry:   c.TwoFactorChallengeExpiry,
+		RecoveryCodeCount: recoveryCodes,
+		MaxFailures:       c.TwoFactorMaxFailures,
+		LockoutDuration:   lockout,
+	}
+}
+
+// OAuth returns the social login configuration
+// A provider is enabled when its client ID is set
+func (c *Config) OAuth() OAuthConfig {
+	callbackURL := strings.TrimSpace(c.OAuthCallbackURL)
+	if callbackURL == "" {
+		callbackURL = "http://localhost:8000/api/auth/oauth/{provider}/callback"
+	}
+
+	providerName := strings.TrimSpace(c.OIDCProviderName)
+	if providerName == "" {
+		providerName = "oidc"
+	}
+
+	return OAuthConfig{
+		CallbackURL: callbackURL,
+		StateTTL:    c.OAuthStateTTL,
+		Google: OAuthClientConfig{
+			ClientID:     strings.TrimSpace(c.GoogleClientID),
+			ClientSecret: c.GoogleClientSecret,
+		},
+		GitHub: OAuthClientConfig{
+			ClientID:     strings.TrimSpace(c.GitHubClientID),
+			ClientSecret: c.GitHubClientSecret,
+		},
+		OIDC: OIDCProviderConfig{
+			Name:      providerName,
+			IssuerURL: strings.TrimSpace(c.OIDCIssuerURL),
+			OAuthClientConfig: OAuthClientConfig{
+				ClientID:     strings.TrimSpace(c.OIDCClientID),
+				ClientSecret: c.OIDCClientSecret,
+			},
+			Scopes: strings.Fields(strings.ReplaceAll(c.OIDCScopes, ",", " ")),
+		},
+	}
+}
+
+// Cache returns the cache configuration
+func (c *Config) Cache() CacheConfig {
+	driver := strings.TrimSpace(c.CacheDriver)
+	if driver == "" {
+		driver = "memory"
+	}
+
+	return CacheConfig{
+		Driver: driver,
+	}
+}
+
+// Mail returns the mail configuration
+func (c *Config) Mail() MailConfig {
+	driver := strings.TrimSpace(c.MailDriver)
+	if driver == "" {
+		driver = "log"
+	}
+
+	return MailConfig{
+		Driver:       driver,
+		From:         c.MailFrom,
+		ResendAPIKey: c.ResendAPIKey,
+	}
+}
+
+// FrontendURL returns the frontend base URL without a trailing slash
+func (c *Config) FrontendURL() string {
+	url := strings.TrimRight(strings.TrimSpace(c.AppFrontendURL), "/")
+	if url == "" {
+		url = "http://localhost:3000"
+	}
+	return url
+}
+
+// EmailVerification returns the email verification configuration
+func (c *Config) EmailVerification() EmailVerificationConfig {
+	return EmailVerificationConfig{
+		Expiry:         c.EmailVerificationExpiry,
+		ResendCooldown: c.EmailVerificationResendCooldown,
+		URL:            c.FrontendURL() + "/verify-email",
+	}
+}
+
+// PasswordReset returns the password reset configuration
+func (c *Config) PasswordReset() PasswordResetConfig {
+	return PasswordResetConfig{
+		Expiry:   c.PasswordResetExpiry,
+		Cooldown: c.PasswordResetCooldown,
+		URL:      c.FrontendURL() + "/reset-password",
+	}
+}
+
+// EmailChange returns the email change confirmation configuration
+func (c *Config) EmailChange() EmailChangeConfig {
+	return EmailChangeConfig{
+		Expiry: c.EmailChangeExpiry,
+		URL:    c.FrontendURL() + "/confirm-email-change",
+	}
+}
+
+// MagicLink returns the passwordless login configuration
+func (c *Config) MagicLink() MagicLinkConfig {
+	return MagicLinkConfig{
+		Expiry:        c.MagicLinkExpiry,
+		Cooldown:      c.MagicLinkCooldown,
+		MaxRequests:   c.MagicLinkMaxRequests,
+		IPMaxRequests: c.MagicLinkIPMaxRequests,
+		Window:        c.MagicLinkWindow,
+		URL:           c.FrontendURL() + "/magic-link",
+	}
+}
+
+// PasswordPolicy returns the rules enforced whenever a password is set
+func (c *Config) PasswordPolicy() PasswordPolicyConfig {
+	minLength := c.PasswordMinLength
+	if minLength <= 0 {
+		minLength = 8
+	}
+
+	// bcrypt ignores everything after 72 bytes, so longer passwords would be silently truncated
+	maxLength := c.PasswordMaxLength
+	if maxLength <= 0 || maxLength > 72 {
+		maxLength = 72
+	}
+
+	history := c.PasswordHistory
+	if history < 0 {
+		history = 0
+	}
+
+	return PasswordPolicyConfig{
+		MinLength:          minLength,
+		MaxLength:          maxLength,
+		RequireMixedCase:   c.PasswordRequireMixedCase,
+		RequireNumbers:     c.PasswordRequireNumbers,
+		RequireSymbols:     c.PasswordRequireSymbols,
+		RejectPersonalInfo: c.PasswordRejectPersonalInfo,
+		History:            history,
+		CheckBreached:      c.PasswordCheckBreached,
+		BreachedHashDir:    strings.TrimSpace(c.PasswordBreachedHashDir),
+	}
+}
+
+// Impersonation returns the admin impersonation configuration
+func (c *Config) Impersonation() ImpersonationConfig {
+	expiry := c.ImpersonationTokenExpiry
+	if expiry <= 0 {
+		expiry = 15 * time.Minute
+	}
+
+	return ImpersonationConfig{
+		TokenExpiry: expiry,
 	}
 }
 
@@ -128,9 +515,140 @@ type CORSConfig struct {
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
-	SecretKey     string
-	AccessExpiry  time.Duration
-	RefreshExpiry time.Duration
+	SecretKey           string
+	AccessExpiry        time.Duration
+	RefreshExpiry       time.Duration
+	Algorithm           string        // HS256 (shared secret), RS256 or EdDSA
+	KeysDir             string        // Directory holding the RS256/EdDSA private keys
+	KeyRotationInterval time.Duration // Zero disables scheduled rotation
+	KeyRetention        time.Duration // How long retired keys remain valid for verification
+}
+
+// SessionConfig holds session-related configuration
+type SessionConfig struct {
+	MaxSessions          int           // Zero means unlimited
+	TokenVersionCacheTTL time.Duration // How long a user's access token version is cached
+	RefreshReuseGrace    time.Duration // How long a rotated refresh token may be retried without counting as reuse
+}
+
+// AuthorizationConfig holds policy engine configuration
+type AuthorizationConfig struct {
+	PermissionCacheTTL time.Duration // How long role permissions are cached in memory
+}
+
+// APIKeyConfig holds API key configuration
+type APIKeyConfig struct {
+	Prefix string // Starts every key, followed by an underscore
+}
+
+// LoginThrottleConfig holds brute-force protection configuration
+type LoginThrottleConfig struct {
+	MaxAttempts     int           // Failures per email before a lockout; zero disables the lockout
+	IPMaxAttempts   int           // Failures per client IP before a lockout; zero disables the lockout
+	Window          time.Duration // Period over which failures are counted
+	LockoutDuration time.Duration
+	BackoffBase     time.Duration // Delay after the second failure for an email, doubled per further failure
+	BackoffMax      time.Duration
+}
+
+// AuthCookieConfig holds cookie auth mode configuration
+type AuthCookieConfig struct {
+	Enabled  bool   // Sends the refresh token in an HttpOnly cookie instead of the response body
+	Domain   string // Empty scopes the cookies to the API host
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// TwoFactorConfig holds two-factor authentication configuration
+type TwoFactorConfig struct {
+	Issuer            string        // Shown in authenticator apps
+	ChallengeExpiry   time.Duration // Lifetime of the token exchanged for tokens after the second factor
+	RecoveryCodeCount int
+	MaxFailures       int           // Wrong codes per user, across challenges, that lock the second factor; 0 disables
+	LockoutDuration   time.Duration // How long the lockout lasts, and the window failures are counted in
+}
+
+// OAuthConfig holds social login configuration
+type OAuthConfig struct {
+	CallbackURL string        // Redirect URI registered with the providers; {provider} is replaced by the provider name
+	StateTTL    time.Duration // How long a started sign-in can be completed
+	Google      OAuthClientConfig
+	GitHub      OAuthClientConfig
+	OIDC        OIDCProviderConfig
+}
+
+// OAuthClientConfig holds the client registration at a provider
+type OAuthClientConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// OIDCProviderConfig holds the configuration of a generic OpenID Connect provider
+type OIDCProviderConfig struct {
+	OAuthClientConfig
+	Name      string
+	IssuerURL string
+	Scopes    []string
+}
+
+// CacheConfig holds cache-related configuration
+type CacheConfig struct {
+	Driver string
+}
+
+// MailConfig holds mail-related configuration
+type MailConfig struct {
+	Driver       string
+	From         string
+	ResendAPIKey string
+}
+
+// EmailVerificationConfig holds email verification configuration
+type EmailVerificationConfig struct {
+	Expiry         time.Duration
+	ResendCooldown time.Duration
+	URL            string
+}
+
+// PasswordResetConfig holds password reset configuration
+type PasswordResetConfig struct {
+	Expiry   time.Duration
+	Cooldown time.Duration
+	URL      string
+}
+
+// EmailChangeConfig holds email change confirmation configuration
+type EmailChangeConfig struct {
+	Expiry time.Duration
+	URL    string
+}
+
+// MagicLinkConfig holds passwordless login configuration
+type MagicLinkConfig struct {
+	Expiry        time.Duration
+	Cooldown      time.Duration // Minimum time between two links sent to the same account
+	MaxRequests   int           // Requests per email within Window; zero disables the limit
+	IPMaxRequests int           // Requests per client IP within Window; zero disables the limit
+	Window        time.Duration
+	URL           string
+}
+
+// PasswordPolicyConfig holds password policy configuration
+type PasswordPolicyConfig struct {
+	MinLength          int
+	MaxLength          int // In bytes, at most 72
+	RequireMixedCase   bool
+	RequireNumbers     bool
+	RequireSymbols     bool
+	RejectPersonalInfo bool   // Rejects passwords containing the user's email address or name
+	History            int    // Previous passwords a user may not reuse; zero allows reuse
+	CheckBreached      bool   // Rejects passwords found in the bundled list of common passwords
+	BreachedHashDir    string // Optional Have I Been Pwned range files extending the breach check
+}
+
+// ImpersonationConfig holds admin impersonation configuration
+type ImpersonationConfig struct {
+	TokenExpiry time.Duration // Lifetime of impersonation access tokens, which cannot be refreshed
 }
 
 // SwaggerConfig holds swagger basic auth configuration
@@ -158,14 +676,97 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_NAME", "gin_skeleton")
 	viper.SetDefault("DB_SSL_MODE", "disable")
 	viper.SetDefault("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
+	viper.SetDefault("APP_FRONTEND_URL", "http://localhost:3000")
+
+	// Mail defaults
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "no-reply@example.com")
+	viper.SetDefault("RESEND_API_KEY", "")
 
 	// JWT defaults
 	viper.SetDefault("JWT_SECRET_KEY", "your-secret-key-change-in-production")
 	viper.SetDefault("JWT_ACCESS_EXPIRY", "168h")  // 7 days
 	viper.SetDefault("JWT_REFRESH_EXPIRY", "720h") // 30 days
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("JWT_KEYS_DIR", "storage/keys")
+	viper.SetDefault("JWT_KEY_ROTATION_INTERVAL", "720h") // 30 days
+	viper.SetDefault("JWT_KEY_RETENTION", "0s")           // Defaults to the longest token lifetime
+	viper.SetDefault("AUTH_MAX_SESSIONS", 10)
+	viper.SetDefault("AUTH_TOKEN_VERSION_CACHE_TTL", "1m")
+	viper.SetDefault("AUTH_REFRESH_REUSE_GRACE", "10s")
+	viper.SetDefault("AUTH_PERMISSION_CACHE_TTL", "1m")
+	viper.SetDefault("API_KEY_PREFIX", "gsk")
+	viper.SetDefault("AUTH_LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("AUTH_LOGIN_IP_MAX_ATTEMPTS", 50)
+	viper.SetDefault("AUTH_LOGIN_ATTEMPT_WINDOW", "15m")
+	viper.SetDefault("AUTH_LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("AUTH_LOGIN_BACKOFF_BASE", "1s")
+	viper.SetDefault("AUTH_LOGIN_BACKOFF_MAX", "30s")
+	viper.SetDefault("AUTH_COOKIE_MODE", false)
+	viper.SetDefault("AUTH_COOKIE_DOMAIN", "")
+	viper.SetDefault("AUTH_COOKIE_SECURE", true)
+	viper.SetDefault("AUTH_COOKIE_SAME_SITE", "strict")
+
+	// Two-factor defaults
+	viper.SetDefault("APP_ENCRYPTION_KEY", "")
+	viper.SetDefault("TWO_FACTOR_ISSUER", "Gin Skeleton")
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_EXPIRY", "5m")
+	viper.SetDefault("TWO_FACTOR_RECOVERY_CODES", 10)
+	viper.SetDefault("TWO_FACTOR_MAX_FAILURES", 10)
+	viper.SetDefault("TWO_FACTOR_LOCKOUT", "15m")
+
+	// Social login defaults
+	viper.SetDefault("OAUTH_CALLBACK_URL", "http://localhost:8000/api/auth/oauth/{provider}/callback")
+	viper.SetDefault("OAUTH_STATE_TTL", "10m")
+	viper.SetDefault("GOOGLE_CLIENT_ID", "")
+	viper.SetDefault("GOOGLE_CLIENT_SECRET", "")
+	viper.SetDefault("GITHUB_CLIENT_ID", "")
+	viper.SetDefault("GITHUB_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_PROVIDER_NAME", "oidc")
+	viper.SetDefault("OIDC_ISSUER_URL", "")
+	viper.SetDefault("OIDC_CLIENT_ID", "")
+	viper.SetDefault("OIDC_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+
+	// Cache defaults
+	viper.SetDefault("CACHE_DRIVER", "memory")
+
+	// Email verification defaults
+	viper.SetDefault("EMAIL_VERIFICATION_EXPIRY", "24h")
+	viper.SetDefault("EMAIL_VERIFICATION_RESEND_COOLDOWN", "60s")
+
+	// Password reset defaults
+	viper.SetDefault("PASSWORD_RESET_EXPIRY", "1h")
+	viper.SetDefault("PASSWORD_RESET_COOLDOWN", "60s")
+
+	// Email change defaults
+	viper.SetDefault("EMAIL_CHANGE_EXPIRY", "24h")
+
+	// Magic link defaults
+	viper.SetDefault("MAGIC_LINK_EXPIRY", "15m")
+	viper.SetDefault("MAGIC_LINK_COOLDOWN", "60s")
+	viper.SetDefault("MAGIC_LINK_MAX_REQUESTS", 5)
+	viper.SetDefault("MAGIC_LINK_IP_MAX_REQUESTS", 20)
+	viper.SetDefault("MAGIC_LINK_WINDOW", "1h")
+
+	// Swagger defaults
 	viper.SetDefault("SWAGGER_BASIC_AUTH_USERNAME", "admin")
 	viper.SetDefault("SWAGGER_BASIC_AUTH_PASSWORD", "change-me")
 
+	// Password policy defaults
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MAX_LENGTH", 72)
+	viper.SetDefault("PASSWORD_REQUIRE_MIXED_CASE", false)
+	viper.SetDefault("PASSWORD_REQUIRE_NUMBERS", false)
+	viper.SetDefault("PASSWORD_REQUIRE_SYMBOLS", false)
+	viper.SetDefault("PASSWORD_REJECT_PERSONAL_INFO", true)
+	viper.SetDefault("PASSWORD_HISTORY", 5)
+	viper.SetDefault("PASSWORD_CHECK_BREACHED", true)
+	viper.SetDefault("PASSWORD_BREACHED_HASH_DIR", "")
+
+	// Impersonation defaults
+	viper.SetDefault("AUTH_IMPERSONATION_TOKEN_EXPIRY", "15m")
+
 	// Enable environment variables
 	viper.AutomaticEnv()
 
@@ -0,0 +1,205 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin/internal/shared/oauth"
+)
+
+// Default GitHub endpoints; overridable for GitHub Enterprise and tests
+const (
+	DefaultAuthURL  = "https://github.com/login/oauth/authorize"
+	DefaultTokenURL = "https://github.com/login/oauth/access_token"
+	DefaultAPIURL   = "https://api.github.com"
+)
+
+// Config contains the OAuth app registration at GitHub.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	AuthURL      string
+	TokenURL     string
+	APIURL       string
+	HTTPClient   *http.Client
+}
+
+// Client signs users in with GitHub.
+// GitHub OAuth apps do not issue ID tokens, so the identity is read from the REST API.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// New creates a GitHub OAuth client.
+func New(config Config) *Client {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"read:user", "user:email"}
+	}
+	if config.AuthURL == "" {
+		config.AuthURL = DefaultAuthURL
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = DefaultTokenURL
+	}
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	config.APIURL = strings.TrimRight(config.APIURL, "/")
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Client{config: config, httpClient: httpClient}
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string {
+	return "github"
+}
+
+// AuthCodeURL returns the GitHub authorization URL for an authorization-code request with PKCE.
+func (c *Client) AuthCodeURL(ctx context.Context, req oauth.AuthorizationRequest) (string, error) {
+	endpoint, err := url.Parse(c.config.AuthURL)
+	if err != nil {
+		return "", fmt.Errorf("github: invalid authorization URL: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("client_id", c.config.ClientID)
+	query.Set("redirect_uri", req.RedirectURI)
+	query.Set("scope", strings.Join(c.config.Scopes, " "))
+	query.Set("state", req.State)
+	query.Set("code_challenge", req.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+// Exchange redeems the authorization code and reads the user's profile and primary email.
+func (c *Client) Exchange(ctx context.Context, req oauth.ExchangeRequest) (*oauth.Identity, error) {
+	accessToken, err := c.exchangeCode(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := c.getJSON(ctx, accessToken, "/user", &profile); err != nil {
+		return nil, err
+	}
+	if profile.ID == 0 {
+		return nil, errors.New("github: user response has no id")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := c.getJSON(ctx, accessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &oauth.Identity{
+		Provider: c.Name(),
+		Subject:  strconv.FormatInt(profile.ID, 10),
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	name := strings.TrimSpace(profile.Name)
+	if name == "" {
+		name = profile.Login
+	}
+	if first, last, ok := strings.Cut(name, " "); ok {
+		identity.FirstName, identity.LastName = first, strings.TrimSpace(last)
+	} else {
+		identity.FirstName = name
+	}
+
+	return identity, nil
+}
+
+// exchangeCode redeems the authorization code for an access token
+func (c *Client) exchangeCode(ctx context.Context, req oauth.ExchangeRequest) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", c.config.ClientID)
+	form.Set("client_secret", c.config.ClientSecret)
+	form.Set("code", req.Code)
+	form.Set("redirect_uri", req.RedirectURI)
+	form.Set("code_verifier", req.CodeVerifier)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := c.do(httpReq, &tokens); err != nil {
+		return "", err
+	}
+	// GitHub reports token errors with status 200 and an error field
+	if tokens.Error != "" || tokens.AccessToken == "" {
+		return "", fmt.Errorf("github: token request failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+
+	return tokens.AccessToken, nil
+}
+
+// getJSON calls a REST API endpoint with the user's access token
+func (c *Client) getJSON(ctx context.Context, accessToken string, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	return c.do(req, out)
+}
+
+// do performs a request and decodes a successful JSON response
+func (c *Client) do(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("github: request to %s failed: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github: %s returned status %d", req.URL.Path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, out)
+}
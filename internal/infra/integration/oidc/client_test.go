@@ -0,0 +1,288 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gin/internal/shared/oauth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testRedirectURI  = "http://localhost:8000/api/auth/oauth/stub/callback"
+)
+
+// stubProvider is a minimal OpenID Connect provider serving discovery, JWKS and token endpoints
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+
+	// codes maps issued authorization codes to the PKCE challenge and nonce of their request
+	codes map[string]stubAuthorization
+	// claims lets a test tamper with the ID token
+	claims func(jwt.MapClaims)
+	// signingKey overrides the key used to sign ID tokens
+	signingKey *rsa.PrivateKey
+}
+
+type stubAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	stub := &stubProvider{t: t, key: key, keyID: "stub-key-1", codes: map[string]stubAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 stub.server.URL,
+			"authorization_endpoint": stub.server.URL + "/authorize",
+			"token_endpoint":         stub.server.URL + "/token",
+			"jwks_uri":               stub.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": stub.keyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", stub.token)
+
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+// authorize simulates the user approving the request and returns the code sent to the redirect URI
+func (s *stubProvider) authorize(authorizationURL string) string {
+	s.t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		s.t.Fatalf("parse authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != testClientID {
+		s.t.Fatalf("unexpected authorization request: %s", authorizationURL)
+	}
+
+	code := "code-" + query.Get("state")
+	s.codes[code] = stubAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code
+}
+
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	authorization, ok := s.codes[r.PostForm.Get("code")]
+	if !ok || r.PostForm.Get("redirect_uri") != testRedirectURI {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(s.codes, r.PostForm.Get("code"))
+
+	if oauth.CodeChallengeS256(r.PostForm.Get("code_verifier")) != authorization.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.server.URL,
+		"sub":            "stub-user-42",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          authorization.nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+	if s.claims != nil {
+		s.claims(claims)
+	}
+
+	signingKey := s.key
+	if s.signingKey != nil {
+		signingKey = s.signingKey
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	idToken, err := token.SignedString(signingKey)
+	if err != nil {
+		s.t.Fatalf("sign id token: %v", err)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestClient(stub *stubProvider) *Client {
+	return New(Config{
+		Name:         "stub",
+		IssuerURL:    stub.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		HTTPClient:   stub.server.Client(),
+	})
+}
+
+// signIn runs the authorization-code flow against the stub provider
+func signIn(t *testing.T, client *Client, stub *stubProvider) (*oauth.Identity, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	verifier, err := oauth.GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("generate verifier: %v", err)
+	}
+
+	authorizationURL, err := client.AuthCodeURL(ctx, oauth.AuthorizationRequest{
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: oauth.CodeChallengeS256(verifier),
+		RedirectURI:   testRedirectURI,
+	})
+	if err != nil {
+		t.Fatalf("build authorization URL: %v", err)
+	}
+	if !strings.HasPrefix(authorizationURL, stub.server.URL+"/authorize?") {
+		t.Fatalf("authorization URL = %s, want the discovered endpoint", authorizationURL)
+	}
+
+	code := stub.authorize(authorizationURL)
+	return client.Exchange(ctx, oauth.ExchangeRequest{
+		Code:         code,
+		CodeVerifier: verifier,
+		Nonce:        "nonce-1",
+		RedirectURI:  testRedirectURI,
+	})
+}
+
+func TestClientSignsInWithStubProvider(t *testing.T) {
+	stub := newStubProvider(t)
+	client := newTestClient(stub)
+
+	identity, err := signIn(t, client, stub)
+	if err != nil {
+		t.Fatalf("sign in: %v", err)
+	}
+
+	want := oauth.Identity{
+		Provider:      "stub",
+		Subject:       "stub-user-42",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		FirstName:     "Jane",
+		LastName:      "Doe",
+	}
+	if *identity != want {
+		t.Fatalf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestClientRejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		claims     func(jwt.MapClaims)
+		signingKey *rsa.PrivateKey
+	}{
+		{name: "foreign signature", signingKey: otherKey},
+		{name: "nonce mismatch", claims: func(c jwt.MapClaims) { c["nonce"] = "replayed" }},
+		{name: "other audience", claims: func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{name: "other issuer", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := newStubProvider(t)
+			stub.claims = tc.claims
+			stub.signingKey = tc.signingKey
+
+			if identity, err := signIn(t, newTestClient(stub), stub); err == nil {
+				t.Fatalf("expected the ID token to be rejected, got %+v", identity)
+			}
+		})
+	}
+}
+
+func TestClientRejectsWrongCodeVerifier(t *testing.T) {
+	stub := newStubProvider(t)
+	client := newTestClient(stub)
+	ctx := context.Background()
+
+	authorizationURL, err := client.AuthCodeURL(ctx, oauth.AuthorizationRequest{
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: oauth.CodeChallengeS256("the-real-verifier"),
+		RedirectURI:   testRedirectURI,
+	})
+	if err != nil {
+		t.Fatalf("build authorization URL: %v", err)
+	}
+
+	_, err = client.Exchange(ctx, oauth.ExchangeRequest{
+		Code:         stub.authorize(authorizationURL),
+		CodeVerifier: "an-intercepted-code-without-the-verifier",
+		Nonce:        "nonce-1",
+		RedirectURI:  testRedirectURI,
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("expected invalid_grant, got %v", err)
+	}
+}
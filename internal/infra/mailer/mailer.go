@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+
+	"gin/internal/infra/config"
+	"gin/internal/infra/integration/resend"
+	"gin/internal/infra/logger"
+	"gin/internal/shared/mail"
+)
+
+// NewMailer creates the mailer selected by MAIL_DRIVER ("log" or "resend")
+func NewMailer(cfg *config.Config) mail.Mailer {
+	mailConfig := cfg.Mail()
+
+	switch strings.ToLower(mailConfig.Driver) {
+	case "resend":
+		return &resendMailer{
+			client: resend.New(resend.Config{
+				APIKey: mailConfig.ResendAPIKey,
+				From:   mailConfig.From,
+			}),
+		}
+	default:
+		return &logMailer{from: mailConfig.From}
+	}
+}
+
+// logMailer writes outgoing emails to the application log
+// It is the default driver for local development
+type logMailer struct {
+	from string
+}
+
+// Send logs the email instead of delivering it
+func (m *logMailer) Send(ctx context.Context, msg mail.Message) error {
+	logger.LogInfo("Email sent via log mailer", map[string]interface{}{
+		"from":    m.from,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"html":    msg.HTML,
+	})
+	return nil
+}
+
+// resendMailer delivers emails through the Resend integration
+type resendMailer struct {
+	client *resend.Client
+}
+
+// Send delivers the email through Resend
+func (m *resendMailer) Send(ctx context.Context, msg mail.Message) error {
+	return m.client.SendEmail(ctx, resend.SendEmailInput{
+		To:      msg.To,
+		Subject: msg.Subject,
+		HTML:    msg.HTML,
+	})
+}
@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"gin/internal/shared/authz"
+	exception "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the request header carrying API keys
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves an API key to the principal it acts as
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*authz.Principal, error)
+}
+
+// JWTOrAPIKeyAuthMiddleware authenticates the request with an X-API-Key header when present,
+// and with a bearer access token otherwise
+// Both populate the same context values, so handlers using RequireUserID work with either
+func JWTOrAPIKeyAuthMiddleware(jwtManager *utils.JWTManager, validator AccessTokenValidator, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(APIKeyHeader) != "" {
+			if err := authenticateAPIKey(c, apiKeys); err != nil {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		claims, err := authenticate(c, jwtManager, validator)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		setAuthContext(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware authenticates the request when it carries an access token or an API key
+// and lets anonymous requests through. Credentials that are present but invalid are still rejected
+func OptionalAuthMiddleware(jwtManager *utils.JWTManager, validator AccessTokenValidator, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
+	authenticated := JWTOrAPIKeyAuthMiddleware(jwtManager, validator, apiKeys)
+	return func(c *gin.Context) {
+		if c.GetHeader(APIKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticated(c)
+	}
+}
+
+// authenticateAPIKey validates the API key of the request and stores the principal it acts as
+func authenticateAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator) error {
+	principal, err := apiKeys.Authenticate(c.Request.Context(), c.GetHeader(APIKeyHeader))
+	if err != nil {
+		var appErr exception.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return exception.InternalError("Failed to validate API key", nil, nil)
+	}
+
+	// Handlers reading the claims see the owner and role, but no session
+	claims := &utils.JWTClaims{
+		UserID: principal.UserID,
+		Type:   utils.CredentialTypeAPIKey,
+		Role:   string(principal.Role),
+	}
+	setPrincipal(c, principal, claims)
+	c.Set("api_key_id", principal.APIKeyID)
+	return nil
+}
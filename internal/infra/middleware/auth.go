@@ -1,6 +1,9 @@
 package middlewares
 
 import (
+	"context"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
 	exception "gin/internal/shared/exception"
 	"gin/internal/shared/utils"
 	"strings"
@@ -8,57 +11,96 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AccessTokenValidator checks that a validly signed access token has not been revoked
+type AccessTokenValidator interface {
+	IsAccessTokenValid(ctx context.Context, claims *utils.JWTClaims) (bool, error)
+}
+
 // JWTAuthMiddleware validates JWT tokens and extracts user information
-func JWTAuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// Tokens revoked by logout, ban, password change or session revocation are rejected immediately
+func JWTAuthMiddleware(jwtManager *utils.JWTManager, validator AccessTokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			appErr := exception.UnauthorizedError("Authorization header is required", nil, nil)
-			_ = c.Error(appErr)
+		claims, err := authenticate(c, jwtManager, validator)
+		if err != nil {
+			_ = c.Error(err)
 			c.Abort()
 			return
 		}
 
-		// Check if the header starts with "Bearer "
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			appErr := exception.UnauthorizedError("Invalid authorization header format. Expected 'Bearer <token>'", nil, nil)
-			_ = c.Error(appErr)
-			c.Abort()
-			return
-		}
+		setAuthContext(c, claims)
 
-		// Extract the token (remove "Bearer " prefix)
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == "" {
-			appErr := exception.UnauthorizedError("Token is required", nil, nil)
-			_ = c.Error(appErr)
-			c.Abort()
-			return
-		}
+		// Continue to the next handler
+		c.Next()
+	}
+}
 
-		// Validate the token using the injected JWT manager
-		claims, err := jwtManager.ValidateToken(tokenString)
-		if err != nil {
-			appErr := exception.UnauthorizedError("Invalid or expired token", nil, nil)
-			_ = c.Error(appErr)
-			c.Abort()
-			return
-		}
+// authenticate validates the bearer access token of the request
+func authenticate(c *gin.Context, jwtManager *utils.JWTManager, validator AccessTokenValidator) (*utils.JWTClaims, error) {
+	// Get the Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, exception.UnauthorizedError("Authorization header is required", nil, nil)
+	}
 
-		// Ensure this is an access token
-		if claims.Type != "access" {
-			appErr := exception.UnauthorizedError("Invalid token type. Access token required", nil, nil)
-			_ = c.Error(appErr)
-			c.Abort()
-			return
-		}
+	// Check if the header starts with "Bearer "
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, exception.UnauthorizedError("Invalid authorization header format. Expected 'Bearer <token>'", nil, nil)
+	}
 
-		// Set user ID in context for later use
-		c.Set("user_id", claims.UserID)
-		c.Set("user_claims", claims)
+	// Extract the token (remove "Bearer " prefix)
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == "" {
+		return nil, exception.UnauthorizedError("Token is required", nil, nil)
+	}
 
-		// Continue to the next handler
-		c.Next()
+	// Validate the token using the injected JWT manager
+	claims, err := jwtManager.ValidateToken(tokenString)
+	if err != nil {
+		return nil, exception.UnauthorizedError("Invalid or expired token", nil, nil)
+	}
+
+	// Ensure this is an access token
+	if claims.Type != "access" {
+		return nil, exception.UnauthorizedError("Invalid token type. Access token required", nil, nil)
+	}
+
+	// Ensure the token has not been revoked since it was issued
+	valid, err := validator.IsAccessTokenValid(c.Request.Context(), claims)
+	if err != nil {
+		return nil, exception.InternalError("Failed to validate token", nil, nil)
+	}
+	if !valid {
+		return nil, exception.UnauthorizedError("Token has been revoked", nil, nil)
+	}
+
+	return claims, nil
+}
+
+// setAuthContext stores the user of a validated access token in the request
+func setAuthContext(c *gin.Context, claims *utils.JWTClaims) {
+	role := roleFromClaims(claims)
+	principal := &authz.Principal{UserID: claims.UserID, Role: role, SessionID: claims.SessionID}
+	if claims.IsImpersonation() {
+		principal.ActorID = claims.Act.Subject
+	}
+	setPrincipal(c, principal, claims)
+}
+
+// setPrincipal stores the authenticated user in the gin context and the principal in the request context,
+// so services can authorize without depending on gin
+func setPrincipal(c *gin.Context, principal *authz.Principal, claims *utils.JWTClaims) {
+	c.Set("user_id", principal.UserID)
+	c.Set("user_claims", claims)
+	c.Set("user_role", principal.Role)
+
+	c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), principal))
+}
+
+// roleFromClaims returns the role carried by an access token
+// Tokens issued before roles were added to the claims count as regular users
+func roleFromClaims(claims *utils.JWTClaims) constant.AccountTypeEnum {
+	if claims.Role == "" {
+		return constant.AccountTypeCustomer
 	}
+	return constant.AccountTypeEnum(claims.Role)
 }
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"regexp"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +19,9 @@ var bufferPool = sync.Pool{
 	},
 }
 
+// identifierKey matches the keys whose case is converted
+var identifierKey = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
 // keyCache caches converted keys to avoid repeated conversions
 type keyCache struct {
 	snakeToCamel map[string]string
@@ -111,7 +114,7 @@ func CaseConverterMiddleware() gin.HandlerFunc {
 		}()
 
 		// Only process JSON responses
-		if !strings.Contains(writer.Header().Get("Content-Type"), "application/json") {
+		if !isJSONMediaType(writer.Header().Get("Content-Type")) {
 			// Write original response for non-JSON content
 			if writer.status != 0 {
 				writer.ResponseWriter.WriteHeader(writer.status)
@@ -131,7 +134,7 @@ func isJSONContent(c *gin.Context) bool {
 	if c.Request.Method == "GET" {
 		return true
 	}
-	return strings.Contains(c.GetHeader("Content-Type"), "application/json")
+	return isJSONMediaType(c.GetHeader("Content-Type"))
 }
 
 // processRequestBody handles the request body conversion
@@ -226,10 +229,14 @@ func convertValue(v interface{}, converter func(string) string) interface{} {
 }
 
 // convertMap converts all keys in a map
+// Keys that are not plain identifiers, such as filter[status] in validation errors, are kept as they are
 func convertMap(m map[string]interface{}, converter func(string) string) map[string]interface{} {
 	result := make(map[string]interface{}, len(m))
 	for k, v := range m {
-		result[converter(k)] = convertValue(v, converter)
+		if identifierKey.MatchString(k) {
+			k = converter(k)
+		}
+		result[k] = convertValue(v, converter)
 	}
 	return result
 }
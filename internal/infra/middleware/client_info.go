@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"gin/internal/shared/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfoMiddleware stores the client IP and user agent in the request context
+// Services read them through audit.ClientFromContext when recording security events
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithClient(c.Request.Context(), audit.Client{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
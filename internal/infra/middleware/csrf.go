@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	exception "gin/internal/shared/exception"
+	"gin/internal/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFMiddleware enforces the double-submit CSRF check in cookie auth mode
+// State-changing requests that carry the refresh token cookie must echo the CSRF cookie in the
+// X-CSRF-Token header. A cross-site page can make the browser send the cookies but cannot read them,
+// so it cannot produce the header. Requests without the cookie are not authenticated by it and pass
+func CSRFMiddleware(options utils.AuthCookieOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !options.Enabled || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if _, ok := utils.RefreshTokenFromCookie(c, options); !ok {
+			c.Next()
+			return
+		}
+
+		cookieToken, err := c.Cookie(utils.CSRFTokenCookie)
+		headerToken := c.GetHeader(utils.CSRFTokenHeader)
+		if err != nil || cookieToken == "" || subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) != 1 {
+			desc := "Send the csrf_token cookie value in the X-CSRF-Token header."
+			appErr := exception.ForbiddenError("CSRF token mismatch", &desc, nil)
+			_ = c.Error(appErr)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isSafeMethod reports whether the HTTP method is read-only
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
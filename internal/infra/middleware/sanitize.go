@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"mime"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -22,8 +23,7 @@ func SanitizeMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		contentType := c.GetHeader("Content-Type")
-		if !strings.Contains(contentType, "application/json") {
+		if !isJSONMediaType(c.GetHeader("Content-Type")) {
 			c.Next()
 			return
 		}
@@ -72,6 +72,17 @@ func SanitizeMiddleware() gin.HandlerFunc {
 	}
 }
 
+// isJSONMediaType reports whether a Content-Type holds JSON: application/json or any
+// structured +json type, such as application/merge-patch+json and application/json-patch+json
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
 // sanitizeValue recursively sanitizes string values in the data structure
 func sanitizeValue(v interface{}, policy *bluemonday.Policy) interface{} {
 	switch val := v.(type) {
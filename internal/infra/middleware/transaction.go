@@ -3,6 +3,7 @@ package middlewares
 import (
 	"context"
 	"gin/internal/infra/logger"
+	"gin/internal/shared/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -12,6 +13,7 @@ const TransactionKey = "db_transaction"
 
 // TransactionMiddleware creates a middleware that wraps requests in a database transaction
 // The transaction is committed on success or rolled back on error
+// Callbacks registered with utils.AfterCommit run only after a successful commit
 // The transaction is stored in both Gin context and request context for repository access
 func TransactionMiddleware(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -28,8 +30,10 @@ func TransactionMiddleware(db *gorm.DB) gin.HandlerFunc {
 		// Store transaction in Gin context (for middleware access)
 		c.Set(TransactionKey, tx)
 
-		// Store transaction in request context (for repository access)
+		// Store transaction in request context (for repository access), along with the
+		// callbacks that must wait for the commit
 		ctx := context.WithValue(c.Request.Context(), TransactionKey, tx)
+		ctx, runAfterCommit := utils.WithAfterCommit(ctx)
 		c.Request = c.Request.WithContext(ctx)
 
 		// Process request
@@ -53,7 +57,9 @@ func TransactionMiddleware(db *gorm.DB) gin.HandlerFunc {
 				c.AbortWithStatusJSON(500, gin.H{
 					"error": "Failed to commit database transaction",
 				})
+				return
 			}
+			runAfterCommit()
 		}
 	}
 }
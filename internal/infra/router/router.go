@@ -3,11 +3,19 @@ package router
 import (
 	"crypto/subtle"
 	_ "gin/docs" // Swagger documentation
+	apikeyhandler "gin/internal/domain/api_key/handler"
+	apikeysvc "gin/internal/domain/api_key/service"
 	authhandler "gin/internal/domain/auth/handler"
+	authsvc "gin/internal/domain/auth/service"
+	emailchangehandler "gin/internal/domain/email_change/handler"
 	healthhandler "gin/internal/domain/health/handler"
+	impersonationhandler "gin/internal/domain/impersonation/handler"
+	permissionhandler "gin/internal/domain/permission/handler"
+	securityeventhandler "gin/internal/domain/security_event/handler"
 	userhandler "gin/internal/domain/user/handler"
 	"gin/internal/infra/config"
 	middleware "gin/internal/infra/middleware"
+	"gin/internal/shared/authz"
 	exceptions "gin/internal/shared/exception"
 	"gin/internal/shared/utils"
 	"net/http"
@@ -25,18 +33,36 @@ type Router interface {
 }
 
 type routerDeps struct {
-	userHandler   *userhandler.UserHandler
-	authHandler   *authhandler.AuthHandler
-	healthHandler *healthhandler.HealthHandler
-	jwtManager    *utils.JWTManager
-	db            *gorm.DB
+	userHandler          *userhandler.UserHandler
+	authHandler          *authhandler.AuthHandler
+	healthHandler        *healthhandler.HealthHandler
+	permissionHandler    *permissionhandler.PermissionHandler
+	apiKeyHandler        *apikeyhandler.APIKeyHandler
+	impersonationHandler *impersonationhandler.ImpersonationHandler
+	securityEventHandler *securityeventhandler.SecurityEventHandler
+	emailChangeHandler   *emailchangehandler.EmailChangeHandler
+	jwtManager           *utils.JWTManager
+	accessTokens         authsvc.AccessTokenServiceInterface
+	apiKeys              apikeysvc.APIKeyServiceInterface
+	authorizer           authz.Authorizer
+	authCookies          utils.AuthCookieOptions
+	db                   *gorm.DB
 }
 
 func NewRouter(
 	userHandler *userhandler.UserHandler,
 	authHandler *authhandler.AuthHandler,
 	healthHandler *healthhandler.HealthHandler,
+	permissionHandler *permissionhandler.PermissionHandler,
+	apiKeyHandler *apikeyhandler.APIKeyHandler,
+	impersonationHandler *impersonationhandler.ImpersonationHandler,
+	securityEventHandler *securityeventhandler.SecurityEventHandler,
+	emailChangeHandler *emailchangehandler.EmailChangeHandler,
 	jwtManager *utils.JWTManager,
+	accessTokens authsvc.AccessTokenServiceInterface,
+	apiKeys apikeysvc.APIKeyServiceInterface,
+	authorizer authz.Authorizer,
+	authCookies utils.AuthCookieOptions,
 	cfg *config.Config,
 	db *gorm.DB,
 ) *gin.Engine {
@@ -45,6 +71,7 @@ func NewRouter(
 	// Add global middleware (order matters)
 	router.Use(middleware.CORSMiddleware(cfg.CORS().AllowedOrigins)) // CORS should be first
 	router.Use(middleware.RequestIDMiddleware())     // Request ID for tracing
+	router.Use(middleware.ClientInfoMiddleware())    // Client IP and user agent for security events
 	router.Use(middleware.LoggingMiddleware())       // Structured logging
 	router.Use(middleware.SanitizeMiddleware())      // Input sanitization (XSS prevention)
 	router.Use(middleware.CaseConverterMiddleware()) // Case conversion
@@ -69,16 +96,27 @@ func NewRouter(
 
 	registerSwaggerRoutes(router, cfg)
 
-	// API routes
-	api := router.Group("/api")
 	deps := &routerDeps{
-		userHandler:   userHandler,
-		authHandler:   authHandler,
-		healthHandler: healthHandler,
-		jwtManager:    jwtManager,
-		db:            db,
+		userHandler:          userHandler,
+		authHandler:          authHandler,
+		healthHandler:        healthHandler,
+		permissionHandler:    permissionHandler,
+		apiKeyHandler:        apiKeyHandler,
+		impersonationHandler: impersonationHandler,
+		securityEventHandler: securityEventHandler,
+		emailChangeHandler:   emailChangeHandler,
+		jwtManager:           jwtManager,
+		accessTokens:         accessTokens,
+		apiKeys:              apiKeys,
+		authorizer:           authorizer,
+		authCookies:          authCookies,
+		db:                   db,
 	}
 
+	registerWellKnownRoutes(router, deps)
+
+	// API routes
+	api := router.Group("/api")
 	registerWebRoutes(api, deps)
 	return router
 }
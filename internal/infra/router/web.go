@@ -1,7 +1,10 @@
 package router
 
 import (
+	userdomain "gin/internal/domain/user"
 	middleware "gin/internal/infra/middleware"
+	"gin/internal/shared/authz"
+	"gin/internal/shared/constant"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,23 +16,79 @@ func registerWebRoutes(api *gin.RouterGroup, d *routerDeps) {
 
 	auth := api.Group("/auth")
 	auth.Use(middleware.RateLimitMiddleware("10-M"))
+	auth.Use(middleware.CSRFMiddleware(d.authCookies)) // Only enforced in cookie auth mode
 	{
 		auth.POST("/signup", middleware.TransactionMiddleware(d.db), d.authHandler.Signup)
+		auth.POST("/verify-email", middleware.TransactionMiddleware(d.db), d.authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", middleware.TransactionMiddleware(d.db), d.authHandler.ResendVerification)
+		auth.POST("/forgot-password", middleware.TransactionMiddleware(d.db), d.authHandler.ForgotPassword)
+		auth.POST("/reset-password", middleware.TransactionMiddleware(d.db), d.authHandler.ResetPassword)
+		auth.POST("/email-change/confirm", middleware.TransactionMiddleware(d.db), d.emailChangeHandler.ConfirmEmailChange)
+		auth.POST("/magic-link", middleware.TransactionMiddleware(d.db), d.authHandler.RequestMagicLink)
+		auth.POST("/magic-link/consume", middleware.TransactionMiddleware(d.db), d.authHandler.ConsumeMagicLink)
 		auth.POST("/login", middleware.TransactionMiddleware(d.db), d.authHandler.Login)
 		auth.POST("/refresh", middleware.TransactionMiddleware(d.db), d.authHandler.RefreshToken)
-		auth.POST("/logout", middleware.JWTAuthMiddleware(d.jwtManager), middleware.TransactionMiddleware(d.db), d.authHandler.Logout)
+		auth.POST("/logout", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.Logout)
+		auth.GET("/sessions", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), d.authHandler.ListSessions)
+		auth.DELETE("/sessions/:id", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.RevokeSession)
+		auth.POST("/change-password", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.ChangePassword)
+		auth.POST("/2fa/enroll", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.EnrollTwoFactor)
+		auth.POST("/2fa/confirm", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.ConfirmTwoFactor)
+		auth.POST("/2fa/disable", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.authHandler.DisableTwoFactor)
+		auth.POST("/2fa/verify", middleware.TransactionMiddleware(d.db), d.authHandler.VerifyTwoFactor)
+		auth.GET("/oauth/:provider", d.authHandler.SocialLoginRedirect)
+		auth.GET("/oauth/:provider/callback", middleware.TransactionMiddleware(d.db), d.authHandler.SocialLoginCallback)
+	}
+
+	// API keys are managed from a login session; a key cannot create or revoke keys,
+	// and an impersonating admin cannot mint credentials for the user
+	apiKeys := api.Group("/api-keys")
+	apiKeys.Use(middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens))
+	{
+		apiKeys.GET("", d.apiKeyHandler.ListAPIKeys)
+		apiKeys.POST("", middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.apiKeyHandler.CreateAPIKey)
+		apiKeys.DELETE("/:id", middleware.DenyImpersonation(), middleware.TransactionMiddleware(d.db), d.apiKeyHandler.RevokeAPIKey)
 	}
 
 	users := api.Group("/users")
 	{
-		users.GET("", d.userHandler.GetAllUsers)
-		users.GET("/:id", d.userHandler.GetUserByID)
+		// Anonymous callers may list users; credentials let the caller see the emails it may read
+		users.GET("", middleware.OptionalAuthMiddleware(d.jwtManager, d.accessTokens, d.apiKeys), d.userHandler.GetAllUsers)
+		users.GET("/:id", middleware.OptionalAuthMiddleware(d.jwtManager, d.accessTokens, d.apiKeys), d.userHandler.GetUserByID)
+		users.GET("/me/security-events", middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens), d.securityEventHandler.ListMySecurityEvents)
 
 		protected := users.Group("/")
-		protected.Use(middleware.JWTAuthMiddleware(d.jwtManager))
+		protected.Use(middleware.JWTOrAPIKeyAuthMiddleware(d.jwtManager, d.accessTokens, d.apiKeys))
 		{
-			protected.PUT("/:id", middleware.TransactionMiddleware(d.db), d.userHandler.UpdateUser)
-			protected.DELETE("/:id", middleware.TransactionMiddleware(d.db), d.userHandler.DeleteUser)
+			protected.PUT("/:id", middleware.DenyImpersonation(), middleware.RequirePermission(d.authorizer, constant.PermissionUsersUpdate, userFromParam), middleware.TransactionMiddleware(d.db), d.userHandler.UpdateUser)
+			protected.PATCH("/:id", middleware.DenyImpersonation(), middleware.RequirePermission(d.authorizer, constant.PermissionUsersUpdate, userFromParam), middleware.TransactionMiddleware(d.db), d.userHandler.PatchUser)
+			protected.PUT("/:id/profile", middleware.DenyImpersonation(), middleware.RequirePermission(d.authorizer, constant.PermissionUsersUpdate, userFromParam), middleware.TransactionMiddleware(d.db), d.userHandler.UpdateProfile)
+			protected.DELETE("/:id", middleware.DenyImpersonation(), middleware.RequirePermission(d.authorizer, constant.PermissionUsersDelete, userFromParam), middleware.TransactionMiddleware(d.db), d.userHandler.DeleteUser)
 		}
 	}
+
+	admin := api.Group("/admin")
+	admin.Use(middleware.JWTAuthMiddleware(d.jwtManager, d.accessTokens))
+	admin.Use(middleware.RequireRole(constant.AccountTypeAdmin))
+	{
+		admin.GET("/permissions", d.permissionHandler.ListPermissions)
+		admin.GET("/roles/:role/permissions", d.permissionHandler.ListRolePermissions)
+		admin.POST("/roles/:role/permissions", middleware.TransactionMiddleware(d.db), d.permissionHandler.GrantPermission)
+		admin.DELETE("/roles/:role/permissions/:permission", middleware.TransactionMiddleware(d.db), d.permissionHandler.RevokePermission)
+		admin.POST("/service-accounts", middleware.TransactionMiddleware(d.db), d.apiKeyHandler.CreateServiceAccount)
+		admin.GET("/users/:id/api-keys", d.apiKeyHandler.ListUserAPIKeys)
+		admin.POST("/users/:id/api-keys", middleware.TransactionMiddleware(d.db), d.apiKeyHandler.CreateUserAPIKey)
+		admin.DELETE("/users/:id/api-keys/:keyId", middleware.TransactionMiddleware(d.db), d.apiKeyHandler.RevokeUserAPIKey)
+		admin.POST("/impersonate/:userId", middleware.TransactionMiddleware(d.db), d.impersonationHandler.Impersonate)
+	}
+}
+
+// userFromParam identifies the user account addressed by the :id route parameter
+func userFromParam(c *gin.Context) authz.Resource {
+	return userdomain.Resource(c.Param("id"))
+}
+
+// registerWellKnownRoutes wires the unauthenticated /.well-known discovery endpoints.
+func registerWellKnownRoutes(router gin.IRoutes, d *routerDeps) {
+	router.GET("/.well-known/jwks.json", d.authHandler.JWKS)
 }
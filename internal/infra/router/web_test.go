@@ -3,27 +3,53 @@ package router
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	apikey "gin/internal/domain/api_key"
+	apikeyhandler "gin/internal/domain/api_key/handler"
 	authhandler "gin/internal/domain/auth/handler"
+	authsvc "gin/internal/domain/auth/service"
+	emailchangehandler "gin/internal/domain/email_change/handler"
 	healthhandler "gin/internal/domain/health/handler"
+	"gin/internal/domain/impersonation"
+	impersonationhandler "gin/internal/domain/impersonation/handler"
+	"gin/internal/domain/permission"
+	permissionhandler "gin/internal/domain/permission/handler"
 	refreshtoken "gin/internal/domain/refresh_token"
+	securityevent "gin/internal/domain/security_event"
+	securityeventhandler "gin/internal/domain/security_event/handler"
+	twofactor "gin/internal/domain/two_factor"
 	userdomain "gin/internal/domain/user"
 	userhandler "gin/internal/domain/user/handler"
+	infracache "gin/internal/infra/cache"
 	"gin/internal/infra/logger"
+	middleware "gin/internal/infra/middleware"
+	"gin/internal/shared/audit"
+	"gin/internal/shared/authz"
 	"gin/internal/shared/constant"
 	exceptions "gin/internal/shared/exception"
+	"gin/internal/shared/oauth"
+	"gin/internal/shared/pagination"
+	"gin/internal/shared/queryspec"
 	"gin/internal/shared/utils"
+	validators "gin/internal/shared/validator"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
@@ -49,23 +75,41 @@ func (*stubTx) Commit() error                { return nil }
 func (*stubTx) Rollback() error              { return nil }
 
 type fakeUserService struct {
-	getAllUsersPaginatedFn func(context.Context, int, int) ([]*userdomain.User, int64, error)
+	getAllUsersPaginatedFn func(context.Context, queryspec.Spec, pagination.Params) (pagination.Page[*userdomain.User], error)
+	getAllUsersByCursorFn  func(context.Context, queryspec.Spec, pagination.CursorParams, bool) (pagination.CursorResult[*userdomain.User], error)
 	getUserByIDFn          func(context.Context, string) (*userdomain.User, error)
 	createUserFn           func(context.Context, userdomain.SignupInput) (*userdomain.User, error)
-	updateUserFn           func(context.Context, map[string]interface{}, *string, string) (*userdomain.User, error)
+	updateUserFn           func(context.Context, map[string]interface{}, string) (*userdomain.User, error)
 	deleteUserFn           func(context.Context, string) error
 	getUserByEmailFn       func(context.Context, string) (*userdomain.User, error)
+	setPasswordFn          func(context.Context, string, string) error
+	changePasswordFn       func(context.Context, string, string, string) error
+	updateStatusFn         func(context.Context, string, constant.UserStatusEnum) error
+	recordSignInFn         func(context.Context, string) error
+	getTokenVersionFn      func(context.Context, string) (int, error)
+	revokeAccessTokensFn   func(context.Context, string) error
+	getBySocialProviderFn  func(context.Context, string, string) (*userdomain.User, error)
+	createSocialUserFn     func(context.Context, userdomain.SocialSignupInput) (*userdomain.User, error)
+	linkSocialProviderFn   func(context.Context, string, string, string) error
+	createServiceAccountFn func(context.Context, string) (*userdomain.User, error)
 }
 
 func (f *fakeUserService) GetAllUsers(context.Context) ([]*userdomain.User, error) {
 	return nil, nil
 }
 
-func (f *fakeUserService) GetAllUsersPaginated(ctx context.Context, page, perPage int) ([]*userdomain.User, int64, error) {
+func (f *fakeUserService) GetAllUsersPaginated(ctx context.Context, spec queryspec.Spec, params pagination.Params) (pagination.Page[*userdomain.User], error) {
 	if f.getAllUsersPaginatedFn != nil {
-		return f.getAllUsersPaginatedFn(ctx, page, perPage)
+		return f.getAllUsersPaginatedFn(ctx, spec, params)
 	}
-	return nil, 0, nil
+	return pagination.NewPage[*userdomain.User](nil, params, nil, false, false), nil
+}
+
+func (f *fakeUserService) GetAllUsersByCursor(ctx context.Context, spec queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*userdomain.User], error) {
+	if f.getAllUsersByCursorFn != nil {
+		return f.getAllUsersByCursorFn(ctx, spec, params, desc)
+	}
+	return pagination.CursorResult[*userdomain.User]{}, nil
 }
 
 func (f *fakeUserService) GetUserByID(ctx context.Context, id string) (*userdomain.User, error) {
@@ -82,9 +126,9 @@ func (f *fakeUserService) CreateUser(ctx context.Context, input userdomain.Signu
 	return &userdomain.User{}, nil
 }
 
-func (f *fakeUserService) UpdateUser(ctx context.Context, updates map[string]interface{}, password *string, id string) (*userdomain.User, error) {
+func (f *fakeUserService) UpdateUser(ctx context.Context, updates map[string]interface{}, id string) (*userdomain.User, error) {
 	if f.updateUserFn != nil {
-		return f.updateUserFn(ctx, updates, password, id)
+		return f.updateUserFn(ctx, updates, id)
 	}
 	return nil, nil
 }
@@ -103,11 +147,91 @@ func (f *fakeUserService) GetUserByEmail(ctx context.Context, email string) (*us
 	return nil, nil
 }
 
+func (f *fakeUserService) IsEmailTaken(ctx context.Context, email string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeUserService) SetPassword(ctx context.Context, id string, password string) error {
+	if f.setPasswordFn != nil {
+		return f.setPasswordFn(ctx, id, password)
+	}
+	return nil
+}
+
+func (f *fakeUserService) ChangePassword(ctx context.Context, id string, currentPassword string, newPassword string) error {
+	if f.changePasswordFn != nil {
+		return f.changePasswordFn(ctx, id, currentPassword, newPassword)
+	}
+	return nil
+}
+
+func (f *fakeUserService) UpdateStatus(ctx context.Context, id string, status constant.UserStatusEnum) error {
+	if f.updateStatusFn != nil {
+		return f.updateStatusFn(ctx, id, status)
+	}
+	return nil
+}
+
+func (f *fakeUserService) RecordSignIn(ctx context.Context, id string) error {
+	if f.recordSignInFn != nil {
+		return f.recordSignInFn(ctx, id)
+	}
+	return nil
+}
+
+func (f *fakeUserService) GetTokenVersion(ctx context.Context, id string) (int, error) {
+	if f.getTokenVersionFn != nil {
+		return f.getTokenVersionFn(ctx, id)
+	}
+	return 0, nil
+}
+
+func (f *fakeUserService) RevokeAccessTokens(ctx context.Context, id string) error {
+	if f.revokeAccessTokensFn != nil {
+		return f.revokeAccessTokensFn(ctx, id)
+	}
+	return nil
+}
+
+func (f *fakeUserService) GetUserBySocialProvider(ctx context.Context, provider string, providerID string) (*userdomain.User, error) {
+	if f.getBySocialProviderFn != nil {
+		return f.getBySocialProviderFn(ctx, provider, providerID)
+	}
+	return nil, nil
+}
+
+func (f *fakeUserService) CreateSocialUser(ctx context.Context, input userdomain.SocialSignupInput) (*userdomain.User, error) {
+	if f.createSocialUserFn != nil {
+		return f.createSocialUserFn(ctx, input)
+	}
+	return &userdomain.User{}, nil
+}
+
+func (f *fakeUserService) LinkSocialProvider(ctx context.Context, id string, provider string, providerID string) error {
+	if f.linkSocialProviderFn != nil {
+		return f.linkSocialProviderFn(ctx, id, provider, providerID)
+	}
+	return nil
+}
+
+func (f *fakeUserService) CreateServiceAccount(ctx context.Context, name string) (*userdomain.User, error) {
+	if f.createServiceAccountFn != nil {
+		return f.createServiceAccountFn(ctx, name)
+	}
+	return &userdomain.User{ID: "service-1", FirstName: &name, Type: constant.AccountTypeService}, nil
+}
+
 type fakeRefreshTokenService struct {
 	createFn              func(context.Context, *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error)
 	findByTokenFn         func(context.Context, string) (*refreshtoken.RefreshToken, error)
-	revokeByTokenFn       func(context.Context, string) error
+	rotateByTokenFn       func(context.Context, string) error
 	revokeAllUserTokensFn func(context.Context, string) error
+	handleReuseFn         func(context.Context, *refreshtoken.RefreshToken) error
+	listActiveSessionsFn  func(context.Context, string) ([]*refreshtoken.RefreshToken, error)
+	revokeSessionFn       func(context.Context, string, string) error
+	revokeOtherSessionsFn func(context.Context, string, string) error
+	enforceSessionLimitFn func(context.Context, string) error
+	isSessionRevokedFn    func(context.Context, string) (bool, error)
 }
 
 func (f *fakeRefreshTokenService) Create(ctx context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
@@ -124,9 +248,9 @@ func (f *fakeRefreshTokenService) FindByToken(ctx context.Context, token string)
 	return nil, nil
 }
 
-func (f *fakeRefreshTokenService) RevokeByToken(ctx context.Context, token string) error {
-	if f.revokeByTokenFn != nil {
-		return f.revokeByTokenFn(ctx, token)
+func (f *fakeRefreshTokenService) RotateByToken(ctx context.Context, token string) error {
+	if f.rotateByTokenFn != nil {
+		return f.rotateByTokenFn(ctx, token)
 	}
 	return nil
 }
@@ -138,9 +262,444 @@ func (f *fakeRefreshTokenService) RevokeAllUserTokens(ctx context.Context, userI
 	return nil
 }
 
-func newTestRouter(t *testing.T, users *fakeUserService, refreshTokens *fakeRefreshTokenService) (*gin.Engine, *utils.JWTManager) {
+func (f *fakeRefreshTokenService) HandleReuse(ctx context.Context, token *refreshtoken.RefreshToken) error {
+	if f.handleReuseFn != nil {
+		return f.handleReuseFn(ctx, token)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenService) ListActiveSessions(ctx context.Context, userID string) ([]*refreshtoken.RefreshToken, error) {
+	if f.listActiveSessionsFn != nil {
+		return f.listActiveSessionsFn(ctx, userID)
+	}
+	return []*refreshtoken.RefreshToken{}, nil
+}
+
+func (f *fakeRefreshTokenService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if f.revokeSessionFn != nil {
+		return f.revokeSessionFn(ctx, userID, sessionID)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenService) RevokeOtherSessions(ctx context.Context, userID string, keepSessionID string) error {
+	if f.revokeOtherSessionsFn != nil {
+		return f.revokeOtherSessionsFn(ctx, userID, keepSessionID)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenService) EnforceSessionLimit(ctx context.Context, userID string) error {
+	if f.enforceSessionLimitFn != nil {
+		return f.enforceSessionLimitFn(ctx, userID)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenService) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if f.isSessionRevokedFn != nil {
+		return f.isSessionRevokedFn(ctx, sessionID)
+	}
+	return false, nil
+}
+
+type fakeEmailVerificationService struct {
+	sendVerificationFn func(context.Context, *userdomain.User) error
+	resendFn           func(context.Context, string) error
+	verifyFn           func(context.Context, string, string) (*userdomain.User, error)
+}
+
+func (f *fakeEmailVerificationService) SendVerification(ctx context.Context, u *userdomain.User) error {
+	if f.sendVerificationFn != nil {
+		return f.sendVerificationFn(ctx, u)
+	}
+	return nil
+}
+
+func (f *fakeEmailVerificationService) Resend(ctx context.Context, email string) error {
+	if f.resendFn != nil {
+		return f.resendFn(ctx, email)
+	}
+	return nil
+}
+
+func (f *fakeEmailVerificationService) Verify(ctx context.Context, token string, password string) (*userdomain.User, error) {
+	if f.verifyFn != nil {
+		return f.verifyFn(ctx, token, password)
+	}
+	return &userdomain.User{}, nil
+}
+
+type fakePasswordResetService struct {
+	requestResetFn func(context.Context, string) error
+	resetFn        func(context.Context, string, string) error
+}
+
+func (f *fakePasswordResetService) RequestReset(ctx context.Context, email string) error {
+	if f.requestResetFn != nil {
+		return f.requestResetFn(ctx, email)
+	}
+	return nil
+}
+
+func (f *fakePasswordResetService) Reset(ctx context.Context, token string, password string) error {
+	if f.resetFn != nil {
+		return f.resetFn(ctx, token, password)
+	}
+	return nil
+}
+
+type fakeEmailChangeService struct {
+	requestChangeFn func(context.Context, string, string) (bool, error)
+	confirmFn       func(context.Context, string) (*userdomain.User, error)
+}
+
+func (f *fakeEmailChangeService) RequestChange(ctx context.Context, userID string, newEmail string) (bool, error) {
+	if f.requestChangeFn != nil {
+		return f.requestChangeFn(ctx, userID, newEmail)
+	}
+	return true, nil
+}
+
+func (f *fakeEmailChangeService) Confirm(ctx context.Context, token string) (*userdomain.User, error) {
+	if f.confirmFn != nil {
+		return f.confirmFn(ctx, token)
+	}
+	return nil, errors.New("unexpected Confirm call")
+}
+
+type fakeMagicLinkService struct {
+	requestLinkFn func(context.Context, string, string, string) error
+	consumeFn     func(context.Context, string, string) (*userdomain.User, error)
+}
+
+func (f *fakeMagicLinkService) RequestLink(ctx context.Context, email string, deviceID string, clientIP string) error {
+	if f.requestLinkFn != nil {
+		return f.requestLinkFn(ctx, email, deviceID, clientIP)
+	}
+	return nil
+}
+
+func (f *fakeMagicLinkService) Consume(ctx context.Context, token string, deviceID string) (*userdomain.User, error) {
+	if f.consumeFn != nil {
+		return f.consumeFn(ctx, token, deviceID)
+	}
+	return nil, exceptions.ValidationError("The given data was invalid.", nil, nil)
+}
+
+type fakeImpersonationService struct {
+	startFn func(context.Context, impersonation.StartInput) (*impersonation.ImpersonationTokenDTO, error)
+}
+
+func (f *fakeImpersonationService) Start(ctx context.Context, input impersonation.StartInput) (*impersonation.ImpersonationTokenDTO, error) {
+	if f.startFn != nil {
+		return f.startFn(ctx, input)
+	}
+	return nil, exceptions.NotFoundError("User not found", nil, nil)
+}
+
+type recordedSecurityEvent struct {
+	userID    string
+	eventType string
+	details   map[string]string
+	client    audit.Client
+}
+
+type fakeSecurityEventService struct {
+	recorded    []recordedSecurityEvent
+	listForUser func(context.Context, string, pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error)
+}
+
+// Record keeps events like the real service: failed logins and refresh token reuse at once,
+// other events only once the request transaction commits
+func (f *fakeSecurityEventService) Record(ctx context.Context, userID string, eventType string, details map[string]string) {
+	event := recordedSecurityEvent{userID: userID, eventType: eventType, details: details, client: audit.ClientFromContext(ctx)}
+	if eventType == audit.EventLoginFailed || eventType == audit.EventRefreshTokenReuse {
+		f.recorded = append(f.recorded, event)
+		return
+	}
+	utils.AfterCommit(ctx, func() { f.recorded = append(f.recorded, event) })
+}
+
+func (f *fakeSecurityEventService) ListForUser(ctx context.Context, userID string, params pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error) {
+	if f.listForUser != nil {
+		return f.listForUser(ctx, userID, params)
+	}
+	return pagination.NewPage[*securityevent.SecurityEvent](nil, params, nil, false, false), nil
+}
+
+// eventTypes lists the recorded event types in order
+func (f *fakeSecurityEventService) eventTypes() []string {
+	types := make([]string, 0, len(f.recorded))
+	for _, event := range f.recorded {
+		types = append(types, event.eventType)
+	}
+	return types
+}
+
+type fakeTwoFactorService struct {
+	isEnabledFn       func(context.Context, string) (bool, error)
+	enrollFn          func(context.Context, string) (*twofactor.EnrollmentDTO, error)
+	confirmFn         func(context.Context, string, string) (*twofactor.RecoveryCodesDTO, error)
+	disableFn         func(context.Context, string, string, string) error
+	createChallengeFn func(context.Context, string) (*twofactor.ChallengeDTO, error)
+	verifyChallengeFn func(context.Context, string, string) (string, error)
+}
+
+func (f *fakeTwoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	if f.isEnabledFn != nil {
+		return f.isEnabledFn(ctx, userID)
+	}
+	return false, nil
+}
+
+func (f *fakeTwoFactorService) Enroll(ctx context.Context, userID string) (*twofactor.EnrollmentDTO, error) {
+	if f.enrollFn != nil {
+		return f.enrollFn(ctx, userID)
+	}
+	return &twofactor.EnrollmentDTO{}, nil
+}
+
+func (f *fakeTwoFactorService) Confirm(ctx context.Context, userID string, code string) (*twofactor.RecoveryCodesDTO, error) {
+	if f.confirmFn != nil {
+		return f.confirmFn(ctx, userID, code)
+	}
+	return &twofactor.RecoveryCodesDTO{}, nil
+}
+
+func (f *fakeTwoFactorService) Disable(ctx context.Context, userID string, password string, code string) error {
+	if f.disableFn != nil {
+		return f.disableFn(ctx, userID, password, code)
+	}
+	return nil
+}
+
+func (f *fakeTwoFactorService) CreateChallenge(ctx context.Context, userID string) (*twofactor.ChallengeDTO, error) {
+	if f.createChallengeFn != nil {
+		return f.createChallengeFn(ctx, userID)
+	}
+	return &twofactor.ChallengeDTO{TwoFactorRequired: true}, nil
+}
+
+func (f *fakeTwoFactorService) VerifyChallenge(ctx context.Context, challengeToken string, code string) (string, error) {
+	if f.verifyChallengeFn != nil {
+		return f.verifyChallengeFn(ctx, challengeToken, code)
+	}
+	return "", nil
+}
+
+// fakeSocialProvider is an OAuth provider that approves every authorization request
+// It records the PKCE challenge and nonce so Exchange can check them like a real provider
+type fakeSocialProvider struct {
+	identity   oauth.Identity
+	challenges map[string]string // code -> code challenge
+	nonces     map[string]string // code -> nonce
+}
+
+func (p *fakeSocialProvider) Name() string { return p.identity.Provider }
+
+func (p *fakeSocialProvider) AuthCodeURL(_ context.Context, req oauth.AuthorizationRequest) (string, error) {
+	code := "code-" + req.State
+	p.challenges[code] = req.CodeChallenge
+	p.nonces[code] = req.Nonce
+	return "https://provider.example.com/authorize?state=" + url.QueryEscape(req.State) + "&code=" + url.QueryEscape(code), nil
+}
+
+func (p *fakeSocialProvider) Exchange(_ context.Context, req oauth.ExchangeRequest) (*oauth.Identity, error) {
+	if oauth.CodeChallengeS256(req.CodeVerifier) != p.challenges[req.Code] || req.Nonce != p.nonces[req.Code] {
+		return nil, errors.New("invalid_grant")
+	}
+	identity := p.identity
+	return &identity, nil
+}
+
+type fakeLockoutNotifier struct {
+	events chan authsvc.LockoutEvent
+}
+
+func (f *fakeLockoutNotifier) NotifyLockout(_ context.Context, event authsvc.LockoutEvent) error {
+	if f.events != nil {
+		f.events <- event
+	}
+	return nil
+}
+
+// fakePermissionService evaluates a static policy instead of loading role assignments from the database
+type fakePermissionService struct {
+	policy    authz.Policy
+	grantFn   func(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error)
+	revokeFn  func(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error)
+	listFn    func(ctx context.Context) ([]permission.PermissionDTO, error)
+	forRoleFn func(ctx context.Context, role constant.AccountTypeEnum) (*permission.RolePermissionsDTO, error)
+}
+
+// defaultTestPolicy mirrors the seeded role assignments and the ownership rules of the permission module
+func defaultTestPolicy() authz.Policy {
+	return authz.Policy{
+		Grants: map[constant.AccountTypeEnum]map[string]bool{
+			constant.AccountTypeAdmin: {
+				constant.PermissionUsersUpdate:    true,
+				constant.PermissionUsersDelete:    true,
+				constant.PermissionUsersReadEmail: true,
+			},
+			constant.AccountTypeStaff: {
+				constant.PermissionUsersReadEmail: true,
+			},
+		},
+		Rules: map[string][]authz.Rule{
+			constant.PermissionUsersUpdate:    {authz.IsOwner},
+			constant.PermissionUsersDelete:    {authz.IsOwner},
+			constant.PermissionUsersReadEmail: {authz.IsOwner},
+		},
+	}
+}
+
+func (f *fakePermissionService) Can(_ context.Context, principal *authz.Principal, action string, resource authz.Resource) (bool, error) {
+	return f.policy.Allows(principal, action, resource), nil
+}
+
+func (f *fakePermissionService) Authorize(ctx context.Context, principal *authz.Principal, action string, resource authz.Resource) error {
+	if principal == nil {
+		return exceptions.UnauthorizedError("Authentication required", nil, nil)
+	}
+	if allowed, _ := f.Can(ctx, principal, action, resource); !allowed {
+		return exceptions.ForbiddenError("You do not have permission to perform this action", nil, nil)
+	}
+	return nil
+}
+
+func (f *fakePermissionService) ListPermissions(ctx context.Context) ([]permission.PermissionDTO, error) {
+	if f.listFn != nil {
+		return f.listFn(ctx)
+	}
+	return []permission.PermissionDTO{}, nil
+}
+
+func (f *fakePermissionService) ListRolePermissions(ctx context.Context, role constant.AccountTypeEnum) (*permission.RolePermissionsDTO, error) {
+	if f.forRoleFn != nil {
+		return f.forRoleFn(ctx, role)
+	}
+	return &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{}}, nil
+}
+
+func (f *fakePermissionService) GrantPermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+	if f.grantFn != nil {
+		return f.grantFn(ctx, role, name)
+	}
+	return &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{name}}, nil
+}
+
+func (f *fakePermissionService) RevokePermission(ctx context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+	if f.revokeFn != nil {
+		return f.revokeFn(ctx, role, name)
+	}
+	return &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{}}, nil
+}
+
+type fakeAPIKeyService struct {
+	createFn       func(context.Context, string, apikey.CreateAPIKeyRequest) (*apikey.CreatedAPIKeyDTO, error)
+	listFn         func(context.Context, string) ([]apikey.APIKeyDTO, error)
+	revokeFn       func(context.Context, string, string) error
+	authenticateFn func(context.Context, string) (*authz.Principal, error)
+}
+
+func (f *fakeAPIKeyService) Create(ctx context.Context, userID string, req apikey.CreateAPIKeyRequest) (*apikey.CreatedAPIKeyDTO, error) {
+	if f.createFn != nil {
+		return f.createFn(ctx, userID, req)
+	}
+	return &apikey.CreatedAPIKeyDTO{}, nil
+}
+
+func (f *fakeAPIKeyService) List(ctx context.Context, userID string) ([]apikey.APIKeyDTO, error) {
+	if f.listFn != nil {
+		return f.listFn(ctx, userID)
+	}
+	return []apikey.APIKeyDTO{}, nil
+}
+
+func (f *fakeAPIKeyService) Revoke(ctx context.Context, userID string, id string) error {
+	if f.revokeFn != nil {
+		return f.revokeFn(ctx, userID, id)
+	}
+	return nil
+}
+
+func (f *fakeAPIKeyService) Authenticate(ctx context.Context, key string) (*authz.Principal, error) {
+	if f.authenticateFn != nil {
+		return f.authenticateFn(ctx, key)
+	}
+	return nil, exceptions.UnauthorizedError("Invalid API key", nil, nil)
+}
+
+// testServices holds the fakes wired into the test router; nil fields get a default fake
+type testServices struct {
+	users              *fakeUserService
+	refreshTokens      *fakeRefreshTokenService
+	emailVerifications *fakeEmailVerificationService
+	passwordResets     *fakePasswordResetService
+	emailChanges       *fakeEmailChangeService
+	magicLinks         *fakeMagicLinkService
+	twoFactors         *fakeTwoFactorService
+	permissions        *fakePermissionService
+	apiKeys            *fakeAPIKeyService
+	impersonations     *fakeImpersonationService
+	securityEvents     *fakeSecurityEventService
+	socialProviders    []oauth.Provider
+	loginThrottle      authsvc.LoginThrottleOptions
+	lockoutNotifier    authsvc.LockoutNotifier
+	authCookies        utils.AuthCookieOptions
+	jwtManager         *utils.JWTManager
+}
+
+func newTestRouter(t *testing.T, services testServices) (*gin.Engine, *utils.JWTManager) {
 	t.Helper()
 
+	users := services.users
+	if users == nil {
+		users = &fakeUserService{}
+	}
+	refreshTokens := services.refreshTokens
+	if refreshTokens == nil {
+		refreshTokens = &fakeRefreshTokenService{}
+	}
+	emailVerifications := services.emailVerifications
+	if emailVerifications == nil {
+		emailVerifications = &fakeEmailVerificationService{}
+	}
+	passwordResets := services.passwordResets
+	if passwordResets == nil {
+		passwordResets = &fakePasswordResetService{}
+	}
+	emailChanges := services.emailChanges
+	if emailChanges == nil {
+		emailChanges = &fakeEmailChangeService{}
+	}
+	magicLinks := services.magicLinks
+	if magicLinks == nil {
+		magicLinks = &fakeMagicLinkService{}
+	}
+	twoFactors := services.twoFactors
+	if twoFactors == nil {
+		twoFactors = &fakeTwoFactorService{}
+	}
+	permissions := services.permissions
+	if permissions == nil {
+		permissions = &fakePermissionService{policy: defaultTestPolicy()}
+	}
+	apiKeys := services.apiKeys
+	if apiKeys == nil {
+		apiKeys = &fakeAPIKeyService{}
+	}
+	securityEvents := services.securityEvents
+	if securityEvents == nil {
+		securityEvents = &fakeSecurityEventService{}
+	}
+	impersonations := services.impersonations
+	if impersonations == nil {
+		impersonations = &fakeImpersonationService{}
+	}
+
 	gin.SetMode(gin.TestMode)
 
 	logger.Logger = logrus.New()
@@ -163,22 +722,54 @@ func newTestRouter(t *testing.T, users *fakeUserService, refreshTokens *fakeRefr
 		t.Fatalf("create gorm test database: %v", err)
 	}
 
-	jwtManager := utils.NewJWTManager(testJWTSecret, 15*time.Minute, 24*time.Hour)
-	userHandler := userhandler.NewUserHandler(users)
-	authHandler := authhandler.NewAuthHandler(users, jwtManager, refreshTokens)
+	jwtManager := services.jwtManager
+	if jwtManager == nil {
+		jwtManager = utils.NewJWTManager(testJWTSecret, 15*time.Minute, 24*time.Hour)
+	}
+	userHandler := userhandler.NewUserHandler(users, emailChanges, permissions)
+	socialLogins := authsvc.NewSocialLoginService(users, oauth.NewRegistry(services.socialProviders...), infracache.NewMemoryCache(), securityEvents, authsvc.SocialLoginOptions{
+		CallbackURL: "http://localhost:8000/api/auth/oauth/{provider}/callback",
+		StateTTL:    time.Minute,
+	})
+	lockoutNotifier := services.lockoutNotifier
+	if lockoutNotifier == nil {
+		lockoutNotifier = &fakeLockoutNotifier{}
+	}
+	loginThrottle := authsvc.NewLoginThrottleService(infracache.NewMemoryCache(), lockoutNotifier, services.loginThrottle)
+	authHandler := authhandler.NewAuthHandler(users, jwtManager, refreshTokens, emailVerifications, passwordResets, magicLinks, twoFactors, socialLogins, loginThrottle, securityEvents, services.authCookies)
 	healthHandler := healthhandler.NewHealthHandler(db)
+	permissionHandler := permissionhandler.NewPermissionHandler(permissions)
+	apiKeyHandler := apikeyhandler.NewAPIKeyHandler(apiKeys, users)
+	impersonationHandler := impersonationhandler.NewImpersonationHandler(impersonations)
+	securityEventHandler := securityeventhandler.NewSecurityEventHandler(securityEvents)
+	emailChangeHandler := emailchangehandler.NewEmailChangeHandler(emailChanges)
 
 	engine := gin.New()
+	engine.Use(middleware.ClientInfoMiddleware())
+	engine.Use(middleware.SanitizeMiddleware())
+	engine.Use(middleware.CaseConverterMiddleware())
 	engine.Use(exceptions.ErrorHandler())
 
+	deps := &routerDeps{
+		userHandler:          userHandler,
+		authHandler:          authHandler,
+		healthHandler:        healthHandler,
+		permissionHandler:    permissionHandler,
+		apiKeyHandler:        apiKeyHandler,
+		impersonationHandler: impersonationHandler,
+		securityEventHandler: securityEventHandler,
+		emailChangeHandler:   emailChangeHandler,
+		jwtManager:           jwtManager,
+		accessTokens:         authsvc.NewAccessTokenService(users, refreshTokens),
+		apiKeys:              apiKeys,
+		authorizer:           permissions,
+		authCookies:          services.authCookies,
+		db:                   db,
+	}
+	registerWellKnownRoutes(engine, deps)
+
 	api := engine.Group("/api")
-	registerWebRoutes(api, &routerDeps{
-		userHandler:   userHandler,
-		authHandler:   authHandler,
-		healthHandler: healthHandler,
-		jwtManager:    jwtManager,
-		db:            db,
-	})
+	registerWebRoutes(api, deps)
 
 	return engine, jwtManager
 }
@@ -208,44 +799,137 @@ func performJSONRequest(t *testing.T, engine http.Handler, method, path string,
 	return recorder
 }
 
-func assertStatus(t *testing.T, recorder *httptest.ResponseRecorder, want int) {
+func performPatchRequest(t *testing.T, engine http.Handler, path, contentType, body, accessToken string) *httptest.ResponseRecorder {
 	t.Helper()
-	if recorder.Code != want {
-		t.Fatalf("status = %d, want %d; body=%s", recorder.Code, want, recorder.Body.String())
-	}
+
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
 }
 
-func assertSuccessResponse(t *testing.T, recorder *httptest.ResponseRecorder) {
+func performCookieRequest(t *testing.T, engine http.Handler, method, path string, cookies []*http.Cookie, csrfToken string) *httptest.ResponseRecorder {
 	t.Helper()
-	var body struct {
-		Success bool `json:"success"`
-	}
-	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
-		t.Fatalf("decode response: %v; body=%s", err, recorder.Body.String())
+
+	req := httptest.NewRequest(method, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
 	}
-	if !body.Success {
-		t.Fatalf("expected success response; body=%s", recorder.Body.String())
+	if csrfToken != "" {
+		req.Header.Set(utils.CSRFTokenHeader, csrfToken)
 	}
-}
 
-func TestHealthEndpoint(t *testing.T) {
-	engine, _ := newTestRouter(t, &fakeUserService{}, &fakeRefreshTokenService{})
-
-	response := performJSONRequest(t, engine, http.MethodGet, "/api/health", nil, "")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
 
-	assertStatus(t, response, http.StatusOK)
-	assertSuccessResponse(t, response)
+func responseCookie(recorder *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
 }
 
-func TestSignupEndpoint(t *testing.T) {
-	var received userdomain.SignupInput
-	users := &fakeUserService{
-		createUserFn: func(_ context.Context, input userdomain.SignupInput) (*userdomain.User, error) {
+func performAPIKeyRequest(t *testing.T, engine http.Handler, method, path string, body interface{}, apiKey string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var requestBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal request body: %v", err)
+		}
+		requestBody = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, requestBody)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", apiKey)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func assertStatus(t *testing.T, recorder *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if recorder.Code != want {
+		t.Fatalf("status = %d, want %d; body=%s", recorder.Code, want, recorder.Body.String())
+	}
+}
+
+func assertSuccessResponse(t *testing.T, recorder *httptest.ResponseRecorder) {
+	t.Helper()
+	var body struct {
+		Success bool `json:"success"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, recorder.Body.String())
+	}
+	if !body.Success {
+		t.Fatalf("expected success response; body=%s", recorder.Body.String())
+	}
+}
+
+func refreshTokenFromBody(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Data struct {
+			RefreshToken string `json:"refreshToken"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, recorder.Body.String())
+	}
+	return body.Data.RefreshToken
+}
+
+func accessTokenFromBody(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Data struct {
+			AccessToken string `json:"accessToken"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, recorder.Body.String())
+	}
+	return body.Data.AccessToken
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	engine, _ := newTestRouter(t, testServices{})
+
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/health", nil, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+}
+
+func TestSignupEndpoint(t *testing.T) {
+	var received userdomain.SignupInput
+	users := &fakeUserService{
+		createUserFn: func(_ context.Context, input userdomain.SignupInput) (*userdomain.User, error) {
 			received = input
 			return &userdomain.User{ID: "user-1", Email: input.Email}, nil
 		},
 	}
-	engine, _ := newTestRouter(t, users, &fakeRefreshTokenService{})
+	var verificationSentTo string
+	emailVerifications := &fakeEmailVerificationService{
+		sendVerificationFn: func(_ context.Context, u *userdomain.User) error {
+			verificationSentTo = u.ID
+			return nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users, emailVerifications: emailVerifications})
 
 	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/signup", map[string]string{
 		"first_name": "Test",
@@ -258,179 +942,2075 @@ func TestSignupEndpoint(t *testing.T) {
 	if received.Email != "test@example.com" || received.FirstName != "Test" || received.LastName != "User" {
 		t.Fatalf("unexpected signup input: %+v", received)
 	}
-}
-
-func TestLoginEndpoint(t *testing.T) {
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
-	if err != nil {
-		t.Fatalf("hash password: %v", err)
+	if verificationSentTo != "user-1" {
+		t.Fatalf("verification email sent to %q, want user-1", verificationSentTo)
 	}
+}
 
-	users := &fakeUserService{
-		getUserByEmailFn: func(_ context.Context, email string) (*userdomain.User, error) {
-			return &userdomain.User{
-				ID:       "user-1",
-				Email:    email,
-				Password: string(passwordHash),
-				Status:   constant.UserStatusActive,
-			}, nil
-		},
-	}
-	var savedRefreshToken *refreshtoken.RefreshToken
-	refreshTokens := &fakeRefreshTokenService{
-		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
-			savedRefreshToken = token
-			return token, nil
+func TestVerifyEmailEndpoint(t *testing.T) {
+	var receivedToken, receivedPassword string
+	emailVerifications := &fakeEmailVerificationService{
+		verifyFn: func(_ context.Context, token string, password string) (*userdomain.User, error) {
+			receivedToken, receivedPassword = token, password
+			return &userdomain.User{ID: "user-1", Email: "test@example.com", Status: constant.UserStatusActive}, nil
 		},
 	}
-	engine, _ := newTestRouter(t, users, refreshTokens)
+	engine, _ := newTestRouter(t, testServices{emailVerifications: emailVerifications})
 
-	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
-		"email":    "test@example.com",
-		"password": "secret123",
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/verify-email", map[string]string{
+		"token":                 "verification-token",
+		"password":              "secret123",
+		"password_confirmation": "secret123",
 	}, "")
 
 	assertStatus(t, response, http.StatusOK)
 	assertSuccessResponse(t, response)
-	if savedRefreshToken == nil || savedRefreshToken.UserID != "user-1" || savedRefreshToken.Token == "" {
-		t.Fatalf("refresh token was not persisted correctly: %+v", savedRefreshToken)
+	if receivedToken != "verification-token" || receivedPassword != "secret123" {
+		t.Fatalf("unexpected verify input: token=%q password=%q", receivedToken, receivedPassword)
 	}
 }
 
-func TestRefreshEndpoint(t *testing.T) {
-	users := &fakeUserService{}
-	refreshTokens := &fakeRefreshTokenService{}
-	engine, jwtManager := newTestRouter(t, users, refreshTokens)
+func TestVerifyEmailEndpointRejectsMismatchedPasswords(t *testing.T) {
+	verifyCalled := false
+	emailVerifications := &fakeEmailVerificationService{
+		verifyFn: func(context.Context, string, string) (*userdomain.User, error) {
+			verifyCalled = true
+			return &userdomain.User{}, nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{emailVerifications: emailVerifications})
 
-	oldToken, err := jwtManager.GenerateRefreshToken("user-1")
-	if err != nil {
-		t.Fatalf("generate refresh token: %v", err)
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/verify-email", map[string]string{
+		"token":                 "verification-token",
+		"password":              "secret123",
+		"password_confirmation": "different",
+	}, "")
+
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+	if verifyCalled {
+		t.Fatal("verification was attempted with mismatched passwords")
 	}
+}
 
-	revoked := false
-	created := false
-	refreshTokens.findByTokenFn = func(_ context.Context, token string) (*refreshtoken.RefreshToken, error) {
-		if token != oldToken {
-			t.Fatalf("FindByToken token = %q, want generated refresh token", token)
-		}
-		return &refreshtoken.RefreshToken{
-			UserID:    "user-1",
-			Token:     token,
-			ExpiresAt: time.Now().Add(time.Hour),
-		}, nil
+func TestResendVerificationEndpoint(t *testing.T) {
+	var requestedEmail string
+	emailVerifications := &fakeEmailVerificationService{
+		resendFn: func(_ context.Context, email string) error {
+			requestedEmail = email
+			return nil
+		},
 	}
-	refreshTokens.revokeByTokenFn = func(_ context.Context, token string) error {
-		revoked = token == oldToken
-		return nil
+	engine, _ := newTestRouter(t, testServices{emailVerifications: emailVerifications})
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/verify-email/resend", map[string]string{
+		"email": "test@example.com",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if requestedEmail != "test@example.com" {
+		t.Fatalf("resend requested for %q, want test@example.com", requestedEmail)
 	}
-	refreshTokens.createFn = func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
-		created = token.Token != "" && token.UserID == "user-1"
-		return token, nil
+}
+
+func TestForgotPasswordEndpoint(t *testing.T) {
+	var requestedEmail string
+	passwordResets := &fakePasswordResetService{
+		requestResetFn: func(_ context.Context, email string) error {
+			requestedEmail = email
+			return nil
+		},
 	}
+	engine, _ := newTestRouter(t, testServices{passwordResets: passwordResets})
 
-	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/refresh", map[string]string{
-		"refresh_token": oldToken,
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/forgot-password", map[string]string{
+		"email": "unknown@example.com",
 	}, "")
 
 	assertStatus(t, response, http.StatusOK)
 	assertSuccessResponse(t, response)
-	if !revoked || !created {
-		t.Fatalf("refresh rotation incomplete: revoked=%v created=%v", revoked, created)
+	if requestedEmail != "unknown@example.com" {
+		t.Fatalf("reset requested for %q, want unknown@example.com", requestedEmail)
 	}
 }
 
-func TestProtectedUserEndpointRejectsMissingToken(t *testing.T) {
-	updateCalled := false
-	users := &fakeUserService{
-		updateUserFn: func(context.Context, map[string]interface{}, *string, string) (*userdomain.User, error) {
-			updateCalled = true
-			return &userdomain.User{}, nil
+func TestResetPasswordEndpoint(t *testing.T) {
+	var receivedToken, receivedPassword string
+	passwordResets := &fakePasswordResetService{
+		resetFn: func(_ context.Context, token string, password string) error {
+			receivedToken, receivedPassword = token, password
+			return nil
 		},
 	}
-	engine, _ := newTestRouter(t, users, &fakeRefreshTokenService{})
+	engine, _ := newTestRouter(t, testServices{passwordResets: passwordResets})
 
-	response := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{
-		"name": "Updated User",
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/reset-password", map[string]string{
+		"token":                 "reset-token",
+		"password":              "newsecret123",
+		"password_confirmation": "newsecret123",
 	}, "")
 
-	assertStatus(t, response, http.StatusUnauthorized)
-	if updateCalled {
-		t.Fatal("protected handler was called without an access token")
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if receivedToken != "reset-token" || receivedPassword != "newsecret123" {
+		t.Fatalf("unexpected reset input: token=%q password=%q", receivedToken, receivedPassword)
 	}
 }
 
-func TestUserEndpoints(t *testing.T) {
+func TestEmailChangeEndpoints(t *testing.T) {
+	var updates map[string]interface{}
 	users := &fakeUserService{
-		getAllUsersPaginatedFn: func(_ context.Context, page, perPage int) ([]*userdomain.User, int64, error) {
-			if page != 2 || perPage != 5 {
-				t.Fatalf("pagination = (%d, %d), want (2, 5)", page, perPage)
-			}
-			return []*userdomain.User{{ID: "user-1", Email: "test@example.com"}}, 1, nil
-		},
-		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
-			return &userdomain.User{ID: id, Email: "test@example.com"}, nil
+		updateUserFn: func(_ context.Context, fields map[string]interface{}, id string) (*userdomain.User, error) {
+			updates = fields
+			return &userdomain.User{ID: id, Email: "old@example.com"}, nil
 		},
-		updateUserFn: func(_ context.Context, updates map[string]interface{}, _ *string, id string) (*userdomain.User, error) {
-			if id != "user-1" || updates["first_name"] != "Updated User" {
-				t.Fatalf("unexpected update: id=%q updates=%v", id, updates)
+	}
+	var requestedUserID, requestedEmail, confirmedToken string
+	emailChanges := &fakeEmailChangeService{
+		requestChangeFn: func(_ context.Context, userID string, newEmail string) (bool, error) {
+			if newEmail == "taken@example.com" {
+				return false, exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+					{Field: "email", Message: "The email has already been taken."},
+				})
 			}
-			name := "Updated User"
-			return &userdomain.User{ID: id, FirstName: &name, Email: "test@example.com"}, nil
+			requestedUserID, requestedEmail = userID, newEmail
+			return true, nil
 		},
-		deleteUserFn: func(_ context.Context, id string) error {
-			if id != "user-1" {
-				t.Fatalf("delete id = %q, want user-1", id)
-			}
-			return nil
+		confirmFn: func(_ context.Context, token string) (*userdomain.User, error) {
+			confirmedToken = token
+			return &userdomain.User{ID: "user-1", Email: "new@example.com"}, nil
 		},
 	}
-	engine, jwtManager := newTestRouter(t, users, &fakeRefreshTokenService{})
-	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	engine, jwtManager := newTestRouter(t, testServices{users: users, emailChanges: emailChanges})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)))
 	if err != nil {
 		t.Fatalf("generate access token: %v", err)
 	}
 
-	tests := []struct {
-		name   string
-		method string
-		path   string
-		body   interface{}
-		token  string
-	}{
-		{name: "list", method: http.MethodGet, path: "/api/users?page=2&per_page=5"},
-		{name: "get by id", method: http.MethodGet, path: "/api/users/user-1"},
-		{name: "update", method: http.MethodPut, path: "/api/users/user-1", body: map[string]string{"name": "Updated User"}, token: accessToken},
-		{name: "delete", method: http.MethodDelete, path: "/api/users/user-1", token: accessToken},
+	update := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{
+		"name":  "Jane",
+		"email": "new@example.com",
+	}, accessToken)
+
+	assertStatus(t, update, http.StatusOK)
+	if _, ok := updates["email"]; ok || updates["first_name"] != "Jane" {
+		t.Fatalf("email must not be written before confirmation: %+v", updates)
+	}
+	if requestedUserID != "user-1" || requestedEmail != "new@example.com" {
+		t.Fatalf("RequestChange(%q, %q), want user-1, new@example.com", requestedUserID, requestedEmail)
+	}
+	var updateBody struct {
+		Message string             `json:"message"`
+		Data    userdomain.UserDTO `json:"data"`
+	}
+	if err := json.Unmarshal(update.Body.Bytes(), &updateBody); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, update.Body.String())
+	}
+	if updateBody.Data.Email != "old@example.com" || !strings.Contains(updateBody.Message, "confirm the new email") {
+		t.Fatalf("unexpected update response: %s", update.Body.String())
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			response := performJSONRequest(t, engine, test.method, test.path, test.body, test.token)
-			assertStatus(t, response, http.StatusOK)
-			assertSuccessResponse(t, response)
-		})
+	conflict := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{"email": "taken@example.com"}, accessToken)
+	assertStatus(t, conflict, http.StatusUnprocessableEntity)
+
+	missingToken := performJSONRequest(t, engine, http.MethodPost, "/api/auth/email-change/confirm", map[string]string{}, "")
+	assertStatus(t, missingToken, http.StatusUnprocessableEntity)
+
+	confirm := performJSONRequest(t, engine, http.MethodPost, "/api/auth/email-change/confirm", map[string]string{"token": "change-token"}, "")
+
+	assertStatus(t, confirm, http.StatusOK)
+	assertSuccessResponse(t, confirm)
+	if confirmedToken != "change-token" || !strings.Contains(confirm.Body.String(), "new@example.com") {
+		t.Fatalf("unexpected confirmation: token=%q body=%s", confirmedToken, confirm.Body.String())
 	}
 }
 
-func TestLogoutEndpoint(t *testing.T) {
-	var revokedUserID string
-	refreshTokens := &fakeRefreshTokenService{
-		revokeAllUserTokensFn: func(_ context.Context, userID string) error {
-			revokedUserID = userID
+func TestMagicLinkEndpoints(t *testing.T) {
+	var requestedEmail, requestedDevice, consumedToken, consumedDevice string
+	magicLinks := &fakeMagicLinkService{
+		requestLinkFn: func(_ context.Context, email string, deviceID string, _ string) error {
+			requestedEmail, requestedDevice = email, deviceID
 			return nil
 		},
+		consumeFn: func(_ context.Context, token string, deviceID string) (*userdomain.User, error) {
+			consumedToken, consumedDevice = token, deviceID
+			return &userdomain.User{ID: "user-1", Email: "test@example.com", Status: constant.UserStatusActive}, nil
+		},
 	}
-	engine, jwtManager := newTestRouter(t, &fakeUserService{}, refreshTokens)
-	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	var savedRefreshToken *refreshtoken.RefreshToken
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			savedRefreshToken = token
+			return token, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{magicLinks: magicLinks, refreshTokens: refreshTokens})
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/magic-link", map[string]string{
+		"email":     "test@example.com",
+		"device_id": "device-0123456789abcdef",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if requestedEmail != "test@example.com" || requestedDevice != "device-0123456789abcdef" {
+		t.Fatalf("unexpected link request: email=%q device=%q", requestedEmail, requestedDevice)
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/auth/magic-link", map[string]string{
+		"email":     "test@example.com",
+		"device_id": "short",
+	}, "")
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/auth/magic-link/consume", map[string]string{
+		"token":       "magic-token",
+		"device_id":   "device-0123456789abcdef",
+		"device_name": "Phone",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if consumedToken != "magic-token" || consumedDevice != "device-0123456789abcdef" {
+		t.Fatalf("unexpected consume input: token=%q device=%q", consumedToken, consumedDevice)
+	}
+	if savedRefreshToken == nil || savedRefreshToken.UserID != "user-1" || savedRefreshToken.DeviceName == nil || *savedRefreshToken.DeviceName != "Phone" {
+		t.Fatalf("session was not started for the link's user: %+v", savedRefreshToken)
+	}
+	if claims, err := jwtManager.ValidateToken(accessTokenFromBody(t, response)); err != nil || claims.UserID != "user-1" {
+		t.Fatalf("access token claims = %+v (err %v), want user-1", claims, err)
+	}
+}
+
+func TestLoginEndpoint(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
 	if err != nil {
-		t.Fatalf("generate access token: %v", err)
+		t.Fatalf("hash password: %v", err)
 	}
 
-	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/logout", nil, accessToken)
+	users := &fakeUserService{
+		getUserByEmailFn: func(_ context.Context, email string) (*userdomain.User, error) {
+			return &userdomain.User{
+				ID:       "user-1",
+				Email:    email,
+				Password: string(passwordHash),
+				Status:   constant.UserStatusActive,
+				Type:     constant.AccountTypeStaff,
+			}, nil
+		},
+	}
+	var signedInUserID string
+	users.recordSignInFn = func(_ context.Context, id string) error {
+		signedInUserID = id
+		return nil
+	}
+	var savedRefreshToken *refreshtoken.RefreshToken
+	var limitedUserID string
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			savedRefreshToken = token
+			return token, nil
+		},
+		enforceSessionLimitFn: func(_ context.Context, userID string) error {
+			limitedUserID = userID
+			return nil
+		},
+	}
+	securityEvents := &fakeSecurityEventService{}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens, securityEvents: securityEvents})
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":       "test@example.com",
+		"password":    "secret123",
+		"device_name": "Work laptop",
+	}, "")
 
 	assertStatus(t, response, http.StatusOK)
 	assertSuccessResponse(t, response)
-	if revokedUserID != "user-1" {
-		t.Fatalf("revoked user id = %q, want user-1", revokedUserID)
+	if savedRefreshToken == nil || savedRefreshToken.UserID != "user-1" || savedRefreshToken.TokenHash != utils.HashToken(refreshTokenFromBody(t, response)) {
+		t.Fatalf("refresh token was not persisted correctly: %+v", savedRefreshToken)
+	}
+	if savedRefreshToken.FamilyID == "" || savedRefreshToken.DeviceName == nil || *savedRefreshToken.DeviceName != "Work laptop" ||
+		savedRefreshToken.ClientIP == nil || savedRefreshToken.LastUsedAt == nil {
+		t.Fatalf("session metadata was not persisted: %+v", savedRefreshToken)
+	}
+	if limitedUserID != "user-1" {
+		t.Fatalf("session limit enforced for %q, want user-1", limitedUserID)
+	}
+	if signedInUserID != "user-1" {
+		t.Fatalf("sign-in recorded for %q, want user-1", signedInUserID)
+	}
+	if len(securityEvents.recorded) != 1 || securityEvents.recorded[0].eventType != audit.EventLoginSucceeded ||
+		securityEvents.recorded[0].userID != "user-1" || securityEvents.recorded[0].client.IP == "" {
+		t.Fatalf("unexpected security events: %+v", securityEvents.recorded)
+	}
+	claims, err := jwtManager.ValidateToken(accessTokenFromBody(t, response))
+	if err != nil || claims.Role != string(constant.AccountTypeStaff) {
+		t.Fatalf("access token role = %+v (err %v), want staff", claims, err)
+	}
+}
+
+func TestLoginEndpointDoesNotRevealUnknownAccounts(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	users := &fakeUserService{
+		getUserByEmailFn: func(_ context.Context, email string) (*userdomain.User, error) {
+			if email != "known@example.com" {
+				return nil, nil
+			}
+			return &userdomain.User{ID: "user-1", Email: email, Password: string(passwordHash), Status: constant.UserStatusInactive}, nil
+		},
+	}
+	securityEvents := &fakeSecurityEventService{}
+	engine, _ := newTestRouter(t, testServices{users: users, securityEvents: securityEvents})
+
+	unknown := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    "unknown@example.com",
+		"password": "secret123",
+	}, "")
+	wrongPassword := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    "known@example.com",
+		"password": "wrong-password",
+	}, "")
+
+	assertStatus(t, unknown, http.StatusUnauthorized)
+	assertStatus(t, wrongPassword, http.StatusUnauthorized)
+	if unknown.Body.String() != wrongPassword.Body.String() {
+		t.Fatalf("unknown account and wrong password responses differ:\n%s\n%s", unknown.Body.String(), wrongPassword.Body.String())
+	}
+	if len(securityEvents.recorded) != 1 || securityEvents.recorded[0].eventType != audit.EventLoginFailed ||
+		securityEvents.recorded[0].userID != "user-1" || securityEvents.recorded[0].details["reason"] != "invalid_password" {
+		t.Fatalf("unexpected security events: %+v", securityEvents.recorded)
+	}
+}
+
+func TestLoginEndpointLocksOutAfterRepeatedFailures(t *testing.T) {
+	notifier := &fakeLockoutNotifier{events: make(chan authsvc.LockoutEvent, 1)}
+	engine, _ := newTestRouter(t, testServices{
+		loginThrottle: authsvc.LoginThrottleOptions{
+			MaxAttempts:     3,
+			IPMaxAttempts:   100,
+			Window:          time.Minute,
+			LockoutDuration: time.Minute,
+		},
+		lockoutNotifier: notifier,
+	})
+
+	attempt := func(email string) *httptest.ResponseRecorder {
+		return performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+			"email":    email,
+			"password": "guess-123",
+		}, "")
+	}
+
+	for i := 0; i < 3; i++ {
+		assertStatus(t, attempt("victim@example.com"), http.StatusUnauthorized)
+	}
+
+	// Locked out even though no account exists for the email
+	locked := attempt("VICTIM@example.com")
+	assertStatus(t, locked, http.StatusTooManyRequests)
+
+	select {
+	case event := <-notifier.events:
+		if event.Email != "victim@example.com" || event.Attempts != 3 {
+			t.Fatalf("unexpected lockout event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("lockout notification was not sent")
+	}
+
+	// Other emails from the same IP are still allowed below the IP threshold
+	assertStatus(t, attempt("someone-else@example.com"), http.StatusUnauthorized)
+}
+
+func TestLoginEndpointBacksOffBetweenFailures(t *testing.T) {
+	engine, _ := newTestRouter(t, testServices{
+		loginThrottle: authsvc.LoginThrottleOptions{
+			Window:      time.Minute,
+			BackoffBase: time.Minute,
+			BackoffMax:  time.Hour,
+		},
+	})
+
+	attempt := func() *httptest.ResponseRecorder {
+		return performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+			"email":    "victim@example.com",
+			"password": "guess-123",
+		}, "")
+	}
+
+	assertStatus(t, attempt(), http.StatusUnauthorized)
+	assertStatus(t, attempt(), http.StatusUnauthorized)
+	assertStatus(t, attempt(), http.StatusTooManyRequests)
+}
+
+func TestLoginEndpointRequiresSecondFactor(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	users := &fakeUserService{
+		getUserByEmailFn: func(_ context.Context, email string) (*userdomain.User, error) {
+			return &userdomain.User{ID: "user-1", Email: email, Password: string(passwordHash), Status: constant.UserStatusActive}, nil
+		},
+	}
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			t.Fatalf("no session may start before the second factor is verified")
+			return token, nil
+		},
+	}
+	twoFactors := &fakeTwoFactorService{
+		isEnabledFn: func(_ context.Context, userID string) (bool, error) {
+			return userID == "user-1", nil
+		},
+		createChallengeFn: func(_ context.Context, userID string) (*twofactor.ChallengeDTO, error) {
+			return &twofactor.ChallengeDTO{TwoFactorRequired: true, ChallengeToken: "challenge-for-" + userID, ExpiresIn: 300}, nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens, twoFactors: twoFactors})
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    "test@example.com",
+		"password": "secret123",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data["twoFactorRequired"] != true || body.Data["challengeToken"] != "challenge-for-user-1" {
+		t.Fatalf("expected a two-factor challenge, got %s", response.Body.String())
+	}
+	if _, ok := body.Data["accessToken"]; ok {
+		t.Fatalf("tokens must not be issued before the second factor: %s", response.Body.String())
+	}
+}
+
+func TestVerifyTwoFactorEndpoint(t *testing.T) {
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Status: constant.UserStatusActive}, nil
+		},
+	}
+	var savedRefreshToken *refreshtoken.RefreshToken
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			savedRefreshToken = token
+			return token, nil
+		},
+	}
+	twoFactors := &fakeTwoFactorService{
+		verifyChallengeFn: func(_ context.Context, challengeToken string, code string) (string, error) {
+			if challengeToken != "challenge" || code != "123456" {
+				return "", exceptions.ValidationError("The given data was invalid.", nil, nil)
+			}
+			return "user-1", nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens, twoFactors: twoFactors})
+
+	rejected := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/verify", map[string]string{
+		"challenge_token": "challenge",
+		"code":            "000000",
+	}, "")
+	assertStatus(t, rejected, http.StatusUnprocessableEntity)
+	if savedRefreshToken != nil {
+		t.Fatalf("a wrong code must not start a session")
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/verify", map[string]string{
+		"challenge_token": "challenge",
+		"code":            "123456",
+		"device_name":     "Phone",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if savedRefreshToken == nil || savedRefreshToken.UserID != "user-1" || savedRefreshToken.TokenHash != utils.HashToken(refreshTokenFromBody(t, response)) {
+		t.Fatalf("refresh token was not persisted correctly: %+v", savedRefreshToken)
+	}
+	if savedRefreshToken.DeviceName == nil || *savedRefreshToken.DeviceName != "Phone" {
+		t.Fatalf("device name was not persisted: %+v", savedRefreshToken)
+	}
+}
+
+func TestTwoFactorEnrollmentEndpoints(t *testing.T) {
+	var confirmed, disabled string
+	twoFactors := &fakeTwoFactorService{
+		enrollFn: func(_ context.Context, userID string) (*twofactor.EnrollmentDTO, error) {
+			return &twofactor.EnrollmentDTO{Secret: "SECRET", OTPAuthURI: "otpauth://totp/" + userID}, nil
+		},
+		confirmFn: func(_ context.Context, userID string, code string) (*twofactor.RecoveryCodesDTO, error) {
+			confirmed = userID + ":" + code
+			return &twofactor.RecoveryCodesDTO{RecoveryCodes: []string{"AAAAA-BBBBB"}}, nil
+		},
+		disableFn: func(_ context.Context, userID string, password string, code string) error {
+			disabled = userID + ":" + password + ":" + code
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{twoFactors: twoFactors})
+
+	unauthenticated := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/enroll", nil, "")
+	assertStatus(t, unauthenticated, http.StatusUnauthorized)
+
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	enroll := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/enroll", nil, accessToken)
+	assertStatus(t, enroll, http.StatusOK)
+	assertSuccessResponse(t, enroll)
+
+	confirm := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/confirm", map[string]string{"code": "123456"}, accessToken)
+	assertStatus(t, confirm, http.StatusOK)
+	if confirmed != "user-1:123456" {
+		t.Fatalf("confirm called with %q", confirmed)
+	}
+
+	missingPassword := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/disable", map[string]string{"code": "123456"}, accessToken)
+	assertStatus(t, missingPassword, http.StatusUnprocessableEntity)
+
+	disable := performJSONRequest(t, engine, http.MethodPost, "/api/auth/2fa/disable", map[string]string{
+		"password": "secret123",
+		"code":     "654321",
+	}, accessToken)
+	assertStatus(t, disable, http.StatusOK)
+	if disabled != "user-1:secret123:654321" {
+		t.Fatalf("disable called with %q", disabled)
+	}
+}
+
+func TestSocialLoginFlow(t *testing.T) {
+	provider := &fakeSocialProvider{
+		identity:   oauth.Identity{Provider: "stub", Subject: "42", Email: "jane@example.com", EmailVerified: true, FirstName: "Jane", LastName: "Doe"},
+		challenges: map[string]string{},
+		nonces:     map[string]string{},
+	}
+	var created userdomain.SocialSignupInput
+	users := &fakeUserService{
+		createSocialUserFn: func(_ context.Context, input userdomain.SocialSignupInput) (*userdomain.User, error) {
+			created = input
+			return &userdomain.User{ID: "user-1", Email: input.Email, Status: constant.UserStatusActive}, nil
+		},
+	}
+	var savedRefreshToken *refreshtoken.RefreshToken
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			savedRefreshToken = token
+			return token, nil
+		},
+	}
+	securityEvents := &fakeSecurityEventService{}
+	engine, _ := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens, securityEvents: securityEvents, socialProviders: []oauth.Provider{provider}})
+
+	unknown := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/unknown", nil, "")
+	assertStatus(t, unknown, http.StatusNotFound)
+
+	redirect := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub", nil, "")
+	assertStatus(t, redirect, http.StatusFound)
+	location, err := url.Parse(redirect.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect location: %v", err)
+	}
+	callbackPath := "/api/auth/oauth/stub/callback?code=" + url.QueryEscape(location.Query().Get("code")) + "&state=" + url.QueryEscape(location.Query().Get("state"))
+
+	forgedState := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub/callback?code=x&state=forged", nil, "")
+	assertStatus(t, forgedState, http.StatusUnauthorized)
+
+	response := performJSONRequest(t, engine, http.MethodGet, callbackPath, nil, "")
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if created.Email != "jane@example.com" || created.SocialProvider != "stub" || created.SocialProviderID != "42" {
+		t.Fatalf("user created with %+v", created)
+	}
+	if savedRefreshToken == nil || savedRefreshToken.TokenHash != utils.HashToken(refreshTokenFromBody(t, response)) {
+		t.Fatalf("refresh token was not persisted correctly: %+v", savedRefreshToken)
+	}
+	if len(securityEvents.recorded) == 0 || securityEvents.recorded[0].eventType != audit.EventSocialSignup || securityEvents.recorded[0].details["provider"] != "stub" {
+		t.Fatalf("recorded events %v, want social_signup first", securityEvents.eventTypes())
+	}
+
+	replayed := performJSONRequest(t, engine, http.MethodGet, callbackPath, nil, "")
+	assertStatus(t, replayed, http.StatusUnauthorized)
+
+	// A sign-up whose session cannot be issued rolls back, and so does its event
+	securityEvents.recorded = nil
+	provider.identity.Subject = "43"
+	refreshTokens.createFn = func(context.Context, *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+		return nil, errors.New("database unavailable")
+	}
+	redirect = performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub", nil, "")
+	location, _ = url.Parse(redirect.Header().Get("Location"))
+	failed := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub/callback?code="+url.QueryEscape(location.Query().Get("code"))+"&state="+url.QueryEscape(location.Query().Get("state")), nil, "")
+	assertStatus(t, failed, http.StatusInternalServerError)
+	if len(securityEvents.recorded) != 0 {
+		t.Fatalf("recorded events %v for a rolled back sign-up", securityEvents.eventTypes())
+	}
+}
+
+func TestSocialLoginLinksExistingAccount(t *testing.T) {
+	provider := &fakeSocialProvider{
+		identity:   oauth.Identity{Provider: "stub", Subject: "42", Email: "jane@example.com", EmailVerified: true},
+		challenges: map[string]string{},
+		nonces:     map[string]string{},
+	}
+	var linked string
+	users := &fakeUserService{
+		getUserByEmailFn: func(_ context.Context, email string) (*userdomain.User, error) {
+			return &userdomain.User{ID: "user-1", Email: email, Status: constant.UserStatusActive}, nil
+		},
+		createSocialUserFn: func(context.Context, userdomain.SocialSignupInput) (*userdomain.User, error) {
+			t.Fatalf("an existing account must be linked, not duplicated")
+			return nil, nil
+		},
+		linkSocialProviderFn: func(_ context.Context, id string, provider string, providerID string) error {
+			linked = id + ":" + provider + ":" + providerID
+			return nil
+		},
+	}
+	securityEvents := &fakeSecurityEventService{}
+	engine, _ := newTestRouter(t, testServices{users: users, securityEvents: securityEvents, socialProviders: []oauth.Provider{provider}})
+
+	redirect := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub", nil, "")
+	location, _ := url.Parse(redirect.Header().Get("Location"))
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub/callback?code="+url.QueryEscape(location.Query().Get("code"))+"&state="+url.QueryEscape(location.Query().Get("state")), nil, "")
+
+	assertStatus(t, response, http.StatusOK)
+	if linked != "user-1:stub:42" {
+		t.Fatalf("linked %q, want user-1:stub:42", linked)
+	}
+	if len(securityEvents.recorded) == 0 || securityEvents.recorded[0].eventType != audit.EventSocialProviderLinked {
+		t.Fatalf("recorded events %v, want social_provider_linked first", securityEvents.eventTypes())
+	}
+
+	// Unverified provider emails must not take over accounts
+	provider.identity.Subject = "43"
+	provider.identity.EmailVerified = false
+	redirect = performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub", nil, "")
+	location, _ = url.Parse(redirect.Header().Get("Location"))
+	unverified := performJSONRequest(t, engine, http.MethodGet, "/api/auth/oauth/stub/callback?code="+url.QueryEscape(location.Query().Get("code"))+"&state="+url.QueryEscape(location.Query().Get("state")), nil, "")
+	assertStatus(t, unverified, http.StatusForbidden)
+}
+
+func TestRefreshEndpoint(t *testing.T) {
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Status: constant.UserStatusActive, TokenVersion: 3}, nil
+		},
+	}
+	refreshTokens := &fakeRefreshTokenService{}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens})
+
+	oldToken, err := jwtManager.GenerateRefreshToken("user-1")
+	if err != nil {
+		t.Fatalf("generate refresh token: %v", err)
+	}
+
+	revoked := false
+	created := false
+	refreshTokens.findByTokenFn = func(_ context.Context, token string) (*refreshtoken.RefreshToken, error) {
+		if token != oldToken {
+			t.Fatalf("FindByToken token = %q, want generated refresh token", token)
+		}
+		return &refreshtoken.RefreshToken{
+			ID:        "token-1",
+			UserID:    "user-1",
+			FamilyID:  "family-1",
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}
+	refreshTokens.rotateByTokenFn = func(_ context.Context, token string) error {
+		revoked = token == oldToken
+		return nil
+	}
+	refreshTokens.createFn = func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+		created = token.TokenHash != "" && token.UserID == "user-1" &&
+			token.FamilyID == "family-1" && token.ParentID != nil && *token.ParentID == "token-1"
+		return token, nil
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/refresh", map[string]string{
+		"refresh_token": oldToken,
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if !revoked || !created {
+		t.Fatalf("refresh rotation incomplete: revoked=%v created=%v", revoked, created)
+	}
+
+	var body struct {
+		Data struct {
+			AccessToken string `json:"accessToken"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	claims, err := jwtManager.ValidateToken(body.Data.AccessToken)
+	if err != nil {
+		t.Fatalf("validate access token: %v", err)
+	}
+	if claims.TokenVersion != 3 || claims.SessionID != "family-1" {
+		t.Fatalf("access token claims = ver %d sid %q, want ver 3 sid family-1", claims.TokenVersion, claims.SessionID)
+	}
+}
+
+func TestCookieAuthMode(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	account := &userdomain.User{ID: "user-1", Email: "test@example.com", Password: string(passwordHash), Status: constant.UserStatusActive}
+	users := &fakeUserService{
+		getUserByEmailFn: func(context.Context, string) (*userdomain.User, error) {
+			return account, nil
+		},
+		getUserByIDFn: func(context.Context, string) (*userdomain.User, error) {
+			return account, nil
+		},
+	}
+	sessions := map[string]*refreshtoken.RefreshToken{}
+	refreshTokens := &fakeRefreshTokenService{
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			token.ID = fmt.Sprintf("token-%d", len(sessions)+1)
+			sessions[token.TokenHash] = token
+			return token, nil
+		},
+		findByTokenFn: func(_ context.Context, token string) (*refreshtoken.RefreshToken, error) {
+			return sessions[utils.HashToken(token)], nil
+		},
+		rotateByTokenFn: func(_ context.Context, token string) error {
+			sessions[utils.HashToken(token)].Revoked = true
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{
+		users:         users,
+		refreshTokens: refreshTokens,
+		authCookies:   utils.AuthCookieOptions{Enabled: true, Secure: true, SameSite: http.SameSiteStrictMode},
+	})
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    "test@example.com",
+		"password": "secret123",
+	}, "")
+
+	assertStatus(t, response, http.StatusOK)
+	if refreshTokenFromBody(t, response) != "" {
+		t.Fatalf("refresh token must not be in the body in cookie mode: %s", response.Body.String())
+	}
+	refreshCookie := responseCookie(response, utils.RefreshTokenCookie)
+	csrfCookie := responseCookie(response, utils.CSRFTokenCookie)
+	if refreshCookie == nil || !refreshCookie.HttpOnly || !refreshCookie.Secure || refreshCookie.SameSite != http.SameSiteStrictMode || refreshCookie.Path != utils.AuthCookiePath {
+		t.Fatalf("refresh cookie = %+v, want an HttpOnly, Secure, SameSite=Strict cookie scoped to /api/auth", refreshCookie)
+	}
+	if csrfCookie == nil || csrfCookie.HttpOnly || csrfCookie.Value == "" || !strings.Contains(response.Body.String(), csrfCookie.Value) {
+		t.Fatalf("csrf cookie = %+v, want a script-readable token echoed in the body", csrfCookie)
+	}
+	if sessions[utils.HashToken(refreshCookie.Value)] == nil {
+		t.Fatal("the refresh token cookie does not hold the persisted refresh token")
+	}
+
+	cookies := []*http.Cookie{refreshCookie, csrfCookie}
+
+	// The cookies alone are not enough, so a cross-site form cannot refresh the session
+	response = performCookieRequest(t, engine, http.MethodPost, "/api/auth/refresh", cookies, "")
+	assertStatus(t, response, http.StatusForbidden)
+	response = performCookieRequest(t, engine, http.MethodPost, "/api/auth/refresh", cookies, "forged-token")
+	assertStatus(t, response, http.StatusForbidden)
+
+	response = performCookieRequest(t, engine, http.MethodPost, "/api/auth/refresh", cookies, csrfCookie.Value)
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if refreshTokenFromBody(t, response) != "" {
+		t.Fatalf("refresh token must not be in the body in cookie mode: %s", response.Body.String())
+	}
+	if !sessions[utils.HashToken(refreshCookie.Value)].Revoked {
+		t.Fatal("the refresh token cookie was not rotated")
+	}
+	rotated := responseCookie(response, utils.RefreshTokenCookie)
+	if rotated == nil || rotated.Value == refreshCookie.Value || sessions[utils.HashToken(rotated.Value)] == nil {
+		t.Fatalf("rotated refresh cookie = %+v, want the new refresh token", rotated)
+	}
+
+	accessToken := accessTokenFromBody(t, response)
+	if _, err := jwtManager.ValidateToken(accessToken); err != nil {
+		t.Fatalf("validate access token: %v", err)
+	}
+
+	// Bearer requests without the cookie are not subject to the CSRF check
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/auth/logout", nil, accessToken)
+	assertStatus(t, response, http.StatusOK)
+	if cleared := responseCookie(response, utils.RefreshTokenCookie); cleared == nil || cleared.MaxAge >= 0 {
+		t.Fatalf("logout did not clear the refresh cookie: %+v", cleared)
+	}
+}
+
+func TestRefreshEndpointRejectsBannedUser(t *testing.T) {
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Status: constant.UserStatusBanned}, nil
+		},
+	}
+	created := false
+	refreshTokens := &fakeRefreshTokenService{
+		findByTokenFn: func(_ context.Context, token string) (*refreshtoken.RefreshToken, error) {
+			return &refreshtoken.RefreshToken{
+				ID:        "token-1",
+				UserID:    "user-1",
+				FamilyID:  "family-1",
+				TokenHash: utils.HashToken(token),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}, nil
+		},
+		createFn: func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+			created = true
+			return token, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens})
+
+	refreshToken, err := jwtManager.GenerateRefreshToken("user-1")
+	if err != nil {
+		t.Fatalf("generate refresh token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/refresh", map[string]string{
+		"refresh_token": refreshToken,
+	}, "")
+
+	assertStatus(t, response, http.StatusUnauthorized)
+	if created {
+		t.Fatal("a new refresh token was issued for a banned user")
+	}
+}
+
+func TestRefreshEndpointRevokesFamilyOnReuse(t *testing.T) {
+	refreshTokens := &fakeRefreshTokenService{}
+	engine, jwtManager := newTestRouter(t, testServices{refreshTokens: refreshTokens})
+
+	replayedToken, err := jwtManager.GenerateRefreshToken("user-1")
+	if err != nil {
+		t.Fatalf("generate refresh token: %v", err)
+	}
+
+	var revokedFamily string
+	created := false
+	refreshTokens.findByTokenFn = func(_ context.Context, token string) (*refreshtoken.RefreshToken, error) {
+		revokedAt := time.Now().Add(-time.Minute)
+		return &refreshtoken.RefreshToken{
+			ID:        "token-1",
+			UserID:    "user-1",
+			FamilyID:  "family-1",
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(time.Hour),
+			Revoked:   true,
+			RevokedAt: &revokedAt,
+		}, nil
+	}
+	refreshTokens.handleReuseFn = func(_ context.Context, token *refreshtoken.RefreshToken) error {
+		revokedFamily = token.FamilyID
+		return nil
+	}
+	refreshTokens.createFn = func(_ context.Context, token *refreshtoken.RefreshToken) (*refreshtoken.RefreshToken, error) {
+		created = true
+		return token, nil
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/refresh", map[string]string{
+		"refresh_token": replayedToken,
+	}, "")
+
+	assertStatus(t, response, http.StatusUnauthorized)
+	if revokedFamily != "family-1" {
+		t.Fatalf("revoked family = %q, want family-1", revokedFamily)
+	}
+	if created {
+		t.Fatal("a new refresh token was issued for a replayed token")
+	}
+}
+
+func TestProtectedUserEndpointRejectsMissingToken(t *testing.T) {
+	updateCalled := false
+	users := &fakeUserService{
+		updateUserFn: func(context.Context, map[string]interface{}, string) (*userdomain.User, error) {
+			updateCalled = true
+			return &userdomain.User{}, nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users})
+
+	response := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{
+		"name": "Updated User",
+	}, "")
+
+	assertStatus(t, response, http.StatusUnauthorized)
+	if updateCalled {
+		t.Fatal("protected handler was called without an access token")
+	}
+}
+
+func TestUserEndpoints(t *testing.T) {
+	users := &fakeUserService{
+		getAllUsersPaginatedFn: func(_ context.Context, _ queryspec.Spec, params pagination.Params) (pagination.Page[*userdomain.User], error) {
+			if params.Page != 2 || params.PerPage != 5 {
+				t.Fatalf("pagination = (%d, %d), want (2, 5)", params.Page, params.PerPage)
+			}
+			total := int64(1)
+			return pagination.NewPage([]*userdomain.User{{ID: "user-1", Email: "test@example.com"}}, params, &total, false, false), nil
+		},
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "test@example.com"}, nil
+		},
+		updateUserFn: func(_ context.Context, updates map[string]interface{}, id string) (*userdomain.User, error) {
+			if id != "user-1" || updates["first_name"] != "Updated User" {
+				t.Fatalf("unexpected update: id=%q updates=%v", id, updates)
+			}
+			name := "Updated User"
+			return &userdomain.User{ID: id, FirstName: &name, Email: "test@example.com"}, nil
+		},
+		deleteUserFn: func(_ context.Context, id string) error {
+			if id != "user-1" {
+				t.Fatalf("delete id = %q, want user-1", id)
+			}
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		token  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/users?page=2&per_page=5"},
+		{name: "get by id", method: http.MethodGet, path: "/api/users/user-1"},
+		{name: "update", method: http.MethodPut, path: "/api/users/user-1", body: map[string]string{"name": "Updated User"}, token: accessToken},
+		{name: "delete", method: http.MethodDelete, path: "/api/users/user-1", token: accessToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := performJSONRequest(t, engine, test.method, test.path, test.body, test.token)
+			assertStatus(t, response, http.StatusOK)
+			assertSuccessResponse(t, response)
+		})
+	}
+}
+
+func TestListUsersQuery(t *testing.T) {
+	listCalls := 0
+	users := &fakeUserService{
+		getAllUsersPaginatedFn: func(_ context.Context, _ queryspec.Spec, params pagination.Params) (pagination.Page[*userdomain.User], error) {
+			listCalls++
+			return pagination.NewPage([]*userdomain.User{{ID: "user-1", Email: "test@example.com"}}, params, nil, false, false), nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+	staffToken, err := jwtManager.GenerateAccessToken("staff-1", utils.WithRole(string(constant.AccountTypeStaff)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	filtered := performJSONRequest(t, engine, http.MethodGet, "/api/users?filter[status]=active&filter[created_at][gte]=2026-01-01&sort=-created_at&q=smith", nil, "")
+	assertStatus(t, filtered, http.StatusOK)
+
+	unknown := performJSONRequest(t, engine, http.MethodGet, "/api/users?filter[password]=secret", nil, "")
+	assertStatus(t, unknown, http.StatusUnprocessableEntity)
+	if !strings.Contains(unknown.Body.String(), "filter[password]") {
+		t.Fatalf("expected an error on filter[password]; body=%s", unknown.Body.String())
+	}
+
+	// Sorting on email would let callers who cannot read emails infer them
+	anonymousEmailSort := performJSONRequest(t, engine, http.MethodGet, "/api/users?sort=email", nil, "")
+	assertStatus(t, anonymousEmailSort, http.StatusUnprocessableEntity)
+	staffEmailSort := performJSONRequest(t, engine, http.MethodGet, "/api/users?sort=email", nil, staffToken)
+	assertStatus(t, staffEmailSort, http.StatusOK)
+
+	if listCalls != 2 {
+		t.Fatalf("list called %d times, want 2", listCalls)
+	}
+}
+
+func TestListUsersCursorAndCountModes(t *testing.T) {
+	var gotCursor *pagination.Cursor
+	var gotLimit int
+	var gotDesc bool
+	var gotCount pagination.CountMode
+	next := pagination.EncodeCursor(pagination.Cursor{ID: "user-2"})
+	users := &fakeUserService{
+		getAllUsersByCursorFn: func(_ context.Context, _ queryspec.Spec, params pagination.CursorParams, desc bool) (pagination.CursorResult[*userdomain.User], error) {
+			gotCursor, gotLimit, gotDesc = params.Cursor, params.Limit, desc
+			return pagination.CursorResult[*userdomain.User]{
+				Items:      []*userdomain.User{{ID: "user-1"}, {ID: "user-2"}},
+				NextCursor: &next,
+			}, nil
+		},
+		getAllUsersPaginatedFn: func(_ context.Context, _ queryspec.Spec, params pagination.Params) (pagination.Page[*userdomain.User], error) {
+			gotCount = params.Count
+			return pagination.NewPage([]*userdomain.User{{ID: "user-1"}}, params, nil, false, true), nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users})
+
+	firstPage := performJSONRequest(t, engine, http.MethodGet, "/api/users?limit=2&sort=-created_at", nil, "")
+	assertStatus(t, firstPage, http.StatusOK)
+	var cursorBody struct {
+		Data struct {
+			Users []userdomain.UserDTO  `json:"users"`
+			Meta  pagination.CursorMeta `json:"meta"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(firstPage.Body.Bytes(), &cursorBody); err != nil {
+		t.Fatalf("decode cursor page: %v", err)
+	}
+	if gotCursor != nil || gotLimit != 2 || !gotDesc {
+		t.Fatalf("cursor query = (%v, %d, %v), want (nil, 2, true)", gotCursor, gotLimit, gotDesc)
+	}
+	if len(cursorBody.Data.Users) != 2 || cursorBody.Data.Meta.NextCursor == nil || *cursorBody.Data.Meta.NextCursor != next || cursorBody.Data.Meta.PrevCursor != nil {
+		t.Fatalf("unexpected cursor page: %+v", cursorBody.Data)
+	}
+	wantLink := `</api/users?limit=2&sort=-created_at>; rel="first", </api/users?cursor=` + next + `&limit=2&sort=-created_at>; rel="next"`
+	if link := firstPage.Header().Get("Link"); link != wantLink {
+		t.Fatalf("Link = %s, want %s", link, wantLink)
+	}
+
+	secondPage := performJSONRequest(t, engine, http.MethodGet, "/api/users?limit=2&cursor="+next, nil, "")
+	assertStatus(t, secondPage, http.StatusOK)
+	if gotCursor == nil || gotCursor.ID != "user-2" || gotDesc {
+		t.Fatalf("cursor = %+v (desc %v), want user-2 ascending", gotCursor, gotDesc)
+	}
+
+	for _, query := range []string{"cursor=not-a-cursor", "cursor=" + next + "&page=2", "limit=2&sort=first_name", "limit=0", "limit=101"} {
+		rejected := performJSONRequest(t, engine, http.MethodGet, "/api/users?"+query, nil, "")
+		assertStatus(t, rejected, http.StatusUnprocessableEntity)
+	}
+
+	uncounted := performJSONRequest(t, engine, http.MethodGet, "/api/users?count=none", nil, "")
+	assertStatus(t, uncounted, http.StatusOK)
+	if gotCount != pagination.CountNone {
+		t.Fatalf("count mode = %q, want none", gotCount)
+	}
+	if strings.Contains(uncounted.Body.String(), "totalItems") || !strings.Contains(uncounted.Body.String(), `"hasMore":true`) {
+		t.Fatalf("expected hasMore without totalItems; body=%s", uncounted.Body.String())
+	}
+
+	invalidCount := performJSONRequest(t, engine, http.MethodGet, "/api/users?count=bogus", nil, "")
+	assertStatus(t, invalidCount, http.StatusUnprocessableEntity)
+}
+
+func TestListUsersPagingParams(t *testing.T) {
+	users := &fakeUserService{
+		getAllUsersPaginatedFn: func(_ context.Context, _ queryspec.Spec, params pagination.Params) (pagination.Page[*userdomain.User], error) {
+			total := int64(25)
+			return pagination.NewPage([]*userdomain.User{{ID: "user-1"}}, params, &total, false, params.Page < 3), nil
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users})
+
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/users?page=2&filter[status]=active", nil, "")
+	assertStatus(t, response, http.StatusOK)
+	wantLink := `</api/users?filter%5Bstatus%5D=active&page=1>; rel="first", ` +
+		`</api/users?filter%5Bstatus%5D=active&page=1>; rel="prev", ` +
+		`</api/users?filter%5Bstatus%5D=active&page=3>; rel="next", ` +
+		`</api/users?filter%5Bstatus%5D=active&page=3>; rel="last"`
+	if link := response.Header().Get("Link"); link != wantLink {
+		t.Fatalf("Link =\n%s\nwant\n%s", link, wantLink)
+	}
+
+	// Invalid paging values used to fall back to the defaults silently
+	invalid := performJSONRequest(t, engine, http.MethodGet, "/api/users?page=0&per_page=500&count=all", nil, "")
+	assertStatus(t, invalid, http.StatusUnprocessableEntity)
+	for _, field := range []string{`"page"`, `"perPage"`, `"count"`} {
+		if !strings.Contains(invalid.Body.String(), field) {
+			t.Fatalf("expected an error on %s; body=%s", field, invalid.Body.String())
+		}
+	}
+}
+
+func TestUserEndpointsRequireOwnerOrAdmin(t *testing.T) {
+	var updatedID, deletedID string
+	users := &fakeUserService{
+		updateUserFn: func(_ context.Context, _ map[string]interface{}, id string) (*userdomain.User, error) {
+			updatedID = id
+			return &userdomain.User{ID: id, Email: "test@example.com"}, nil
+		},
+		deleteUserFn: func(_ context.Context, id string) error {
+			deletedID = id
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+
+	tests := []struct {
+		name   string
+		userID string
+		role   constant.AccountTypeEnum
+		target string
+		want   int
+	}{
+		{name: "owner", userID: "user-1", role: constant.AccountTypeCustomer, target: "user-1", want: http.StatusOK},
+		{name: "other user", userID: "user-2", role: constant.AccountTypeCustomer, target: "user-1", want: http.StatusForbidden},
+		{name: "staff", userID: "staff-1", role: constant.AccountTypeStaff, target: "user-1", want: http.StatusForbidden},
+		{name: "admin", userID: "admin-1", role: constant.AccountTypeAdmin, target: "user-1", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updatedID, deletedID = "", ""
+			accessToken, err := jwtManager.GenerateAccessToken(tt.userID, utils.WithRole(string(tt.role)))
+			if err != nil {
+				t.Fatalf("generate access token: %v", err)
+			}
+
+			update := performJSONRequest(t, engine, http.MethodPut, "/api/users/"+tt.target, map[string]string{"name": "Updated User"}, accessToken)
+			assertStatus(t, update, tt.want)
+			remove := performJSONRequest(t, engine, http.MethodDelete, "/api/users/"+tt.target, nil, accessToken)
+			assertStatus(t, remove, tt.want)
+
+			allowed := tt.want == http.StatusOK
+			if (updatedID == tt.target) != allowed || (deletedID == tt.target) != allowed {
+				t.Fatalf("update called for %q, delete called for %q; allowed = %v", updatedID, deletedID, allowed)
+			}
+		})
+	}
+}
+
+func TestTransactionRunsAfterCommitHooksOnlyOnCommit(t *testing.T) {
+	var hookRuns int
+	var failUpdate bool
+	users := &fakeUserService{
+		updateUserFn: func(ctx context.Context, _ map[string]interface{}, id string) (*userdomain.User, error) {
+			utils.AfterCommit(ctx, func() { hookRuns++ })
+			if failUpdate {
+				return nil, errors.New("write failed")
+			}
+			return &userdomain.User{ID: id}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+	ownerToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	committed := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{"name": "Jane"}, ownerToken)
+	assertStatus(t, committed, http.StatusOK)
+	if hookRuns != 1 {
+		t.Fatalf("hook ran %d times after the commit, want 1", hookRuns)
+	}
+
+	failUpdate = true
+	rolledBack := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{"name": "Jane"}, ownerToken)
+	assertStatus(t, rolledBack, http.StatusInternalServerError)
+	if hookRuns != 1 {
+		t.Fatal("a hook ran although the transaction was rolled back")
+	}
+}
+
+func TestPatchUserEndpoint(t *testing.T) {
+	phone := "+9779800000000"
+	firstName := "Jane"
+	var updates map[string]interface{}
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "jane@example.com", FirstName: &firstName, Phone: &phone}, nil
+		},
+		updateUserFn: func(_ context.Context, fields map[string]interface{}, id string) (*userdomain.User, error) {
+			updates = fields
+			return &userdomain.User{ID: id, Email: "jane@example.com"}, nil
+		},
+	}
+	var requestedEmail string
+	emailChanges := &fakeEmailChangeService{
+		requestChangeFn: func(_ context.Context, _ string, newEmail string) (bool, error) {
+			requestedEmail = newEmail
+			return true, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, emailChanges: emailChanges})
+	ownerToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	// null clears a nullable column instead of being ignored
+	merge := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"lastName":"Doe","phone":null}`, ownerToken)
+	assertStatus(t, merge, http.StatusOK)
+	if want := map[string]interface{}{"last_name": "Doe", "phone": nil}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+
+	updates, requestedEmail = nil, ""
+	jsonPatch := performPatchRequest(t, engine, "/api/users/user-1", "application/json-patch+json",
+		`[{"op":"test","path":"/firstName","value":"Jane"},{"op":"remove","path":"/firstName"},{"op":"replace","path":"/email","value":"new@example.com"}]`, ownerToken)
+	assertStatus(t, jsonPatch, http.StatusOK)
+	if want := map[string]interface{}{"first_name": nil}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v; the email must wait for confirmation", updates, want)
+	}
+	if requestedEmail != "new@example.com" {
+		t.Fatalf("requested email change to %q, want new@example.com", requestedEmail)
+	}
+
+	updates = nil
+	conflict := performPatchRequest(t, engine, "/api/users/user-1", "application/json-patch+json",
+		`[{"op":"replace","path":"/phone","value":"+9779811111111"},{"op":"test","path":"/firstName","value":"Joan"}]`, ownerToken)
+	assertStatus(t, conflict, http.StatusConflict)
+
+	invalid := performPatchRequest(t, engine, "/api/users/user-1", "application/json-patch+json", `[{"op":"replace","path":"/type","value":"admin"}]`, ownerToken)
+	assertStatus(t, invalid, http.StatusUnprocessableEntity)
+	if !strings.Contains(invalid.Body.String(), "0.path") {
+		t.Fatalf("expected an error on 0.path; body=%s", invalid.Body.String())
+	}
+
+	unsupported := performPatchRequest(t, engine, "/api/users/user-1", "application/json", `{"lastName":"Doe"}`, ownerToken)
+	assertStatus(t, unsupported, http.StatusUnsupportedMediaType)
+	if accept := unsupported.Header().Get("Accept-Patch"); accept != "application/merge-patch+json, application/json-patch+json" {
+		t.Fatalf("Accept-Patch = %q", accept)
+	}
+	if updates != nil {
+		t.Fatalf("rejected patches must not update the user: %v", updates)
+	}
+
+	otherToken, err := jwtManager.GenerateAccessToken("user-2", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	forbidden := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"lastName":"Doe"}`, otherToken)
+	assertStatus(t, forbidden, http.StatusForbidden)
+
+	impersonationToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)), utils.WithActor("admin-1"))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	impersonatedEmail := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"email":"new@example.com"}`, impersonationToken)
+	assertStatus(t, impersonatedEmail, http.StatusForbidden)
+	impersonatedEmailOp := performPatchRequest(t, engine, "/api/users/user-1", "application/json-patch+json", `[{"op":"replace","path":"/email","value":"new@example.com"}]`, impersonationToken)
+	assertStatus(t, impersonatedEmailOp, http.StatusForbidden)
+	impersonatedProfile := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"lastName":"Doe"}`, impersonationToken)
+	assertStatus(t, impersonatedProfile, http.StatusForbidden)
+}
+
+func TestPatchUserEndpointSanitizesHTML(t *testing.T) {
+	var updates map[string]interface{}
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "jane@example.com"}, nil
+		},
+		updateUserFn: func(_ context.Context, fields map[string]interface{}, id string) (*userdomain.User, error) {
+			updates = fields
+			return &userdomain.User{ID: id, Email: "jane@example.com"}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+	ownerToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	merge := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"lastName":"<script>alert(1)</script>Doe"}`, ownerToken)
+	assertStatus(t, merge, http.StatusOK)
+	if want := map[string]interface{}{"last_name": "Doe"}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+
+	updates = nil
+	jsonPatch := performPatchRequest(t, engine, "/api/users/user-1", "application/json-patch+json",
+		`[{"op":"add","path":"/address","value":"<img src=x onerror=alert(1)>Kathmandu"}]`, ownerToken)
+	assertStatus(t, jsonPatch, http.StatusOK)
+	if want := map[string]interface{}{"address": "Kathmandu"}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+}
+
+func TestUpdateProfileEndpoint(t *testing.T) {
+	firstName, phone, zip, country := "Jane", "+9779800000000", "44600", "Nepal"
+	var updates map[string]interface{}
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "jane@example.com", FirstName: &firstName, Phone: &phone, Zip: &zip, Country: &country}, nil
+		},
+		updateUserFn: func(_ context.Context, fields map[string]interface{}, id string) (*userdomain.User, error) {
+			updates = fields
+			city := "Kathmandu"
+			return &userdomain.User{ID: id, Email: "jane@example.com", City: &city}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+	ownerToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	// The zip is checked again against the new country but only written when it changes
+	profile := map[string]interface{}{"firstName": "Jane", "phone": "+977 981-234 5678", "city": "Kathmandu", "zip": "44600", "country": "np"}
+	updated := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1/profile", profile, ownerToken)
+	assertStatus(t, updated, http.StatusOK)
+	if want := map[string]interface{}{"phone": "+9779812345678", "city": "Kathmandu", "country": "NP"}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+	if !strings.Contains(updated.Body.String(), `"city":"Kathmandu"`) {
+		t.Fatalf("expected the city in the response; body=%s", updated.Body.String())
+	}
+
+	// Omitted fields are cleared
+	updates = nil
+	cleared := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1/profile", map[string]interface{}{"firstName": "Jane", "phone": " "}, ownerToken)
+	assertStatus(t, cleared, http.StatusOK)
+	if want := map[string]interface{}{"phone": nil, "zip": nil, "country": nil}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+
+	updates = nil
+	invalid := []struct {
+		body    map[string]interface{}
+		field   string
+		message string
+	}{
+		{body: map[string]interface{}{"phone": "9812345678"}, field: "phone", message: "The phone must be a valid international phone number, such as +9779812345678."},
+		{body: map[string]interface{}{"country": "XX"}, field: "country", message: "The country must be a valid ISO 3166-1 alpha-2 code, such as NP."},
+		{body: map[string]interface{}{"zip": "4460", "country": "NP"}, field: "zip", message: "The zip is not a valid postal code for NP."},
+		{body: map[string]interface{}{"zip": "44700"}, field: "zip", message: "The country field is required when zip is present."},
+	}
+	for _, tc := range invalid {
+		recorder := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1/profile", tc.body, ownerToken)
+		assertStatus(t, recorder, http.StatusUnprocessableEntity)
+		if !strings.Contains(recorder.Body.String(), `"`+tc.field+`":["`+tc.message) {
+			t.Fatalf("expected %q on %s; body=%s", tc.message, tc.field, recorder.Body.String())
+		}
+	}
+	if updates != nil {
+		t.Fatalf("invalid profiles must not update the user: %v", updates)
+	}
+
+	// PATCH applies the same rules
+	patched := performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"phone":"00977 9812345678","country":"ca"}`, ownerToken)
+	assertStatus(t, patched, http.StatusUnprocessableEntity)
+	if !strings.Contains(patched.Body.String(), "The zip is not a valid postal code for CA.") {
+		t.Fatalf("expected the zip to be checked against the new country; body=%s", patched.Body.String())
+	}
+	patched = performPatchRequest(t, engine, "/api/users/user-1", "application/merge-patch+json", `{"phone":"00977 9812345678","country":"ca","zip":"k1a 0b1"}`, ownerToken)
+	assertStatus(t, patched, http.StatusOK)
+	if want := map[string]interface{}{"phone": "+9779812345678", "country": "CA", "zip": "K1A 0B1"}; !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+
+	otherToken, err := jwtManager.GenerateAccessToken("user-2", utils.WithRole(string(constant.AccountTypeCustomer)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	forbidden := performJSONRequest(t, engine, http.MethodPut, "/api/users/user-1/profile", profile, otherToken)
+	assertStatus(t, forbidden, http.StatusForbidden)
+}
+
+func TestUserEndpointsRedactEmails(t *testing.T) {
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "owner@example.com"}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+
+	tests := []struct {
+		name      string
+		userID    string
+		role      constant.AccountTypeEnum
+		wantEmail bool
+	}{
+		{name: "anonymous"},
+		{name: "other user", userID: "user-2", role: constant.AccountTypeCustomer},
+		{name: "owner", userID: "user-1", role: constant.AccountTypeCustomer, wantEmail: true},
+		{name: "staff", userID: "staff-1", role: constant.AccountTypeStaff, wantEmail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken := ""
+			if tt.userID != "" {
+				var err error
+				accessToken, err = jwtManager.GenerateAccessToken(tt.userID, utils.WithRole(string(tt.role)))
+				if err != nil {
+					t.Fatalf("generate access token: %v", err)
+				}
+			}
+
+			response := performJSONRequest(t, engine, http.MethodGet, "/api/users/user-1", nil, accessToken)
+			assertStatus(t, response, http.StatusOK)
+
+			var body struct {
+				Data struct {
+					Email string `json:"email"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if (body.Data.Email != "") != tt.wantEmail {
+				t.Fatalf("email = %q, want visible = %v", body.Data.Email, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestAdminPermissionEndpoints(t *testing.T) {
+	var grantedRole constant.AccountTypeEnum
+	var grantedPermission, revokedPermission string
+	permissions := &fakePermissionService{
+		policy: defaultTestPolicy(),
+		grantFn: func(_ context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+			grantedRole, grantedPermission = role, name
+			return &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{name}}, nil
+		},
+		revokeFn: func(_ context.Context, role constant.AccountTypeEnum, name string) (*permission.RolePermissionsDTO, error) {
+			revokedPermission = name
+			return &permission.RolePermissionsDTO{Role: string(role), Permissions: []string{}}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{permissions: permissions})
+
+	staffToken, err := jwtManager.GenerateAccessToken("staff-1", utils.WithRole(string(constant.AccountTypeStaff)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	adminToken, err := jwtManager.GenerateAccessToken("admin-1", utils.WithRole(string(constant.AccountTypeAdmin)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	grant := map[string]string{"permission": constant.PermissionUsersDelete}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/admin/roles/staff/permissions", grant, staffToken)
+	assertStatus(t, response, http.StatusForbidden)
+	if grantedPermission != "" {
+		t.Fatal("a non-admin was able to grant a permission")
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/roles/staff/permissions", grant, adminToken)
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if grantedRole != constant.AccountTypeStaff || grantedPermission != constant.PermissionUsersDelete {
+		t.Fatalf("granted %q to %q, want users:delete to staff", grantedPermission, grantedRole)
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/roles/staff/permissions", map[string]string{}, adminToken)
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+
+	response = performJSONRequest(t, engine, http.MethodDelete, "/api/admin/roles/staff/permissions/users:delete", nil, adminToken)
+	assertStatus(t, response, http.StatusOK)
+	if revokedPermission != constant.PermissionUsersDelete {
+		t.Fatalf("revoked %q, want users:delete", revokedPermission)
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	var updatedBy string
+	var updatedFields map[string]interface{}
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Email: "cron@example.com"}, nil
+		},
+		updateUserFn: func(ctx context.Context, fields map[string]interface{}, id string) (*userdomain.User, error) {
+			updatedFields = fields
+			if principal, ok := authz.PrincipalFromContext(ctx); ok {
+				updatedBy = principal.APIKeyID
+			}
+			return &userdomain.User{ID: id, Email: "cron@example.com"}, nil
+		},
+	}
+	apiKeys := &fakeAPIKeyService{
+		authenticateFn: func(_ context.Context, key string) (*authz.Principal, error) {
+			switch key {
+			case "gsk_update":
+				return &authz.Principal{UserID: "user-1", Role: constant.AccountTypeCustomer, APIKeyID: "key-1", Scopes: []string{constant.PermissionUsersUpdate}}, nil
+			case "gsk_read":
+				return &authz.Principal{UserID: "user-1", Role: constant.AccountTypeCustomer, APIKeyID: "key-2", Scopes: []string{constant.PermissionUsersReadEmail}}, nil
+			}
+			return nil, exceptions.UnauthorizedError("Invalid API key", nil, nil)
+		},
+	}
+	engine, _ := newTestRouter(t, testServices{users: users, apiKeys: apiKeys})
+	update := map[string]string{"name": "Updated User"}
+
+	response := performAPIKeyRequest(t, engine, http.MethodPut, "/api/users/user-1", update, "gsk_update")
+	assertStatus(t, response, http.StatusOK)
+	if updatedBy != "key-1" {
+		t.Fatalf("principal API key = %q, want key-1", updatedBy)
+	}
+
+	// The owner may update their account, but this key is not scoped for it
+	response = performAPIKeyRequest(t, engine, http.MethodPut, "/api/users/user-1", update, "gsk_read")
+	assertStatus(t, response, http.StatusForbidden)
+
+	response = performAPIKeyRequest(t, engine, http.MethodPut, "/api/users/user-1", update, "gsk_unknown")
+	assertStatus(t, response, http.StatusUnauthorized)
+
+	// A leaked key must not be enough to take over the account; passwords are not part of an update
+	response = performAPIKeyRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{"password": "N3w-Passw0rd!"}, "gsk_update")
+	assertStatus(t, response, http.StatusOK)
+	if _, ok := updatedFields["password"]; ok {
+		t.Fatalf("PUT /users/:id updated the password: %v", updatedFields)
+	}
+	updatedBy = ""
+	response = performAPIKeyRequest(t, engine, http.MethodPut, "/api/users/user-1", map[string]string{"email": "attacker@example.com"}, "gsk_update")
+	assertStatus(t, response, http.StatusForbidden)
+	patchRequest := httptest.NewRequest(http.MethodPatch, "/api/users/user-1", strings.NewReader(`{"email":"attacker@example.com"}`))
+	patchRequest.Header.Set("Content-Type", "application/merge-patch+json")
+	patchRequest.Header.Set("X-API-Key", "gsk_update")
+	patchResponse := httptest.NewRecorder()
+	engine.ServeHTTP(patchResponse, patchRequest)
+	assertStatus(t, patchResponse, http.StatusForbidden)
+	if updatedBy != "" {
+		t.Fatal("a credential change through an API key updated the user")
+	}
+
+	// Account and session management stays behind a login
+	response = performAPIKeyRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, "gsk_update")
+	assertStatus(t, response, http.StatusUnauthorized)
+	response = performAPIKeyRequest(t, engine, http.MethodPost, "/api/api-keys", map[string]interface{}{"name": "nested", "scopes": []string{"users:update"}}, "gsk_update")
+	assertStatus(t, response, http.StatusUnauthorized)
+}
+
+func TestAPIKeyManagementEndpoints(t *testing.T) {
+	var created apikey.CreateAPIKeyRequest
+	var createdFor, revokedKey string
+	apiKeys := &fakeAPIKeyService{
+		createFn: func(_ context.Context, userID string, req apikey.CreateAPIKeyRequest) (*apikey.CreatedAPIKeyDTO, error) {
+			created, createdFor = req, userID
+			return &apikey.CreatedAPIKeyDTO{APIKeyDTO: apikey.APIKeyDTO{ID: "key-1", UserID: userID, Scopes: req.Scopes}, Key: "gsk_secret"}, nil
+		},
+		revokeFn: func(_ context.Context, userID string, id string) error {
+			revokedKey = userID + ":" + id
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{apiKeys: apiKeys})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	adminToken, err := jwtManager.GenerateAccessToken("admin-1", utils.WithRole(string(constant.AccountTypeAdmin)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/api-keys", map[string]interface{}{
+		"name":   "Nightly export",
+		"scopes": []string{constant.PermissionUsersReadEmail},
+	}, accessToken)
+	assertStatus(t, response, http.StatusCreated)
+	assertSuccessResponse(t, response)
+	if createdFor != "user-1" || created.Name != "Nightly export" || len(created.Scopes) != 1 {
+		t.Fatalf("created %+v for %q", created, createdFor)
+	}
+	var body struct {
+		Data struct {
+			Key string `json:"key"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body.Data.Key != "gsk_secret" {
+		t.Fatalf("created key not returned: %s", response.Body.String())
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/api-keys", map[string]interface{}{"name": "No scopes"}, accessToken)
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+
+	response = performJSONRequest(t, engine, http.MethodDelete, "/api/api-keys/key-1", nil, accessToken)
+	assertStatus(t, response, http.StatusOK)
+	if revokedKey != "user-1:key-1" {
+		t.Fatalf("revoked %q, want user-1:key-1", revokedKey)
+	}
+
+	// Only admins create service accounts and issue keys for other accounts
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/service-accounts", map[string]string{"name": "Billing sync"}, accessToken)
+	assertStatus(t, response, http.StatusForbidden)
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/service-accounts", map[string]string{"name": "Billing sync"}, adminToken)
+	assertStatus(t, response, http.StatusCreated)
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/users/service-1/api-keys", map[string]interface{}{
+		"name":   "Billing sync",
+		"scopes": []string{constant.PermissionUsersReadEmail},
+	}, adminToken)
+	assertStatus(t, response, http.StatusCreated)
+	if createdFor != "service-1" {
+		t.Fatalf("key created for %q, want service-1", createdFor)
+	}
+}
+
+func TestLogoutEndpoint(t *testing.T) {
+	var revokedUserID, versionBumpedFor string
+	users := &fakeUserService{
+		revokeAccessTokensFn: func(_ context.Context, id string) error {
+			versionBumpedFor = id
+			return nil
+		},
+	}
+	refreshTokens := &fakeRefreshTokenService{
+		revokeAllUserTokensFn: func(_ context.Context, userID string) error {
+			revokedUserID = userID
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/logout", nil, accessToken)
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if revokedUserID != "user-1" {
+		t.Fatalf("revoked user id = %q, want user-1", revokedUserID)
+	}
+	if versionBumpedFor != "user-1" {
+		t.Fatalf("access tokens revoked for %q, want user-1", versionBumpedFor)
+	}
+}
+
+func TestProtectedEndpointRejectsRevokedAccessToken(t *testing.T) {
+	users := &fakeUserService{
+		getTokenVersionFn: func(_ context.Context, id string) (int, error) {
+			if id == "deleted-user" {
+				return 0, exceptions.NotFoundError("User not found", nil, nil)
+			}
+			return 2, nil
+		},
+	}
+	refreshTokens := &fakeRefreshTokenService{
+		isSessionRevokedFn: func(_ context.Context, sessionID string) (bool, error) {
+			return sessionID == "revoked-session", nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens})
+
+	tests := []struct {
+		name   string
+		userID string
+		opts   []utils.TokenOption
+		want   int
+	}{
+		{name: "stale token version", userID: "user-1", opts: []utils.TokenOption{utils.WithTokenVersion(1)}, want: http.StatusUnauthorized},
+		{name: "revoked session", userID: "user-1", opts: []utils.TokenOption{utils.WithTokenVersion(2), utils.WithSessionID("revoked-session")}, want: http.StatusUnauthorized},
+		{name: "deleted user", userID: "deleted-user", opts: []utils.TokenOption{utils.WithTokenVersion(2)}, want: http.StatusUnauthorized},
+		{name: "current token", userID: "user-1", opts: []utils.TokenOption{utils.WithTokenVersion(2), utils.WithSessionID("live-session")}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken, err := jwtManager.GenerateAccessToken(tt.userID, tt.opts...)
+			if err != nil {
+				t.Fatalf("generate access token: %v", err)
+			}
+
+			response := performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, accessToken)
+			assertStatus(t, response, tt.want)
+		})
+	}
+}
+
+func TestListSessionsEndpoint(t *testing.T) {
+	familyID := refreshtoken.NewFamilyID()
+	deviceName := "Work laptop"
+	refreshTokens := &fakeRefreshTokenService{
+		listActiveSessionsFn: func(_ context.Context, userID string) ([]*refreshtoken.RefreshToken, error) {
+			if userID != "user-1" {
+				t.Fatalf("ListActiveSessions user id = %q, want user-1", userID)
+			}
+			return []*refreshtoken.RefreshToken{
+				{ID: "token-2", UserID: userID, FamilyID: familyID, DeviceName: &deviceName, ExpiresAt: time.Now().Add(time.Hour)},
+				{ID: "token-1", UserID: userID, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)},
+			}, nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{refreshTokens: refreshTokens})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithSessionID(familyID))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, accessToken)
+
+	assertStatus(t, response, http.StatusOK)
+	var body struct {
+		Data []refreshtoken.SessionDTO `json:"data"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, response.Body.String())
+	}
+	if len(body.Data) != 2 || body.Data[0].ID != familyID || !body.Data[0].Current || body.Data[1].Current {
+		t.Fatalf("unexpected sessions: %+v", body.Data)
+	}
+}
+
+func TestRevokeSessionEndpoint(t *testing.T) {
+	var revokedUserID, revokedSessionID string
+	refreshTokens := &fakeRefreshTokenService{
+		revokeSessionFn: func(_ context.Context, userID string, sessionID string) error {
+			if sessionID == "missing" {
+				return exceptions.NotFoundError("Session not found", nil, nil)
+			}
+			revokedUserID, revokedSessionID = userID, sessionID
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{refreshTokens: refreshTokens})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodDelete, "/api/auth/sessions/family-1", nil, accessToken)
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if revokedUserID != "user-1" || revokedSessionID != "family-1" {
+		t.Fatalf("revoked session = %q/%q, want user-1/family-1", revokedUserID, revokedSessionID)
+	}
+
+	response = performJSONRequest(t, engine, http.MethodDelete, "/api/auth/sessions/missing", nil, accessToken)
+	assertStatus(t, response, http.StatusNotFound)
+}
+
+func TestSecurityEventsEndpoint(t *testing.T) {
+	clientIP := "203.0.113.7"
+	securityEvents := &fakeSecurityEventService{
+		listForUser: func(_ context.Context, userID string, params pagination.Params) (pagination.Page[*securityevent.SecurityEvent], error) {
+			if userID != "user-1" || params.Page != 2 || params.PerPage != 1 {
+				t.Fatalf("ListForUser(%q, %+v), want user-1, page 2 of 1", userID, params)
+			}
+			total := int64(3)
+			return pagination.NewPage([]*securityevent.SecurityEvent{
+				{ID: "event-1", UserID: userID, Type: audit.EventLoginFailed, Details: map[string]string{"reason": "invalid_password"}, ClientIP: &clientIP},
+			}, params, &total, false, true), nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{securityEvents: securityEvents})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	unauthenticated := performJSONRequest(t, engine, http.MethodGet, "/api/users/me/security-events", nil, "")
+	assertStatus(t, unauthenticated, http.StatusUnauthorized)
+
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/users/me/security-events?page=2&per_page=1", nil, accessToken)
+
+	assertStatus(t, response, http.StatusOK)
+	var body struct {
+		Data securityevent.PaginatedSecurityEventDTO `json:"data"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v; body=%s", err, response.Body.String())
+	}
+	if len(body.Data.Events) != 1 || body.Data.Events[0].Type != audit.EventLoginFailed || body.Data.Events[0].Details["reason"] != "invalid_password" {
+		t.Fatalf("unexpected events: %+v", body.Data.Events)
+	}
+	if body.Data.Meta.Page != 2 || body.Data.Meta.TotalPages == nil || *body.Data.Meta.TotalPages != 3 || !body.Data.Meta.HasMore {
+		t.Fatalf("unexpected pagination: %+v", body.Data.Meta)
+	}
+}
+
+func TestChangePasswordEndpoint(t *testing.T) {
+	tokenVersion := 3
+	var changedUserID, currentPassword, newPassword string
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Status: constant.UserStatusActive, TokenVersion: tokenVersion}, nil
+		},
+		getTokenVersionFn: func(context.Context, string) (int, error) {
+			return tokenVersion, nil
+		},
+		changePasswordFn: func(_ context.Context, id string, current string, password string) error {
+			if current != "old-secret-123" {
+				return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+					{Field: "current_password", Message: "The current password is incorrect."},
+				})
+			}
+			changedUserID, currentPassword, newPassword = id, current, password
+			tokenVersion++
+			return nil
+		},
+	}
+	var revokedUserID, keptSessionID string
+	refreshTokens := &fakeRefreshTokenService{
+		revokeOtherSessionsFn: func(_ context.Context, userID string, keepSessionID string) error {
+			revokedUserID, keptSessionID = userID, keepSessionID
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users, refreshTokens: refreshTokens})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithSessionID("family-1"), utils.WithTokenVersion(3))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/auth/change-password", map[string]string{
+		"current_password":      "wrong-secret",
+		"password":              "new-secret-456",
+		"password_confirmation": "new-secret-456",
+	}, accessToken)
+
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+	if !strings.Contains(response.Body.String(), "The current password is incorrect.") {
+		t.Fatalf("expected a current_password error, got %s", response.Body.String())
+	}
+	if revokedUserID != "" {
+		t.Fatalf("sessions must not be revoked when the current password is wrong")
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/auth/change-password", map[string]string{
+		"current_password":      "old-secret-123",
+		"password":              "new-secret-456",
+		"password_confirmation": "new-secret-456",
+	}, accessToken)
+
+	assertStatus(t, response, http.StatusOK)
+	assertSuccessResponse(t, response)
+	if changedUserID != "user-1" || currentPassword != "old-secret-123" || newPassword != "new-secret-456" {
+		t.Fatalf("unexpected password change: user=%q current=%q new=%q", changedUserID, currentPassword, newPassword)
+	}
+	if revokedUserID != "user-1" || keptSessionID != "family-1" {
+		t.Fatalf("revoked sessions of %q keeping %q, want user-1 keeping family-1", revokedUserID, keptSessionID)
+	}
+
+	claims, err := jwtManager.ValidateToken(accessTokenFromBody(t, response))
+	if err != nil || claims.SessionID != "family-1" || claims.TokenVersion != 4 {
+		t.Fatalf("new access token claims = %+v (err %v), want session family-1 at version 4", claims, err)
+	}
+
+	// The new token version cuts off the access token used for the change
+	response = performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, accessToken)
+	assertStatus(t, response, http.StatusUnauthorized)
+}
+
+func TestChangePasswordEndpointLocksOutAfterWrongPasswords(t *testing.T) {
+	users := &fakeUserService{
+		getUserByIDFn: func(_ context.Context, id string) (*userdomain.User, error) {
+			return &userdomain.User{ID: id, Status: constant.UserStatusActive}, nil
+		},
+		changePasswordFn: func(context.Context, string, string, string) error {
+			return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+				{Field: "current_password", Message: "The current password is incorrect."},
+			})
+		},
+	}
+	notifier := &fakeLockoutNotifier{events: make(chan authsvc.LockoutEvent, 1)}
+	engine, jwtManager := newTestRouter(t, testServices{
+		users: users,
+		loginThrottle: authsvc.LoginThrottleOptions{
+			MaxAttempts:     3,
+			IPMaxAttempts:   100,
+			Window:          time.Minute,
+			LockoutDuration: time.Minute,
+		},
+		lockoutNotifier: notifier,
+	})
+	accessToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithSessionID("family-1"))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	attempt := func() *httptest.ResponseRecorder {
+		return performJSONRequest(t, engine, http.MethodPost, "/api/auth/change-password", map[string]string{
+			"current_password":      "guess-123",
+			"password":              "new-secret-456",
+			"password_confirmation": "new-secret-456",
+		}, accessToken)
+	}
+
+	for i := 0; i < 3; i++ {
+		assertStatus(t, attempt(), http.StatusUnprocessableEntity)
+	}
+	assertStatus(t, attempt(), http.StatusTooManyRequests)
+
+	select {
+	case event := <-notifier.events:
+		if event.UserID != "user-1" || event.Email != "" || event.Attempts != 3 {
+			t.Fatalf("unexpected lockout event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("lockout notification was not sent")
+	}
+}
+
+func TestImpersonateEndpoint(t *testing.T) {
+	var started impersonation.StartInput
+	var jwtManager *utils.JWTManager
+	impersonations := &fakeImpersonationService{
+		startFn: func(_ context.Context, input impersonation.StartInput) (*impersonation.ImpersonationTokenDTO, error) {
+			started = input
+			accessToken, err := jwtManager.GenerateAccessToken(input.UserID, utils.WithSessionID("audit-1"), utils.WithActor(input.AdminID))
+			if err != nil {
+				return nil, err
+			}
+			return &impersonation.ImpersonationTokenDTO{SessionID: "audit-1", AccessToken: accessToken, TokenType: "Bearer", ExpiresIn: 900}, nil
+		},
+	}
+	jwtManager = utils.NewJWTManager(testJWTSecret, 15*time.Minute, 24*time.Hour)
+	engine, _ := newTestRouter(t, testServices{impersonations: impersonations, jwtManager: jwtManager})
+
+	staffToken, err := jwtManager.GenerateAccessToken("staff-1", utils.WithRole(string(constant.AccountTypeStaff)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	adminToken, err := jwtManager.GenerateAccessToken("admin-1", utils.WithRole(string(constant.AccountTypeAdmin)))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	body := map[string]string{"reason": "Support ticket #4521"}
+
+	response := performJSONRequest(t, engine, http.MethodPost, "/api/admin/impersonate/user-1", body, staffToken)
+	assertStatus(t, response, http.StatusForbidden)
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/impersonate/user-1", map[string]string{}, adminToken)
+	assertStatus(t, response, http.StatusUnprocessableEntity)
+	if started.UserID != "" {
+		t.Fatal("an impersonation without a reason was started")
+	}
+
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/admin/impersonate/user-1", body, adminToken)
+	assertStatus(t, response, http.StatusCreated)
+	assertSuccessResponse(t, response)
+	if started.AdminID != "admin-1" || started.UserID != "user-1" || started.Reason != "Support ticket #4521" {
+		t.Fatalf("unexpected impersonation input: %+v", started)
+	}
+
+	claims, err := jwtManager.ValidateToken(accessTokenFromBody(t, response))
+	if err != nil || claims.UserID != "user-1" || !claims.IsImpersonation() || claims.Act.Subject != "admin-1" {
+		t.Fatalf("impersonation token claims = %+v (err %v), want user-1 acted on by admin-1", claims, err)
+	}
+}
+
+func TestImpersonationTokenCannotChangeCredentials(t *testing.T) {
+	var changed []string
+	users := &fakeUserService{
+		updateUserFn: func(_ context.Context, updates map[string]interface{}, id string) (*userdomain.User, error) {
+			changed = append(changed, "update")
+			return &userdomain.User{ID: id, Status: constant.UserStatusActive}, nil
+		},
+		changePasswordFn: func(context.Context, string, string, string) error {
+			changed = append(changed, "password")
+			return nil
+		},
+		deleteUserFn: func(context.Context, string) error {
+			changed = append(changed, "delete")
+			return nil
+		},
+	}
+	engine, jwtManager := newTestRouter(t, testServices{users: users})
+
+	accessToken, err := jwtManager.GenerateAccessToken("user-1", utils.WithSessionID("audit-1"), utils.WithActor("admin-1"))
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+
+	denied := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{method: http.MethodPost, path: "/api/auth/change-password", body: map[string]string{
+			"current_password": "old-secret-123", "password": "new-secret-456", "password_confirmation": "new-secret-456",
+		}},
+		{method: http.MethodPost, path: "/api/auth/logout"},
+		{method: http.MethodDelete, path: "/api/auth/sessions/family-1"},
+		{method: http.MethodPost, path: "/api/auth/2fa/enroll"},
+		{method: http.MethodPost, path: "/api/api-keys", body: map[string]interface{}{"name": "ci", "scopes": []string{constant.PermissionUsersReadEmail}}},
+		{method: http.MethodPut, path: "/api/users/user-1", body: map[string]string{"email": "new@example.com"}},
+		{method: http.MethodPut, path: "/api/users/user-1", body: map[string]string{"name": "Jane"}},
+		{method: http.MethodPut, path: "/api/users/user-1/profile", body: map[string]string{"first_name": "Jane"}},
+		{method: http.MethodDelete, path: "/api/users/user-1"},
+	}
+	for _, tc := range denied {
+		response := performJSONRequest(t, engine, tc.method, tc.path, tc.body, accessToken)
+		assertStatus(t, response, http.StatusForbidden)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("an impersonation token reached %v", changed)
+	}
+
+	// The account is still read as the user
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, accessToken)
+	assertStatus(t, response, http.StatusOK)
+
+	// No refresh token is issued, so the session cannot outlive its access token
+	response = performJSONRequest(t, engine, http.MethodPost, "/api/auth/refresh", map[string]string{"refresh_token": accessToken}, "")
+	assertStatus(t, response, http.StatusUnauthorized)
+}
+
+func TestJWKSEndpointPublishesRotatedKeys(t *testing.T) {
+	keyRing, err := utils.NewKeyRing(utils.KeyRingOptions{Algorithm: utils.AlgorithmEdDSA})
+	if err != nil {
+		t.Fatalf("create key ring: %v", err)
+	}
+	jwtManager := utils.NewJWTManagerWithKeyRing(keyRing, 15*time.Minute, 24*time.Hour)
+	engine, _ := newTestRouter(t, testServices{jwtManager: jwtManager})
+
+	oldToken, err := jwtManager.GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate access token: %v", err)
+	}
+	if _, err := keyRing.Rotate(); err != nil {
+		t.Fatalf("rotate key ring: %v", err)
+	}
+
+	// Tokens signed with the retired key still pass the middleware
+	response := performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, oldToken)
+	assertStatus(t, response, http.StatusOK)
+
+	// HS256 tokens are rejected once asymmetric signing is enabled
+	hmacToken, err := utils.NewJWTManager(testJWTSecret, time.Minute, time.Minute).GenerateAccessToken("user-1")
+	if err != nil {
+		t.Fatalf("generate hmac token: %v", err)
+	}
+	response = performJSONRequest(t, engine, http.MethodGet, "/api/auth/sessions", nil, hmacToken)
+	assertStatus(t, response, http.StatusUnauthorized)
+
+	response = performJSONRequest(t, engine, http.MethodGet, "/.well-known/jwks.json", nil, "")
+	assertStatus(t, response, http.StatusOK)
+
+	var set utils.JWKSet
+	if err := json.Unmarshal(response.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode jwks: %v; body=%s", err, response.Body.String())
+	}
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != keyRing.Current().ID {
+		t.Fatalf("unexpected key set: %+v", set.Keys)
+	}
+
+	// An external verifier only needs the published key set
+	parsed, err := jwt.Parse(oldToken, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range set.Keys {
+			if key.KeyID == token.Header["kid"] && key.KeyType == "OKP" {
+				x, err := base64.RawURLEncoding.DecodeString(key.X)
+				return ed25519.PublicKey(x), err
+			}
+		}
+		return nil, fmt.Errorf("kid %v not published", token.Header["kid"])
+	}, jwt.WithValidMethods([]string{utils.AlgorithmEdDSA}))
+	if err != nil || !parsed.Valid {
+		t.Fatalf("verify token with published key: %v", err)
 	}
 }
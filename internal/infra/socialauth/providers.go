@@ -0,0 +1,46 @@
+package socialauth
+
+import (
+	"gin/internal/infra/config"
+	"gin/internal/infra/integration/github"
+	"gin/internal/infra/integration/oidc"
+	"gin/internal/shared/oauth"
+)
+
+// googleIssuer is Google's OpenID Connect issuer
+const googleIssuer = "https://accounts.google.com"
+
+// NewProviders creates the social login providers enabled in the configuration
+// Providers without a client ID are left out, so an empty configuration disables social login
+func NewProviders(cfg *config.Config) *oauth.Registry {
+	oauthConfig := cfg.OAuth()
+	var providers []oauth.Provider
+
+	if oauthConfig.Google.ClientID != "" {
+		providers = append(providers, oidc.New(oidc.Config{
+			Name:         "google",
+			IssuerURL:    googleIssuer,
+			ClientID:     oauthConfig.Google.ClientID,
+			ClientSecret: oauthConfig.Google.ClientSecret,
+		}))
+	}
+
+	if oauthConfig.GitHub.ClientID != "" {
+		providers = append(providers, github.New(github.Config{
+			ClientID:     oauthConfig.GitHub.ClientID,
+			ClientSecret: oauthConfig.GitHub.ClientSecret,
+		}))
+	}
+
+	if oauthConfig.OIDC.ClientID != "" && oauthConfig.OIDC.IssuerURL != "" {
+		providers = append(providers, oidc.New(oidc.Config{
+			Name:         oauthConfig.OIDC.Name,
+			IssuerURL:    oauthConfig.OIDC.IssuerURL,
+			ClientID:     oauthConfig.OIDC.ClientID,
+			ClientSecret: oauthConfig.OIDC.ClientSecret,
+			Scopes:       oauthConfig.OIDC.Scopes,
+		}))
+	}
+
+	return oauth.NewRegistry(providers...)
+}
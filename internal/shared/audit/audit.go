@@ -0,0 +1,55 @@
+package audit
+
+import "context"
+
+// Security event types recorded in a user's activity log
+const (
+	EventLoginSucceeded       = "login_succeeded"
+	EventLoginFailed          = "login_failed"
+	EventTokenRefreshed       = "token_refreshed"
+	EventRefreshTokenReuse    = "refresh_token_reuse"
+	EventLogout               = "logout"
+	EventSessionRevoked       = "session_revoked"
+	EventPasswordChanged      = "password_changed"
+	EventPasswordReset        = "password_reset"
+	EventEmailChangeRequested = "email_change_requested"
+	EventEmailChanged         = "email_changed"
+	EventTwoFactorEnabled     = "two_factor_enabled"
+	EventTwoFactorDisabled    = "two_factor_disabled"
+	EventRecoveryCodeUsed     = "two_factor_recovery_code_used"
+	EventImpersonationStarted = "impersonation_started"
+	EventSocialSignup         = "social_signup"
+	EventSocialProviderLinked = "social_provider_linked"
+	EventAPIKeyCreated        = "api_key_created"
+	EventAPIKeyRevoked        = "api_key_revoked"
+	EventMagicLinkUsed        = "magic_link_used"
+)
+
+// Recorder writes security events to the activity log of a user
+// Implementations live in the security_event domain so callers do not depend on storage
+type Recorder interface {
+	// Record stores the event with the client of the request in ctx; details are optional
+	// Failures are logged rather than returned, so recording never fails the action being recorded
+	// Events of successful actions wait for the request transaction to commit; failed logins and
+	// refresh token reuse are written at once, since their request is rejected and rolls back
+	Record(ctx context.Context, userID string, eventType string, details map[string]string)
+}
+
+// Client identifies where a request came from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient returns a copy of ctx carrying the client of the request
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client stored in ctx; it is empty outside HTTP requests
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
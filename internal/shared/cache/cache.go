@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a small key/value store for hot-path lookups
+// Implementations live under internal/infra so domains stay vendor-agnostic
+type Cache interface {
+	// Get returns the value stored under key and whether it was found
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set stores a value under key; a zero ttl keeps it until deleted
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Increment atomically adds one to a counter and returns the new value
+	// The ttl applies when the counter is created, so counters expire a fixed time after the first hit
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
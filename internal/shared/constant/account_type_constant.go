@@ -6,4 +6,15 @@ const (
 	AccountTypeCustomer AccountTypeEnum = "user"
 	AccountTypeAdmin    AccountTypeEnum = "admin"
 	AccountTypeStaff    AccountTypeEnum = "staff"
+	// AccountTypeService marks service accounts that only authenticate with API keys
+	AccountTypeService AccountTypeEnum = "service"
 )
+
+// IsValid reports whether the account type is one of the known roles
+func (t AccountTypeEnum) IsValid() bool {
+	switch t {
+	case AccountTypeCustomer, AccountTypeAdmin, AccountTypeStaff, AccountTypeService:
+		return true
+	}
+	return false
+}
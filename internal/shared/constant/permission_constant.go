@@ -0,0 +1,9 @@
+package constant
+
+// Permissions checked by the policy engine
+// Assignments to roles are stored in the role_permissions table
+const (
+	PermissionUsersUpdate    = "users:update"
+	PermissionUsersDelete    = "users:delete"
+	PermissionUsersReadEmail = "users:read:email"
+)
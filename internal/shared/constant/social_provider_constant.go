@@ -0,0 +1,4 @@
+package constant
+
+// SocialProviderEmailPassword marks accounts that sign in with email and password only
+const SocialProviderEmailPassword = "emailPassword"
@@ -0,0 +1,81 @@
+package contact
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// e164Pattern matches a plus sign followed by 7 to 15 digits, the first of which is not zero
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// phoneSeparators are the formatting characters dropped from phone numbers
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "")
+
+// postalCodePatterns holds the postal code formats of common countries, after NormalizePostalCode
+// has upper-cased the code and collapsed its spaces
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BD": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CN": regexp.MustCompile(`^\d{6}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"LK": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NP": regexp.MustCompile(`^\d{5}$`),
+	"PK": regexp.MustCompile(`^\d{5}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// genericPostalCodePattern accepts the codes of countries without a specific format
+var genericPostalCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,8}[A-Z0-9]$`)
+
+// NormalizePhone returns the E.164 form of an international phone number, e.g. +9779812345678
+// Spaces, dashes, dots, slashes, parentheses and a "(0)" trunk prefix are dropped, and a leading
+// 00 is read as the plus sign. Numbers without a country calling code are rejected
+func NormalizePhone(raw string) (string, bool) {
+	phone := strings.ReplaceAll(strings.TrimSpace(raw), "(0)", "")
+	phone = phoneSeparators.Replace(phone)
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + strings.TrimPrefix(phone, "00")
+	}
+	if !e164Pattern.MatchString(phone) {
+		return "", false
+	}
+	return phone, true
+}
+
+// NormalizeCountry returns the upper-case ISO 3166-1 alpha-2 code, e.g. NP for "np"
+func NormalizeCountry(raw string) (string, bool) {
+	country := strings.ToUpper(strings.TrimSpace(raw))
+	if err := validate.Var(country, "iso3166_1_alpha2"); err != nil {
+		return "", false
+	}
+	return country, true
+}
+
+// NormalizePostalCode upper-cases a postal code, collapses its spaces and checks it against the
+// format of the country, given as an ISO 3166-1 alpha-2 code
+// Countries without a known format accept 3 to 10 letters, digits, spaces and dashes
+func NormalizePostalCode(country, raw string) (string, bool) {
+	code := strings.Join(strings.Fields(strings.ToUpper(raw)), " ")
+
+	pattern, ok := postalCodePatterns[country]
+	if !ok {
+		pattern = genericPostalCodePattern
+	}
+	if !pattern.MatchString(code) {
+		return "", false
+	}
+	return code, true
+}
@@ -0,0 +1,77 @@
+package contact
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{raw: "+977 981-234 5678", want: "+9779812345678", ok: true},
+		{raw: "00977 (1) 4412345", want: "+97714412345", ok: true},
+		{raw: "+44 (0)20 7946 0958", want: "+442079460958", ok: true},
+		{raw: "+1.415.555.2671", want: "+14155552671", ok: true},
+		{raw: "9812345678"},
+		{raw: "+0 123 456 789"},
+		{raw: "+977 98123x45678"},
+		{raw: "+1234567890123456"},
+		{raw: "+12345"},
+	}
+
+	for _, tc := range cases {
+		got, ok := NormalizePhone(tc.raw)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestNormalizeCountry(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{raw: "NP", want: "NP", ok: true},
+		{raw: " us ", want: "US", ok: true},
+		{raw: "UK"},
+		{raw: "NPL"},
+		{raw: "Nepal"},
+	}
+
+	for _, tc := range cases {
+		got, ok := NormalizeCountry(tc.raw)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("NormalizeCountry(%q) = %q, %v; want %q, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestNormalizePostalCode(t *testing.T) {
+	cases := []struct {
+		country string
+		raw     string
+		want    string
+		ok      bool
+	}{
+		{country: "NP", raw: "44600", want: "44600", ok: true},
+		{country: "NP", raw: "4460"},
+		{country: "US", raw: "94105-1234", want: "94105-1234", ok: true},
+		{country: "US", raw: "9410"},
+		{country: "CA", raw: "k1a  0b1", want: "K1A 0B1", ok: true},
+		{country: "CA", raw: "D1A 0B1"},
+		{country: "GB", raw: "sw1a 1aa", want: "SW1A 1AA", ok: true},
+		{country: "NL", raw: "1012 ab", want: "1012 AB", ok: true},
+		{country: "IN", raw: "011001"},
+		{country: "SE", raw: "114 55", want: "114 55", ok: true},
+		{country: "SE", raw: "1"},
+	}
+
+	for _, tc := range cases {
+		got, ok := NormalizePostalCode(tc.country, tc.raw)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("NormalizePostalCode(%q, %q) = %q, %v; want %q, %v", tc.country, tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -12,11 +12,14 @@ import (
 type ErrorType string
 
 const (
-	ErrorTypeValidation   ErrorType = "VALIDATION_ERROR"
-	ErrorTypeInternal     ErrorType = "INTERNAL_ERROR"
-	ErrorTypeNotFound     ErrorType = "NOT_FOUND"
-	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
-	ErrorTypeForbidden    ErrorType = "FORBIDDEN"
+	ErrorTypeValidation           ErrorType = "VALIDATION_ERROR"
+	ErrorTypeInternal             ErrorType = "INTERNAL_ERROR"
+	ErrorTypeNotFound             ErrorType = "NOT_FOUND"
+	ErrorTypeUnauthorized         ErrorType = "UNAUTHORIZED"
+	ErrorTypeForbidden            ErrorType = "FORBIDDEN"
+	ErrorTypeTooManyRequests      ErrorType = "TOO_MANY_REQUESTS"
+	ErrorTypeConflict             ErrorType = "CONFLICT"
+	ErrorTypeUnsupportedMediaType ErrorType = "UNSUPPORTED_MEDIA_TYPE"
 )
 
 type AppError struct {
@@ -95,6 +98,45 @@ func ForbiddenError(message string, description *string, data ...interface{}) Ap
 	}
 }
 
+func TooManyRequestsError(message string, description *string, data ...interface{}) AppError {
+	var errorData interface{}
+	if len(data) > 0 {
+		errorData = data[0]
+	}
+	return AppError{
+		Type:        ErrorTypeTooManyRequests,
+		Message:     message,
+		Description: description,
+		Data:        errorData,
+	}
+}
+
+func ConflictError(message string, description *string, data ...interface{}) AppError {
+	var errorData interface{}
+	if len(data) > 0 {
+		errorData = data[0]
+	}
+	return AppError{
+		Type:        ErrorTypeConflict,
+		Message:     message,
+		Description: description,
+		Data:        errorData,
+	}
+}
+
+func UnsupportedMediaTypeError(message string, description *string, data ...interface{}) AppError {
+	var errorData interface{}
+	if len(data) > 0 {
+		errorData = data[0]
+	}
+	return AppError{
+		Type:        ErrorTypeUnsupportedMediaType,
+		Message:     message,
+		Description: description,
+		Data:        errorData,
+	}
+}
+
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Process request
@@ -140,6 +182,24 @@ func ErrorHandler() gin.HandlerFunc {
 						desc = *appErr.Description
 					}
 					response.SendError(c, appErr.Message, desc, http.StatusForbidden)
+				case ErrorTypeTooManyRequests:
+					desc := ""
+					if appErr.Description != nil {
+						desc = *appErr.Description
+					}
+					response.SendError(c, appErr.Message, desc, http.StatusTooManyRequests)
+				case ErrorTypeConflict:
+					desc := ""
+					if appErr.Description != nil {
+						desc = *appErr.Description
+					}
+					response.SendError(c, appErr.Message, desc, http.StatusConflict)
+				case ErrorTypeUnsupportedMediaType:
+					desc := ""
+					if appErr.Description != nil {
+						desc = *appErr.Description
+					}
+					response.SendError(c, appErr.Message, desc, http.StatusUnsupportedMediaType)
 				default:
 					response.SendError(c, "An unexpected error occurred", err.Error(), http.StatusInternalServerError)
 				}
@@ -0,0 +1,16 @@
+package mail
+
+import "context"
+
+// Message describes a transactional email
+type Message struct {
+	To      []string
+	Subject string
+	HTML    string
+}
+
+// Mailer sends transactional emails
+// Implementations live under internal/infra so domains stay vendor-agnostic
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
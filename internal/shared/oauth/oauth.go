@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"sort"
+)
+
+// Identity is the account a provider vouched for after a successful sign-in
+type Identity struct {
+	Provider      string
+	Subject       string // Stable account ID at the provider
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// AuthorizationRequest carries the per-attempt values sent to the authorization endpoint
+type AuthorizationRequest struct {
+	State         string
+	Nonce         string
+	CodeChallenge string // S256 PKCE challenge
+	RedirectURI   string
+}
+
+// ExchangeRequest carries the values needed to redeem an authorization code
+type ExchangeRequest struct {
+	Code         string
+	CodeVerifier string
+	Nonce        string
+	RedirectURI  string
+}
+
+// Provider is an OAuth2/OIDC identity provider using the authorization-code flow with PKCE
+// Implementations live under internal/infra so domains stay vendor-agnostic
+type Provider interface {
+	// Name is the identifier used in routes and stored as the user's social provider
+	Name() string
+	// AuthCodeURL returns the URL the user agent is redirected to
+	AuthCodeURL(ctx context.Context, req AuthorizationRequest) (string, error)
+	// Exchange redeems the authorization code and returns the verified identity
+	Exchange(ctx context.Context, req ExchangeRequest) (*Identity, error)
+}
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates a registry of the given providers
+func NewRegistry(providers ...Provider) *Registry {
+	registry := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// Names returns the names of the configured providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateCodeVerifier returns a random PKCE code verifier (RFC 7636)
+func GenerateCodeVerifier() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallengeS256 derives the S256 PKCE code challenge of a verifier
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
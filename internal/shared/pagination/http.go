@@ -0,0 +1,149 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CursorParams selects one page of a cursor-paginated listing
+type CursorParams struct {
+	Cursor *Cursor // Nil for the first page
+	Limit  int
+}
+
+// BindParams reads page, per_page and count from the query string
+// Missing values fall back to page 1, defaultPerPage and an exact count; invalid ones are
+// reported together as a validation error
+func BindParams(c *gin.Context, defaultPerPage int) (Params, error) {
+	params := Params{Page: 1, PerPage: defaultPerPage, Count: CountExact}
+	var fieldErrors []validators.ValidationError
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "page", Message: "The page must be an integer of at least 1."})
+		}
+		params.Page = page
+	}
+
+	if value := c.Query("per_page"); value != "" {
+		perPage, message := parseSize("per_page", value)
+		if message != "" {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "per_page", Message: message})
+		}
+		params.PerPage = perPage
+	}
+
+	if value := c.Query("count"); value != "" {
+		params.Count = CountMode(value)
+		if !params.Count.IsValid() {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "count", Message: "The selected count is invalid."})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return Params{}, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return params, nil
+}
+
+// BindCursorParams reads cursor and limit from the query string
+// A missing limit falls back to defaultLimit; invalid values are reported together as a validation error
+func BindCursorParams(c *gin.Context, defaultLimit int) (CursorParams, error) {
+	params := CursorParams{Limit: defaultLimit}
+	var fieldErrors []validators.ValidationError
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "cursor", Message: "The cursor is invalid."})
+		}
+		params.Cursor = &cursor
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, message := parseSize("limit", value)
+		if message != "" {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: "limit", Message: message})
+		}
+		params.Limit = limit
+	}
+
+	if len(fieldErrors) > 0 {
+		return CursorParams{}, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return params, nil
+}
+
+// SetLinkHeader adds an RFC 8288 Link header with the first, prev, next and last pages
+// The links keep the rest of the request's query string, so filters and sorting carry over
+func SetLinkHeader(c *gin.Context, meta Meta) {
+	links := []pageLink{{rel: "first", set: map[string]string{"page": "1"}}}
+	if meta.Page > 1 {
+		links = append(links, pageLink{rel: "prev", set: map[string]string{"page": strconv.Itoa(meta.Page - 1)}})
+	}
+	if meta.HasMore {
+		links = append(links, pageLink{rel: "next", set: map[string]string{"page": strconv.Itoa(meta.Page + 1)}})
+	}
+	if meta.TotalPages != nil && *meta.TotalPages > 0 {
+		links = append(links, pageLink{rel: "last", set: map[string]string{"page": strconv.Itoa(*meta.TotalPages)}})
+	}
+	setLinks(c, links)
+}
+
+// SetCursorLinkHeader adds an RFC 8288 Link header with the first, prev and next pages of a cursor listing
+func SetCursorLinkHeader(c *gin.Context, nextCursor, prevCursor *string) {
+	links := []pageLink{{rel: "first", set: map[string]string{"cursor": ""}}}
+	if prevCursor != nil {
+		links = append(links, pageLink{rel: "prev", set: map[string]string{"cursor": *prevCursor}})
+	}
+	if nextCursor != nil {
+		links = append(links, pageLink{rel: "next", set: map[string]string{"cursor": *nextCursor}})
+	}
+	setLinks(c, links)
+}
+
+// pageLink is one Link header entry; set overrides query parameters, and empty values remove them
+type pageLink struct {
+	rel string
+	set map[string]string
+}
+
+// setLinks writes the links as relative references to the request path
+func setLinks(c *gin.Context, links []pageLink) {
+	entries := make([]string, 0, len(links))
+	for _, link := range links {
+		query := url.Values{}
+		for key, values := range c.Request.URL.Query() {
+			query[key] = values
+		}
+		for key, value := range link.set {
+			if value == "" {
+				query.Del(key)
+			} else {
+				query.Set(key, value)
+			}
+		}
+
+		target := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		entries = append(entries, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+	c.Header("Link", strings.Join(entries, ", "))
+}
+
+// parseSize reads a page size between 1 and MaxPerPage
+// A non-empty message describes why the value was rejected
+func parseSize(name, value string) (int, string) {
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 || size > MaxPerPage {
+		return 0, fmt.Sprintf("The %s must be an integer between 1 and %d.", name, MaxPerPage)
+	}
+	return size, ""
+}
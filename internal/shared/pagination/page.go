@@ -0,0 +1,164 @@
+package pagination
+
+import (
+	"gin/internal/shared/utils/transformer"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPerPage is the page size used when none is given
+	DefaultPerPage = 10
+	// MaxPerPage bounds the page size of every listing
+	MaxPerPage = 100
+)
+
+// Params selects one page of an offset-paginated listing
+type Params struct {
+	Page    int
+	PerPage int
+	Count   CountMode
+}
+
+// Offset returns the number of items before the page
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// normalized fills in defaults for params that were not built by BindParams
+func (p Params) normalized() Params {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = DefaultPerPage
+	}
+	if p.PerPage > MaxPerPage {
+		p.PerPage = MaxPerPage
+	}
+	if !p.Count.IsValid() {
+		p.Count = CountExact
+	}
+	return p
+}
+
+// Meta describes where a page sits in its listing
+type Meta struct {
+	Page           int    `json:"page"`
+	TotalPages     *int   `json:"totalPages,omitempty"` // Omitted when the count was skipped
+	PerPage        int    `json:"perPage"`
+	TotalItems     *int64 `json:"totalItems,omitempty"` // Omitted when the count was skipped
+	TotalEstimated bool   `json:"totalEstimated,omitempty"`
+	HasMore        bool   `json:"hasMore"`
+}
+
+// Page is one page of an offset-paginated listing
+type Page[T any] struct {
+	Items []T  `json:"items"`
+	Meta  Meta `json:"meta"`
+}
+
+// NewPage builds a page and its meta; total is nil when the count was skipped
+func NewPage[T any](items []T, params Params, total *int64, estimated bool, hasMore bool) Page[T] {
+	params = params.normalized()
+	if items == nil {
+		items = []T{}
+	}
+
+	meta := Meta{
+		Page:           params.Page,
+		PerPage:        params.PerPage,
+		TotalItems:     total,
+		TotalEstimated: estimated,
+		HasMore:        hasMore,
+	}
+
+	// Calculate total pages
+	if total != nil {
+		totalPages := int((*total + int64(params.PerPage) - 1) / int64(params.PerPage))
+		meta.TotalPages = &totalPages
+	}
+	return Page[T]{Items: items, Meta: meta}
+}
+
+// Map converts the items of a page, e.g. from models to DTOs, keeping its meta
+func Map[T, U any](page Page[T], transformFn transformer.TransformFunc[T, U]) Page[U] {
+	return Page[U]{
+		Items: transformer.TransformCollection(page.Items, transformFn),
+		Meta:  page.Meta,
+	}
+}
+
+// CursorMeta describes a page of a cursor-paginated listing; a null cursor means there is no page that way
+type CursorMeta struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
+}
+
+// CursorPage is one page of a cursor-paginated listing as sent to clients
+type CursorPage[T any] struct {
+	Items []T        `json:"items"`
+	Meta  CursorMeta `json:"meta"`
+}
+
+// NewCursorPage builds the page and its meta from a cursor result fetched with the given limit
+func NewCursorPage[T any](result CursorResult[T], limit int) CursorPage[T] {
+	items := result.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	return CursorPage[T]{
+		Items: items,
+		Meta: CursorMeta{
+			Limit:      limit,
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+		},
+	}
+}
+
+// MapCursor converts the items of a cursor page, e.g. from models to DTOs, keeping its meta
+func MapCursor[T, U any](page CursorPage[T], transformFn transformer.TransformFunc[T, U]) CursorPage[U] {
+	return CursorPage[U]{
+		Items: transformer.TransformCollection(page.Items, transformFn),
+		Meta:  page.Meta,
+	}
+}
+
+// Paginate loads the page of query selected by params
+// The query carries the model, filters and order; the total is counted exactly, estimated from
+// the query plan or skipped as params.Count asks, and one extra row tells whether another page follows
+func Paginate[T any](query *gorm.DB, params Params) (Page[T], error) {
+	params = params.normalized()
+
+	var total *int64
+	switch params.Count {
+	case CountNone:
+	case CountEstimate:
+		estimate, err := EstimateCount(query.Session(&gorm.Session{}))
+		if err != nil {
+			return Page[T]{}, err
+		}
+		total = &estimate
+	default:
+		var count int64
+		if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return Page[T]{}, err
+		}
+		total = &count
+	}
+
+	var items []T
+	err := query.Session(&gorm.Session{}).Offset(params.Offset()).Limit(params.PerPage + 1).Find(&items).Error
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	hasMore := len(items) > params.PerPage
+	if hasMore {
+		items = items[:params.PerPage]
+	}
+	return NewPage(items, params, total, params.Count == CountEstimate, hasMore), nil
+}
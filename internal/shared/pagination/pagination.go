@@ -0,0 +1,151 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CountMode tells offset pagination how to report the total number of items
+type CountMode string
+
+const (
+	// CountExact runs a COUNT(*) over the matching rows
+	CountExact CountMode = "exact"
+	// CountEstimate reads the planner's row estimate, which stays cheap on large tables
+	CountEstimate CountMode = "estimate"
+	// CountNone skips the total; clients page until HasMore is false
+	CountNone CountMode = "none"
+)
+
+// IsValid reports whether the count mode is one of the known modes
+func (m CountMode) IsValid() bool {
+	switch m {
+	case CountExact, CountEstimate, CountNone:
+		return true
+	}
+	return false
+}
+
+// ErrInvalidCursor is returned for cursors that were not issued by EncodeCursor
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a listing ordered by a unique, time-sortable key such as a ULID
+// It is handed to clients as an opaque string
+type Cursor struct {
+	ID     string `json:"id"`
+	Before bool   `json:"b,omitempty"` // The page ends before ID instead of starting after it
+}
+
+// CursorResult is one page of a cursor-paginated listing
+type CursorResult[T any] struct {
+	Items      []T
+	NextCursor *string // Nil on the last page
+	PrevCursor *string // Nil on the first page
+}
+
+// EncodeCursor turns a cursor into an opaque, URL-safe string
+func EncodeCursor(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(value string) (Cursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Keyset is a GORM scope selecting up to limit+1 rows after (or before) the cursor in key order
+// The extra row tells whether another page follows; pass the rows to NewCursorResult to trim it
+// Rows of a Before page come back in reverse order, which NewCursorResult restores
+func Keyset(column string, cursor *Cursor, limit int, desc bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		key := clause.Column{Name: column}
+		backward := cursor != nil && cursor.Before
+
+		// Walking backwards flips both the comparison and the order
+		ascending := desc == backward
+		if cursor != nil {
+			if ascending {
+				db = db.Where(clause.Gt{Column: key, Value: cursor.ID})
+			} else {
+				db = db.Where(clause.Lt{Column: key, Value: cursor.ID})
+			}
+		}
+
+		return db.Order(clause.OrderByColumn{Column: key, Desc: !ascending}).Limit(limit + 1)
+	}
+}
+
+// NewCursorResult turns the rows fetched with Keyset into a page and the cursors around it
+func NewCursorResult[T any](rows []T, key func(T) string, cursor *Cursor, limit int) CursorResult[T] {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	backward := cursor != nil && cursor.Before
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	result := CursorResult[T]{Items: rows}
+	if len(rows) == 0 {
+		return result
+	}
+
+	// Going forward there is a previous page whenever we started from a cursor,
+	// and going backward there is always a next page: the one we came from
+	if backward || hasMore {
+		next := EncodeCursor(Cursor{ID: key(rows[len(rows)-1])})
+		result.NextCursor = &next
+	}
+	if cursor != nil && (!backward || hasMore) {
+		prev := EncodeCursor(Cursor{ID: key(rows[0]), Before: true})
+		result.PrevCursor = &prev
+	}
+	return result
+}
+
+// EstimateCount returns the planner's estimate of the rows the query would return
+// It runs EXPLAIN instead of COUNT(*), so it is cheap but only as accurate as the table statistics
+// The query must have a model or table set, e.g. db.Model(&User{}).Scopes(spec.Filter)
+func EstimateCount(query *gorm.DB) (int64, error) {
+	dryRun := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{})
+	if dryRun.Error != nil {
+		return 0, dryRun.Error
+	}
+
+	var plan string
+	row := query.Session(&gorm.Session{NewDB: true}).Raw("EXPLAIN (FORMAT JSON) "+dryRun.Statement.SQL.String(), dryRun.Statement.Vars...).Row()
+	if err := row.Scan(&plan); err != nil {
+		return 0, err
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil {
+		return 0, fmt.Errorf("parse query plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, errors.New("parse query plan: empty plan")
+	}
+	return int64(explained[0].Plan.Rows), nil
+}
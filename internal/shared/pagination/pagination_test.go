@@ -0,0 +1,189 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type testRecord struct {
+	ID string
+}
+
+func recordID(r testRecord) string {
+	return r.ID
+}
+
+// keysetSQL renders the query the Keyset scope produces, without a database connection
+func keysetSQL(t *testing.T, cursor *Cursor, desc bool) (string, []interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry-run database: %v", err)
+	}
+	var records []testRecord
+	statement := db.Table("records").Scopes(Keyset("id", cursor, 2, desc)).Find(&records).Statement
+	return statement.SQL.String(), statement.Vars
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{ID: "01J9ZK3M4N5P6Q7R8S9T0V1W2X", Before: true}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+	if err != nil || decoded != cursor {
+		t.Fatalf("DecodeCursor = %+v (err %v), want %+v", decoded, err, cursor)
+	}
+
+	for _, value := range []string{"", "not a cursor!", "e30"} {
+		if _, err := DecodeCursor(value); err != ErrInvalidCursor {
+			t.Fatalf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", value, err)
+		}
+	}
+}
+
+func TestKeysetScope(t *testing.T) {
+	cases := []struct {
+		name     string
+		cursor   *Cursor
+		desc     bool
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{name: "first page", wantSQL: `SELECT * FROM "records" ORDER BY "id" LIMIT $1`, wantVars: []interface{}{3}},
+		{name: "next page", cursor: &Cursor{ID: "b"}, wantSQL: `SELECT * FROM "records" WHERE "id" > $1 ORDER BY "id" LIMIT $2`, wantVars: []interface{}{"b", 3}},
+		{name: "previous page", cursor: &Cursor{ID: "b", Before: true}, wantSQL: `SELECT * FROM "records" WHERE "id" < $1 ORDER BY "id" DESC LIMIT $2`, wantVars: []interface{}{"b", 3}},
+		{name: "next page, newest first", cursor: &Cursor{ID: "b"}, desc: true, wantSQL: `SELECT * FROM "records" WHERE "id" < $1 ORDER BY "id" DESC LIMIT $2`, wantVars: []interface{}{"b", 3}},
+		{name: "previous page, newest first", cursor: &Cursor{ID: "b", Before: true}, desc: true, wantSQL: `SELECT * FROM "records" WHERE "id" > $1 ORDER BY "id" LIMIT $2`, wantVars: []interface{}{"b", 3}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, vars := keysetSQL(t, tc.cursor, tc.desc)
+			if sql != tc.wantSQL || !reflect.DeepEqual(vars, tc.wantVars) {
+				t.Fatalf("query = %s %v, want %s %v", sql, vars, tc.wantSQL, tc.wantVars)
+			}
+		})
+	}
+}
+
+func TestNewCursorResult(t *testing.T) {
+	rows := func(ids ...string) []testRecord {
+		records := make([]testRecord, 0, len(ids))
+		for _, id := range ids {
+			records = append(records, testRecord{ID: id})
+		}
+		return records
+	}
+	cursorFor := func(id string, before bool) *string {
+		encoded := EncodeCursor(Cursor{ID: id, Before: before})
+		return &encoded
+	}
+
+	cases := []struct {
+		name     string
+		rows     []testRecord
+		cursor   *Cursor
+		wantIDs  []testRecord
+		wantNext *string
+		wantPrev *string
+	}{
+		{name: "first page with more", rows: rows("a", "b", "c"), wantIDs: rows("a", "b"), wantNext: cursorFor("b", false)},
+		{name: "only page", rows: rows("a"), wantIDs: rows("a")},
+		{name: "middle page", rows: rows("c", "d", "e"), cursor: &Cursor{ID: "b"}, wantIDs: rows("c", "d"), wantNext: cursorFor("d", false), wantPrev: cursorFor("c", true)},
+		{name: "last page", rows: rows("e"), cursor: &Cursor{ID: "d"}, wantIDs: rows("e"), wantPrev: cursorFor("e", true)},
+		{name: "previous page with more", rows: rows("d", "c", "b"), cursor: &Cursor{ID: "e", Before: true}, wantIDs: rows("c", "d"), wantNext: cursorFor("d", false), wantPrev: cursorFor("c", true)},
+		{name: "back to the first page", rows: rows("b", "a"), cursor: &Cursor{ID: "c", Before: true}, wantIDs: rows("a", "b"), wantNext: cursorFor("b", false)},
+		{name: "empty", rows: rows(), cursor: &Cursor{ID: "z"}, wantIDs: rows()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := NewCursorResult(tc.rows, recordID, tc.cursor, 2)
+			if !reflect.DeepEqual(page.Items, tc.wantIDs) {
+				t.Fatalf("items = %v, want %v", page.Items, tc.wantIDs)
+			}
+			if !reflect.DeepEqual(page.NextCursor, tc.wantNext) || !reflect.DeepEqual(page.PrevCursor, tc.wantPrev) {
+				t.Fatalf("cursors = (%v, %v), want (%v, %v)", page.NextCursor, page.PrevCursor, tc.wantNext, tc.wantPrev)
+			}
+		})
+	}
+}
+
+// queryContext builds a gin context for a GET request with the given query string
+func queryContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+query, nil)
+	return c
+}
+
+func TestBindParams(t *testing.T) {
+	params, err := BindParams(queryContext(""), 20)
+	if err != nil || params != (Params{Page: 1, PerPage: 20, Count: CountExact}) {
+		t.Fatalf("defaults = %+v (err %v)", params, err)
+	}
+
+	params, err = BindParams(queryContext("page=3&per_page=50&count=none"), 20)
+	if err != nil || params != (Params{Page: 3, PerPage: 50, Count: CountNone}) || params.Offset() != 100 {
+		t.Fatalf("params = %+v (err %v)", params, err)
+	}
+
+	_, err = BindParams(queryContext("page=first&per_page=101&count=maybe"), 20)
+	var appErr exceptions.AppError
+	if !errors.As(err, &appErr) || appErr.Type != exceptions.ErrorTypeValidation {
+		t.Fatalf("BindParams error = %v, want a validation error", err)
+	}
+	want := []validators.ValidationError{
+		{Field: "page", Message: "The page must be an integer of at least 1."},
+		{Field: "per_page", Message: "The per_page must be an integer between 1 and 100."},
+		{Field: "count", Message: "The selected count is invalid."},
+	}
+	if !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v\nwant %+v", appErr.Data, want)
+	}
+}
+
+func TestNewPageAndMap(t *testing.T) {
+	total := int64(21)
+	page := NewPage([]testRecord{{ID: "a"}}, Params{Page: 3, PerPage: 10}, &total, true, false)
+	if page.Meta.TotalPages == nil || *page.Meta.TotalPages != 3 || page.Meta.PerPage != 10 || !page.Meta.TotalEstimated {
+		t.Fatalf("meta = %+v", page.Meta)
+	}
+
+	ids := Map(page, recordID)
+	if !reflect.DeepEqual(ids.Items, []string{"a"}) || ids.Meta != page.Meta {
+		t.Fatalf("mapped page = %+v", ids)
+	}
+
+	// Skipped counts leave the totals out, and empty pages still encode an empty list
+	empty := NewPage[testRecord](nil, Params{}, nil, false, false)
+	if empty.Items == nil || empty.Meta.TotalPages != nil || empty.Meta.Page != 1 || empty.Meta.PerPage != DefaultPerPage {
+		t.Fatalf("empty page = %+v", empty)
+	}
+}
+
+func TestNewCursorPageAndMapCursor(t *testing.T) {
+	next := EncodeCursor(Cursor{ID: "a"})
+	page := NewCursorPage(CursorResult[testRecord]{Items: []testRecord{{ID: "a"}}, NextCursor: &next}, 1)
+	if page.Meta.Limit != 1 || page.Meta.NextCursor != &next || page.Meta.PrevCursor != nil {
+		t.Fatalf("meta = %+v", page.Meta)
+	}
+
+	ids := MapCursor(page, recordID)
+	if !reflect.DeepEqual(ids.Items, []string{"a"}) || ids.Meta != page.Meta {
+		t.Fatalf("mapped page = %+v", ids)
+	}
+
+	// Empty pages still encode an empty list
+	empty := NewCursorPage(CursorResult[testRecord]{}, 10)
+	if empty.Items == nil || empty.Meta.NextCursor != nil {
+		t.Fatalf("empty page = %+v", empty)
+	}
+}
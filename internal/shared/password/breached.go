@@ -0,0 +1,80 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashPrefixLength is the number of SHA-1 hex characters naming a range file
+const hashPrefixLength = 5
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// BreachedList looks passwords up in known data breaches without calling an external service
+// It always checks a bundled list of the most common passwords; a directory of Have I Been Pwned
+// range files extends it with the full breach corpus, reading only the file for the hash prefix
+type BreachedList struct {
+	common  map[string]struct{}
+	hashDir string
+}
+
+// NewBreachedList creates a breached-password list; hashDir is optional
+// hashDir holds one file per SHA-1 prefix (e.g. "5BAA6.txt") with "SUFFIX:COUNT" lines,
+// the layout written by the haveibeenpwned-downloader tool
+func NewBreachedList(hashDir string) *BreachedList {
+	common := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswords, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			common[line] = struct{}{}
+		}
+	}
+
+	return &BreachedList{
+		common:  common,
+		hashDir: strings.TrimSpace(hashDir),
+	}
+}
+
+// Contains reports whether the password appears in the list
+// The bundled list is matched case-insensitively so trivial variations are rejected as well
+func (l *BreachedList) Contains(password string) (bool, error) {
+	if _, ok := l.common[strings.ToLower(password)]; ok {
+		return true, nil
+	}
+
+	if l.hashDir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	file, err := os.Open(filepath.Join(l.hashDir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("open breached password range %s: %w", prefix, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		candidate, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(candidate), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,110 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minPersonalInfoLength keeps short names and email local parts from rejecting unrelated passwords
+const minPersonalInfoLength = 3
+
+// Options configures the rules a new password must satisfy
+type Options struct {
+	MinLength          int
+	MaxLength          int // Counted in bytes, since bcrypt ignores everything after 72 bytes
+	RequireMixedCase   bool
+	RequireNumbers     bool
+	RequireSymbols     bool
+	RejectPersonalInfo bool // Rejects passwords containing the user's email address or name
+}
+
+// Policy validates new passwords against the configured rules and a breached-password list
+type Policy struct {
+	options  Options
+	breached *BreachedList
+}
+
+// NewPolicy creates a password policy; a nil breached list disables the breach check
+func NewPolicy(options Options, breached *BreachedList) *Policy {
+	return &Policy{
+		options:  options,
+		breached: breached,
+	}
+}
+
+// Validate returns a Laravel-style message for every rule the password breaks
+// personalInfo holds the email address and names of the account the password is for
+func (p *Policy) Validate(password string, personalInfo ...string) ([]string, error) {
+	var messages []string
+
+	if p.options.MinLength > 0 && utf8.RuneCountInString(password) < p.options.MinLength {
+		messages = append(messages, fmt.Sprintf("The password must be at least %d characters.", p.options.MinLength))
+	}
+	if p.options.MaxLength > 0 && len(password) > p.options.MaxLength {
+		messages = append(messages, fmt.Sprintf("The password may not be greater than %d characters.", p.options.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.options.RequireMixedCase && !(hasUpper && hasLower) {
+		messages = append(messages, "The password must contain at least one uppercase and one lowercase letter.")
+	}
+	if p.options.RequireNumbers && !hasNumber {
+		messages = append(messages, "The password must contain at least one number.")
+	}
+	if p.options.RequireSymbols && !hasSymbol {
+		messages = append(messages, "The password must contain at least one symbol.")
+	}
+
+	if p.options.RejectPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		messages = append(messages, "The password must not contain your email address or name.")
+	}
+
+	if p.breached != nil {
+		breached, err := p.breached.Contains(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			messages = append(messages, "The given password has appeared in a data leak. Please choose a different password.")
+		}
+	}
+
+	return messages, nil
+}
+
+// containsPersonalInfo reports whether the password contains an email address, its local part or a name
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lowered := strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(strings.TrimSpace(info))
+
+		candidates := strings.Fields(info)
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			candidates = append(candidates, local)
+		}
+
+		for _, candidate := range candidates {
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoLength && strings.Contains(lowered, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
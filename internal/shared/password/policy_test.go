@@ -0,0 +1,110 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	strict := NewPolicy(Options{
+		MinLength:          10,
+		MaxLength:          72,
+		RequireMixedCase:   true,
+		RequireNumbers:     true,
+		RequireSymbols:     true,
+		RejectPersonalInfo: true,
+	}, NewBreachedList(""))
+
+	cases := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{name: "strong", password: "Violet-Harbor-42"},
+		{
+			name:     "too short and plain",
+			password: "violet",
+			want: []string{
+				"The password must be at least 10 characters.",
+				"The password must contain at least one uppercase and one lowercase letter.",
+				"The password must contain at least one number.",
+				"The password must contain at least one symbol.",
+			},
+		},
+		{
+			name:     "too long",
+			password: "Aa1!" + strings.Repeat("x", 69),
+			want:     []string{"The password may not be greater than 72 characters."},
+		},
+		{
+			name:     "contains email local part",
+			password: "Jane.Doe-2024!",
+			want:     []string{"The password must not contain your email address or name."},
+		},
+		{
+			name:     "contains name",
+			password: "Smithereens-42!",
+			want:     []string{"The password must not contain your email address or name."},
+		},
+		{
+			name:     "common password",
+			password: "P@ssw0rd",
+			want: []string{
+				"The password must be at least 10 characters.",
+				"The given password has appeared in a data leak. Please choose a different password.",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := strict.Validate(tc.password, "jane.doe@example.com", "Jane Smith")
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("messages = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPolicyIgnoresShortPersonalInfo(t *testing.T) {
+	policy := NewPolicy(Options{RejectPersonalInfo: true}, nil)
+
+	got, err := policy.Validate("bold-alpaca-rides", "al@example.com", "Al Bo")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("messages = %q, want none", got)
+	}
+}
+
+func TestBreachedListHashRangeFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	sum := sha1.Sum([]byte("correct horse battery staple"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n" + hash[5:] + ":368\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(rangeFile), 0o600); err != nil {
+		t.Fatalf("write range file: %v", err)
+	}
+
+	list := NewBreachedList(dir)
+
+	breached, err := list.Contains("correct horse battery staple")
+	if err != nil || !breached {
+		t.Fatalf("Contains(breached) = %v, %v; want true", breached, err)
+	}
+
+	breached, err = list.Contains("a passphrase nobody has leaked yet")
+	if err != nil || breached {
+		t.Fatalf("Contains(unknown) = %v, %v; want false", breached, err)
+	}
+}
@@ -0,0 +1,350 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/iancoleman/strcase"
+)
+
+// Content types accepted for PATCH requests
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7386
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+// AcceptPatch is the value of the Accept-Patch header (RFC 5789) advertising both formats
+const AcceptPatch = MergePatchContentType + ", " + JSONPatchContentType
+
+var validate = validator.New()
+
+// Field describes a member of a resource that clients may patch
+type Field struct {
+	Column   string // Database column the member maps onto
+	Nullable bool   // Whether null, or removing the member, clears the column
+	Rules    string // Validator tags checked against new values, e.g. "min=1,max=100"
+}
+
+// Schema lists the patchable members of a resource, keyed by their JSON name
+// Members outside the schema are rejected, so a patch never reaches other columns
+// Members may also be named in snake_case, as CaseConverterMiddleware rewrites the keys of merge patches
+type Schema map[string]Field
+
+// Document is the patchable state of a resource; members that are not set are absent
+type Document map[string]string
+
+// operation is one parsed JSON Patch operation
+type operation struct {
+	op        string
+	path      string // Member name the path points to
+	from      string // Member name the from pointer points to, for move and copy
+	value     string
+	valueNull bool
+}
+
+// CheckContentType rejects content types other than the two patch formats
+// Handlers should send an Accept-Patch header along with the error
+func CheckContentType(contentType string) error {
+	if contentType == MergePatchContentType || contentType == JSONPatchContentType {
+		return nil
+	}
+	message := fmt.Sprintf("PATCH requests must be sent as %s or %s.", MergePatchContentType, JSONPatchContentType)
+	return exceptions.UnsupportedMediaTypeError(message, nil)
+}
+
+// Apply applies a merge patch or a JSON patch, chosen by content type, to a copy of doc
+// Nothing is written: pass the result to Changes to get the column updates
+func (s Schema) Apply(contentType string, body []byte, doc Document) (Document, error) {
+	if err := CheckContentType(contentType); err != nil {
+		return nil, err
+	}
+	if contentType == MergePatchContentType {
+		return s.MergePatch(body, doc)
+	}
+	return s.JSONPatch(body, doc)
+}
+
+// MergePatch applies an RFC 7386 merge patch: members set in the patch replace the current
+// values, and null members are cleared
+func (s Schema) MergePatch(body []byte, doc Document) (Document, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil, exceptions.ValidationError("The merge patch must be a JSON object.", nil)
+	}
+
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	patched := doc.clone()
+	var fieldErrors []validators.ValidationError
+	for _, key := range names {
+		name, ok := s.resolve(key)
+		if !ok {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: key, Message: fmt.Sprintf("The %s field cannot be patched.", key)})
+			continue
+		}
+		value, isNull, ok := decodeValue(members[key])
+		if !ok {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: name, Message: fmt.Sprintf("The %s must be a string or null.", name)})
+			continue
+		}
+		if isNull {
+			delete(patched, name)
+		} else {
+			patched[name] = value
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return patched, nil
+}
+
+// JSONPatch applies an RFC 6902 JSON patch, one operation after another
+// Every operation is validated before any is applied, and a failing operation discards the whole patch
+// A failed test operation is reported as a conflict; a member that is not set tests equal to null
+func (s Schema) JSONPatch(body []byte, doc Document) (Document, error) {
+	var rawOperations []json.RawMessage
+	if err := json.Unmarshal(body, &rawOperations); err != nil || rawOperations == nil {
+		return nil, exceptions.ValidationError("The JSON patch must be an array of operations.", nil)
+	}
+
+	operations := make([]operation, len(rawOperations))
+	var fieldErrors []validators.ValidationError
+	for i, raw := range rawOperations {
+		op, errs := s.parseOperation(i, raw)
+		operations[i] = op
+		fieldErrors = append(fieldErrors, errs...)
+	}
+	if len(fieldErrors) > 0 {
+		return nil, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+
+	patched := doc.clone()
+	for i, op := range operations {
+		if err := patched.apply(i, op); err != nil {
+			return nil, err
+		}
+	}
+	return patched, nil
+}
+
+// Changes compares a patched document with the original and returns the column updates
+// Changed values are checked against their field rules; cleared members map onto nil
+func (s Schema) Changes(before, after Document) (map[string]interface{}, error) {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updates := make(map[string]interface{})
+	var fieldErrors []validators.ValidationError
+	for _, name := range names {
+		field := s[name]
+		oldValue, wasSet := before[name]
+		newValue, isSet := after[name]
+		if wasSet == isSet && oldValue == newValue {
+			continue
+		}
+
+		if !isSet {
+			if !field.Nullable {
+				fieldErrors = append(fieldErrors, validators.ValidationError{Field: name, Message: fmt.Sprintf("The %s field cannot be null.", name)})
+				continue
+			}
+			updates[field.Column] = nil
+			continue
+		}
+
+		if field.Rules != "" {
+			if err := validate.Var(newValue, field.Rules); err != nil {
+				fieldErrors = append(fieldErrors, validators.ValidationError{Field: name, Message: ruleMessage(name, err)})
+				continue
+			}
+		}
+		updates[field.Column] = newValue
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return updates, nil
+}
+
+// parseOperation reads and validates the operation at index i
+func (s Schema) parseOperation(i int, raw json.RawMessage) (operation, []validators.ValidationError) {
+	var op operation
+	var fieldErrors []validators.ValidationError
+	fail := func(member, message string) {
+		field := fmt.Sprintf("%d.%s", i, member)
+		fieldErrors = append(fieldErrors, validators.ValidationError{Field: field, Message: fmt.Sprintf(message, field)})
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil || members == nil {
+		return op, []validators.ValidationError{{Field: fmt.Sprint(i), Message: fmt.Sprintf("The operation %d must be a JSON object.", i)}}
+	}
+
+	if err := json.Unmarshal(members["op"], &op.op); err != nil {
+		fail("op", "The %s field is required.")
+	} else if !isOperation(op.op) {
+		fail("op", "The %s must be one of add, remove, replace, move, copy or test.")
+	}
+
+	var ok bool
+	if op.path, ok = s.member(members["path"]); !ok {
+		fail("path", "The %s must point to a patchable field.")
+	}
+
+	switch op.op {
+	case "move", "copy":
+		if op.from, ok = s.member(members["from"]); !ok {
+			fail("from", "The %s must point to a patchable field.")
+		}
+	case "add", "replace", "test":
+		value, present := members["value"]
+		if !present {
+			fail("value", "The %s field is required.")
+		} else if op.value, op.valueNull, ok = decodeValue(value); !ok {
+			fail("value", "The %s must be a string or null.")
+		}
+	}
+
+	return op, fieldErrors
+}
+
+// member resolves a JSON pointer such as "/phone" to a member of the schema
+func (s Schema) member(raw json.RawMessage) (string, bool) {
+	var pointer string
+	if err := json.Unmarshal(raw, &pointer); err != nil || !strings.HasPrefix(pointer, "/") {
+		return "", false
+	}
+	name := strings.TrimPrefix(pointer, "/")
+	if strings.Contains(name, "/") {
+		return "", false
+	}
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+	return s.resolve(name)
+}
+
+// resolve finds a member by its schema name or by the snake_case form of that name, e.g. first_name
+func (s Schema) resolve(name string) (string, bool) {
+	if _, ok := s[name]; ok {
+		return name, true
+	}
+	camel := strcase.ToLowerCamel(name)
+	if _, ok := s[camel]; ok && strcase.ToSnake(camel) == name {
+		return camel, true
+	}
+	return "", false
+}
+
+// apply runs the validated operation at index i against the document
+func (d Document) apply(i int, op operation) error {
+	notSet := func(member, name string) error {
+		return exceptions.ValidationError("The given data was invalid.", nil, []validators.ValidationError{
+			{Field: fmt.Sprintf("%d.%s", i, member), Message: fmt.Sprintf("The %s field is not set.", name)},
+		})
+	}
+
+	switch op.op {
+	case "add":
+		d.set(op.path, op.value, op.valueNull)
+	case "remove":
+		if _, ok := d[op.path]; !ok {
+			return notSet("path", op.path)
+		}
+		delete(d, op.path)
+	case "replace":
+		if _, ok := d[op.path]; !ok {
+			return notSet("path", op.path)
+		}
+		d.set(op.path, op.value, op.valueNull)
+	case "move", "copy":
+		value, ok := d[op.from]
+		if !ok {
+			return notSet("from", op.from)
+		}
+		if op.op == "move" {
+			delete(d, op.from)
+		}
+		d[op.path] = value
+	case "test":
+		current, ok := d[op.path]
+		if ok == op.valueNull || (ok && current != op.value) {
+			return exceptions.ConflictError(fmt.Sprintf("The test operation %d failed; the %s field has changed.", i, op.path), nil)
+		}
+	}
+	return nil
+}
+
+// set stores a value, or clears the member for null
+func (d Document) set(name, value string, isNull bool) {
+	if isNull {
+		delete(d, name)
+		return
+	}
+	d[name] = value
+}
+
+// clone copies the document so a failed patch leaves the original untouched
+func (d Document) clone() Document {
+	copied := make(Document, len(d))
+	for name, value := range d {
+		copied[name] = value
+	}
+	return copied
+}
+
+// decodeValue reads a JSON string or null
+func decodeValue(raw json.RawMessage) (string, bool, bool) {
+	if string(raw) == "null" {
+		return "", true, true
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", false, false
+	}
+	return value, false, true
+}
+
+// isOperation reports whether op is one of the RFC 6902 operations
+func isOperation(op string) bool {
+	switch op {
+	case "add", "remove", "replace", "move", "copy", "test":
+		return true
+	}
+	return false
+}
+
+// ruleMessage turns the first failed validator rule into a readable message
+func ruleMessage(name string, err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) == 0 {
+		return fmt.Sprintf("The %s field is invalid.", name)
+	}
+
+	rule := validationErrors[0]
+	switch rule.Tag() {
+	case "email":
+		return fmt.Sprintf("The %s must be a valid email address.", name)
+	case "min":
+		return fmt.Sprintf("The %s must be at least %s characters.", name, rule.Param())
+	case "max":
+		return fmt.Sprintf("The %s may not be greater than %s characters.", name, rule.Param())
+	default:
+		return fmt.Sprintf("The %s field is invalid.", name)
+	}
+}
@@ -0,0 +1,177 @@
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+)
+
+func testSchema() Schema {
+	return Schema{
+		"firstName": {Column: "first_name", Nullable: true, Rules: "min=1,max=10"},
+		"email":     {Column: "email", Rules: "email"},
+		"phone":     {Column: "phone", Nullable: true},
+		"address":   {Column: "address", Nullable: true},
+	}
+}
+
+func testDocument() Document {
+	return Document{"firstName": "Jane", "email": "jane@example.com", "phone": "+9779800000000"}
+}
+
+// appError unwraps the AppError of a failed patch
+func appError(t *testing.T, err error, want exceptions.ErrorType) exceptions.AppError {
+	t.Helper()
+	var appErr exceptions.AppError
+	if !errors.As(err, &appErr) || appErr.Type != want {
+		t.Fatalf("error = %v, want a %s error", err, want)
+	}
+	return appErr
+}
+
+func TestMergePatch(t *testing.T) {
+	schema := testSchema()
+	before := testDocument()
+
+	after, err := schema.Apply(MergePatchContentType, []byte(`{"firstName":"Janet","phone":null,"address":"Kathmandu"}`), before)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	updates, err := schema.Changes(before, after)
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+
+	want := map[string]interface{}{"first_name": "Janet", "phone": nil, "address": "Kathmandu"}
+	if !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+	if before["phone"] != "+9779800000000" {
+		t.Fatal("the original document was modified")
+	}
+}
+
+func TestMergePatchAcceptsSnakeCaseMembers(t *testing.T) {
+	after, err := testSchema().MergePatch([]byte(`{"first_name":"Janet"}`), testDocument())
+	if err != nil {
+		t.Fatalf("MergePatch: %v", err)
+	}
+	if after["firstName"] != "Janet" {
+		t.Fatalf("firstName = %q, want Janet", after["firstName"])
+	}
+
+	// Only the exact snake_case form is an alias
+	_, err = testSchema().MergePatch([]byte(`{"FirstName":"Janet"}`), testDocument())
+	appErr := appError(t, err, exceptions.ErrorTypeValidation)
+	if want := []validators.ValidationError{{Field: "FirstName", Message: "The FirstName field cannot be patched."}}; !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v, want %+v", appErr.Data, want)
+	}
+}
+
+func TestMergePatchRejectsUnknownAndInvalidMembers(t *testing.T) {
+	_, err := testSchema().MergePatch([]byte(`{"password":"secret","phone":42}`), testDocument())
+
+	appErr := appError(t, err, exceptions.ErrorTypeValidation)
+	want := []validators.ValidationError{
+		{Field: "password", Message: "The password field cannot be patched."},
+		{Field: "phone", Message: "The phone must be a string or null."},
+	}
+	if !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v, want %+v", appErr.Data, want)
+	}
+
+	if _, err := testSchema().MergePatch([]byte(`[]`), testDocument()); err == nil {
+		t.Fatal("a merge patch that is not an object was accepted")
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	schema := testSchema()
+	before := testDocument()
+	body := `[
+		{"op": "test", "path": "/firstName", "value": "Jane"},
+		{"op": "test", "path": "/address", "value": null},
+		{"op": "copy", "from": "/firstName", "path": "/address"},
+		{"op": "replace", "path": "/firstName", "value": "Janet"},
+		{"op": "move", "from": "/phone", "path": "/address"},
+		{"op": "add", "path": "/phone", "value": "+9779811111111"},
+		{"op": "remove", "path": "/phone"}
+	]`
+
+	after, err := schema.Apply(JSONPatchContentType, []byte(body), before)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	updates, err := schema.Changes(before, after)
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+
+	want := map[string]interface{}{"first_name": "Janet", "phone": nil, "address": "+9779800000000"}
+	if !reflect.DeepEqual(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+}
+
+func TestJSONPatchValidatesEveryOperation(t *testing.T) {
+	body := `[
+		{"op": "replace", "path": "/firstName", "value": "Janet"},
+		{"op": "rename", "path": "/firstName"},
+		{"op": "add", "path": "/password", "value": "secret"},
+		{"op": "copy", "path": "/address"},
+		{"op": "replace", "path": "/phone"},
+		"remove"
+	]`
+
+	_, err := testSchema().JSONPatch([]byte(body), testDocument())
+
+	appErr := appError(t, err, exceptions.ErrorTypeValidation)
+	want := []validators.ValidationError{
+		{Field: "1.op", Message: "The 1.op must be one of add, remove, replace, move, copy or test."},
+		{Field: "2.path", Message: "The 2.path must point to a patchable field."},
+		{Field: "3.from", Message: "The 3.from must point to a patchable field."},
+		{Field: "4.value", Message: "The 4.value field is required."},
+		{Field: "5", Message: "The operation 5 must be a JSON object."},
+	}
+	if !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v\nwant %+v", appErr.Data, want)
+	}
+}
+
+func TestJSONPatchFailuresDiscardThePatch(t *testing.T) {
+	schema := testSchema()
+
+	_, err := schema.JSONPatch([]byte(`[{"op": "remove", "path": "/phone"}, {"op": "replace", "path": "/address", "value": "x"}]`), testDocument())
+	appErr := appError(t, err, exceptions.ErrorTypeValidation)
+	if want := []validators.ValidationError{{Field: "1.path", Message: "The address field is not set."}}; !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v, want %+v", appErr.Data, want)
+	}
+
+	_, err = schema.JSONPatch([]byte(`[{"op": "replace", "path": "/phone", "value": "+1"}, {"op": "test", "path": "/firstName", "value": "Joan"}]`), testDocument())
+	appError(t, err, exceptions.ErrorTypeConflict)
+}
+
+func TestChangesValidatesNewValues(t *testing.T) {
+	schema := testSchema()
+	before := testDocument()
+	after := Document{"firstName": "Bartholomew", "phone": "+9779800000000"}
+
+	_, err := schema.Changes(before, after)
+
+	appErr := appError(t, err, exceptions.ErrorTypeValidation)
+	want := []validators.ValidationError{
+		{Field: "email", Message: "The email field cannot be null."},
+		{Field: "firstName", Message: "The firstName may not be greater than 10 characters."},
+	}
+	if !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v, want %+v", appErr.Data, want)
+	}
+}
+
+func TestApplyRejectsOtherContentTypes(t *testing.T) {
+	_, err := testSchema().Apply("application/json", []byte(`{}`), testDocument())
+	appError(t, err, exceptions.ErrorTypeUnsupportedMediaType)
+}
@@ -0,0 +1,283 @@
+package queryspec
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSearchLength bounds the free-text search term
+const maxSearchLength = 100
+
+// FilterKind tells how a filter compares its column
+type FilterKind int
+
+const (
+	// Exact matches one of a comma-separated list of values, e.g. filter[status]=active,banned
+	Exact FilterKind = iota
+	// TimeRange bounds a timestamp column, e.g. filter[created_at][gte]=2026-01-01
+	TimeRange
+)
+
+// rangeOperators maps the operators accepted by TimeRange filters onto SQL comparisons
+var rangeOperators = map[string]func(column clause.Column, value time.Time) clause.Expression{
+	"gt": func(column clause.Column, value time.Time) clause.Expression {
+		return clause.Gt{Column: column, Value: value}
+	},
+	"gte": func(column clause.Column, value time.Time) clause.Expression {
+		return clause.Gte{Column: column, Value: value}
+	},
+	"lt": func(column clause.Column, value time.Time) clause.Expression {
+		return clause.Lt{Column: column, Value: value}
+	},
+	"lte": func(column clause.Column, value time.Time) clause.Expression {
+		return clause.Lte{Column: column, Value: value}
+	},
+}
+
+// Filter describes a column that may be filtered on
+type Filter struct {
+	Column  string
+	Kind    FilterKind
+	Allowed []string // Values accepted by an Exact filter; empty accepts any value
+}
+
+// Schema lists what a listing endpoint lets clients filter, sort and search on
+// Only columns named here ever reach SQL, and every value is bound as a parameter
+type Schema struct {
+	Filters     map[string]Filter // Keyed by the name used in filter[name]
+	Sorts       map[string]string // Sort keys mapped onto their columns
+	Search      []string          // Columns matched by the q search term
+	DefaultSort string            // Sort applied when none is given, e.g. "-created_at"
+	TieBreaker  string            // Unique column appended to every sort so pages are stable
+}
+
+// Spec is a parsed listing query; its Filter and Sort methods are GORM scopes
+// The zero value neither filters nor sorts
+type Spec struct {
+	conditions []clause.Expression
+	order      []clause.OrderByColumn
+}
+
+// Parse reads filter[...], sort and q from the query string
+// Unknown filters or sort keys and invalid values are reported together as a validation error
+func (s Schema) Parse(query url.Values) (Spec, error) {
+	var spec Spec
+	var fieldErrors []validators.ValidationError
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		condition, message := s.parseFilter(key, query[key])
+		if message != "" {
+			fieldErrors = append(fieldErrors, validators.ValidationError{Field: key, Message: message})
+			continue
+		}
+		spec.conditions = append(spec.conditions, condition)
+	}
+
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		if len(q) > maxSearchLength {
+			fieldErrors = append(fieldErrors, validators.ValidationError{
+				Field:   "q",
+				Message: fmt.Sprintf("The q may not be greater than %d characters.", maxSearchLength),
+			})
+		} else if len(s.Search) > 0 {
+			spec.conditions = append(spec.conditions, s.searchConditions(q)...)
+		}
+	}
+
+	sortParam := query.Get("sort")
+	if sortParam == "" {
+		sortParam = s.DefaultSort
+	}
+	order, message := s.parseSort(sortParam)
+	if message != "" {
+		fieldErrors = append(fieldErrors, validators.ValidationError{Field: "sort", Message: message})
+	}
+	spec.order = order
+
+	if len(fieldErrors) > 0 {
+		return Spec{}, exceptions.ValidationError("The given data was invalid.", nil, fieldErrors)
+	}
+	return spec, nil
+}
+
+// Filter is a GORM scope applying the parsed filters and search
+func (s Spec) Filter(db *gorm.DB) *gorm.DB {
+	for _, condition := range s.conditions {
+		db = db.Where(condition)
+	}
+	return db
+}
+
+// Sort is a GORM scope applying the parsed sort order
+func (s Spec) Sort(db *gorm.DB) *gorm.DB {
+	if len(s.order) == 0 {
+		return db
+	}
+	return db.Order(clause.OrderBy{Columns: s.order})
+}
+
+// parseFilter turns one filter[name] or filter[name][operator] parameter into a condition
+// A non-empty message describes why the parameter was rejected
+func (s Schema) parseFilter(key string, values []string) (clause.Expression, string) {
+	name, operator, ok := splitFilterKey(key)
+	if !ok {
+		return nil, fmt.Sprintf("The %s parameter is malformed.", key)
+	}
+
+	filter, ok := s.Filters[name]
+	if !ok {
+		return nil, fmt.Sprintf("Filtering on %s is not supported.", name)
+	}
+	column := clause.Column{Name: filter.Column}
+
+	switch filter.Kind {
+	case TimeRange:
+		compare, ok := rangeOperators[operator]
+		if !ok {
+			return nil, fmt.Sprintf("The %s filter needs one of the gt, gte, lt or lte operators.", name)
+		}
+		value, dateOnly, ok := parseTime(lastValue(values))
+		if !ok {
+			return nil, fmt.Sprintf("The %s must be a valid RFC 3339 timestamp or YYYY-MM-DD date.", key)
+		}
+		// A bare date covers the whole day, so lte includes it and gt starts after it
+		if dateOnly && (operator == "lte" || operator == "gt") {
+			value = value.AddDate(0, 0, 1)
+			if operator == "lte" {
+				compare = rangeOperators["lt"]
+			} else {
+				compare = rangeOperators["gte"]
+			}
+		}
+		return compare(column, value), ""
+	default:
+		if operator != "" {
+			return nil, fmt.Sprintf("The %s filter does not take an operator.", name)
+		}
+		accepted := make([]interface{}, 0)
+		for _, value := range splitValues(values) {
+			if len(filter.Allowed) > 0 && !slices.Contains(filter.Allowed, value) {
+				return nil, fmt.Sprintf("The selected %s is invalid.", key)
+			}
+			accepted = append(accepted, value)
+		}
+		if len(accepted) == 0 {
+			return nil, fmt.Sprintf("The %s must have a value.", key)
+		}
+		return clause.IN{Column: column, Values: accepted}, ""
+	}
+}
+
+// searchConditions matches every whitespace-separated term against any search column, ignoring case
+func (s Schema) searchConditions(q string) []clause.Expression {
+	terms := strings.Fields(q)
+	conditions := make([]clause.Expression, 0, len(terms))
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		matches := make([]clause.Expression, 0, len(s.Search))
+		for _, column := range s.Search {
+			matches = append(matches, clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{clause.Column{Name: column}, pattern}})
+		}
+		conditions = append(conditions, clause.Or(matches...))
+	}
+	return conditions
+}
+
+// parseSort reads a comma-separated list of sort keys; a leading "-" sorts descending
+func (s Schema) parseSort(param string) ([]clause.OrderByColumn, string) {
+	var order []clause.OrderByColumn
+	seen := make(map[string]bool)
+
+	if param != "" {
+		for _, key := range strings.Split(param, ",") {
+			desc := strings.HasPrefix(key, "-")
+			name := strings.TrimPrefix(key, "-")
+
+			column, ok := s.Sorts[name]
+			if !ok {
+				return nil, fmt.Sprintf("Sorting on %s is not supported.", name)
+			}
+			if seen[column] {
+				continue
+			}
+			seen[column] = true
+			order = append(order, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+		}
+	}
+
+	if s.TieBreaker != "" && !seen[s.TieBreaker] {
+		order = append(order, clause.OrderByColumn{Column: clause.Column{Name: s.TieBreaker}})
+	}
+	return order, ""
+}
+
+// splitFilterKey splits filter[name] and filter[name][operator]
+func splitFilterKey(key string) (string, string, bool) {
+	rest := strings.TrimPrefix(key, "filter[")
+	name, rest, ok := strings.Cut(rest, "]")
+	if !ok || name == "" {
+		return "", "", false
+	}
+	if rest == "" {
+		return name, "", true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") || len(rest) < 3 {
+		return "", "", false
+	}
+	return name, rest[1 : len(rest)-1], true
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates, which are read as midnight UTC
+func parseTime(value string) (time.Time, bool, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, true
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// splitValues flattens repeated and comma-separated parameter values, dropping empty ones
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// lastValue returns the last of the repeated values of a parameter
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[len(values)-1])
+}
+
+// escapeLike escapes the LIKE wildcards so a search term only matches literally
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
@@ -0,0 +1,106 @@
+package queryspec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	exceptions "gin/internal/shared/exception"
+	validators "gin/internal/shared/validator"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type testRecord struct {
+	ID string
+}
+
+func testSchema() Schema {
+	return Schema{
+		Filters: map[string]Filter{
+			"status":     {Column: "status", Allowed: []string{"active", "banned"}},
+			"country":    {Column: "country"},
+			"created_at": {Column: "created_at", Kind: TimeRange},
+		},
+		Sorts:      map[string]string{"created_at": "created_at", "name": "first_name"},
+		Search:     []string{"first_name", "email"},
+		TieBreaker: "id",
+	}
+}
+
+// querySQL renders the query a spec produces, without a database connection
+func querySQL(t *testing.T, spec Spec) (string, []interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry-run database: %v", err)
+	}
+	var records []testRecord
+	statement := db.Table("records").Scopes(spec.Filter, spec.Sort).Find(&records).Statement
+	return statement.SQL.String(), statement.Vars
+}
+
+func TestSchemaParseBuildsScopes(t *testing.T) {
+	query, _ := url.ParseQuery("filter[status]=active,banned&filter[created_at][gte]=2026-01-01&filter[created_at][lte]=2026-01-31&sort=-created_at,name&q=smi_th%20jo")
+	spec, err := testSchema().Parse(query)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sql, vars := querySQL(t, spec)
+
+	wantSQL := `SELECT * FROM "records" WHERE "created_at" >= $1 AND "created_at" < $2 AND "status" IN ($3,$4) AND ("first_name" ILIKE $5 OR "email" ILIKE $6) AND ("first_name" ILIKE $7 OR "email" ILIKE $8) ORDER BY "created_at" DESC,"first_name","id"`
+	if sql != wantSQL {
+		t.Fatalf("SQL =\n%s\nwant\n%s", sql, wantSQL)
+	}
+	if len(vars) != 8 {
+		t.Fatalf("vars = %v, want 8 values", vars)
+	}
+	// The lte bound on a bare date covers the whole day
+	if upper, ok := vars[1].(time.Time); !ok || !upper.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("upper bound = %v, want 2026-02-01", vars[1])
+	}
+	if vars[4] != `%smi\_th%` || vars[6] != "%jo%" {
+		t.Fatalf("search patterns = %v, %v", vars[4], vars[6])
+	}
+}
+
+func TestSchemaParseAppliesDefaultSort(t *testing.T) {
+	schema := testSchema()
+	schema.DefaultSort = "-created_at"
+
+	spec, err := schema.Parse(url.Values{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sql, _ := querySQL(t, spec)
+	if want := `SELECT * FROM "records" ORDER BY "created_at" DESC,"id"`; sql != want {
+		t.Fatalf("SQL = %s, want %s", sql, want)
+	}
+}
+
+func TestSchemaParseRejectsUnknownFields(t *testing.T) {
+	query, _ := url.ParseQuery("filter[password]=x&filter[status]=deleted&filter[created_at]=2026-01-01&filter[country][gte]=NP&filter[created_at][lt]=yesterday&sort=password&page=2")
+
+	_, err := testSchema().Parse(query)
+
+	var appErr exceptions.AppError
+	if !errors.As(err, &appErr) || appErr.Type != exceptions.ErrorTypeValidation {
+		t.Fatalf("Parse error = %v, want a validation error", err)
+	}
+	want := []validators.ValidationError{
+		{Field: "filter[country][gte]", Message: "The country filter does not take an operator."},
+		{Field: "filter[created_at]", Message: "The created_at filter needs one of the gt, gte, lt or lte operators."},
+		{Field: "filter[created_at][lt]", Message: "The filter[created_at][lt] must be a valid RFC 3339 timestamp or YYYY-MM-DD date."},
+		{Field: "filter[password]", Message: "Filtering on password is not supported."},
+		{Field: "filter[status]", Message: "The selected filter[status] is invalid."},
+		{Field: "sort", Message: "Sorting on password is not supported."},
+	}
+	if !reflect.DeepEqual(appErr.Data, want) {
+		t.Fatalf("errors = %+v\nwant %+v", appErr.Data, want)
+	}
+}
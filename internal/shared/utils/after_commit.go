@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+type afterCommitKey struct{}
+
+// afterCommitHooks collects the callbacks registered while a transaction is open
+type afterCommitHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// WithAfterCommit returns a copy of ctx that collects AfterCommit callbacks, and a function
+// that runs them. The transaction middleware calls it once the transaction has committed
+func WithAfterCommit(ctx context.Context) (context.Context, func()) {
+	pending := &afterCommitHooks{}
+	run := func() {
+		pending.mu.Lock()
+		hooks := pending.hooks
+		pending.hooks = nil
+		pending.mu.Unlock()
+
+		for _, hook := range hooks {
+			hook()
+		}
+	}
+	return context.WithValue(ctx, afterCommitKey{}, pending), run
+}
+
+// AfterCommit runs fn once the request transaction in ctx commits; it is dropped on rollback
+// Outside a transaction fn runs right away. Use it for side effects such as cache writes that
+// must not outlive a rolled back change
+func AfterCommit(ctx context.Context, fn func()) {
+	pending, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	pending.mu.Lock()
+	defer pending.mu.Unlock()
+	pending.hooks = append(pending.hooks, fn)
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RefreshTokenCookie holds the refresh token in cookie auth mode; it is HttpOnly and scoped to AuthCookiePath
+	RefreshTokenCookie = "refresh_token"
+	// CSRFTokenCookie holds the double-submit CSRF token; scripts read it and echo it in CSRFTokenHeader
+	CSRFTokenCookie = "csrf_token"
+	// CSRFTokenHeader carries the CSRF token on state-changing requests in cookie auth mode
+	CSRFTokenHeader = "X-CSRF-Token"
+	// AuthCookiePath limits the refresh token cookie to the authentication routes
+	AuthCookiePath = "/api/auth"
+)
+
+// AuthCookieOptions configures the cookie auth mode for browser clients
+// When enabled, refresh tokens are kept out of JavaScript in an HttpOnly cookie
+type AuthCookieOptions struct {
+	Enabled  bool
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// SetAuthCookies stores the refresh token and a new CSRF token in cookies and returns the CSRF token
+// The CSRF cookie is readable by scripts on every path so the client can send it back in a header
+func SetAuthCookies(c *gin.Context, options AuthCookieOptions, refreshToken string, maxAge time.Duration) (string, error) {
+	csrfToken, err := GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(c.Writer, options.cookie(RefreshTokenCookie, refreshToken, AuthCookiePath, int(maxAge.Seconds()), true))
+	http.SetCookie(c.Writer, options.cookie(CSRFTokenCookie, csrfToken, "/", int(maxAge.Seconds()), false))
+	return csrfToken, nil
+}
+
+// ClearAuthCookies expires the refresh token and CSRF cookies
+func ClearAuthCookies(c *gin.Context, options AuthCookieOptions) {
+	http.SetCookie(c.Writer, options.cookie(RefreshTokenCookie, "", AuthCookiePath, -1, true))
+	http.SetCookie(c.Writer, options.cookie(CSRFTokenCookie, "", "/", -1, false))
+}
+
+// RefreshTokenFromCookie returns the refresh token cookie of the request, if cookie auth mode is enabled
+func RefreshTokenFromCookie(c *gin.Context, options AuthCookieOptions) (string, bool) {
+	if !options.Enabled {
+		return "", false
+	}
+
+	token, err := c.Cookie(RefreshTokenCookie)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// cookie builds an auth cookie with the configured domain and security attributes
+func (o AuthCookieOptions) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   o.Domain,
+		MaxAge:   maxAge,
+		Secure:   o.Secure,
+		HttpOnly: httpOnly,
+		SameSite: o.SameSite,
+	}
+}
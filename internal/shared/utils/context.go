@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"gin/internal/shared/constant"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,6 +39,31 @@ func GetUserClaimsFromContext(c *gin.Context) (*JWTClaims, bool) {
 	return nil, false
 }
 
+// GetUserRoleFromContext extracts the authenticated user's role from the Gin context
+// This should be called after JWT middleware has validated the token
+func GetUserRoleFromContext(c *gin.Context) (constant.AccountTypeEnum, bool) {
+	role, exists := c.Get("user_role")
+	if !exists {
+		return "", false
+	}
+
+	if r, ok := role.(constant.AccountTypeEnum); ok {
+		return r, true
+	}
+
+	return "", false
+}
+
+// GetActorIDFromContext returns the admin acting as the authenticated user through impersonation
+// The second result is false for requests made by the user themselves
+func GetActorIDFromContext(c *gin.Context) (string, bool) {
+	claims, ok := GetUserClaimsFromContext(c)
+	if !ok || !claims.IsImpersonation() {
+		return "", false
+	}
+	return claims.Act.Subject, true
+}
+
 // RequireUserID extracts user ID from context and returns error if not found
 func RequireUserID(c *gin.Context) (string, error) {
 	userID, exists := GetUserIDFromContext(c)
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// encryptionVersion prefixes ciphertexts so the format can evolve
+const encryptionVersion = "v1:"
+
+// Encrypter encrypts small secrets at rest with AES-256-GCM
+type Encrypter struct {
+	aead cipher.AEAD
+}
+
+// NewEncrypter creates an encrypter from a 32-byte key
+func NewEncrypter(key []byte) (*Encrypter, error) {
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encrypter{aead: aead}, nil
+}
+
+// Encrypt returns the base64-encoded nonce and ciphertext of plaintext
+func (e *Encrypter) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := e.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptionVersion + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt; tampered or foreign ciphertexts are rejected
+func (e *Encrypter) Decrypt(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, encryptionVersion) {
+		return "", errors.New("unsupported ciphertext format")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, encryptionVersion))
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := e.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := e.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
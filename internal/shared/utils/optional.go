@@ -0,0 +1,13 @@
+package utils
+
+// OptionalTruncated returns nil for empty values and cuts long values to max bytes
+// Use it for nullable, fixed-width columns such as client IPs and user agents
+func OptionalTruncated(value string, max int) *string {
+	if value == "" {
+		return nil
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+	return &value
+}
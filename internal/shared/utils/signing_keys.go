@@ -0,0 +1,387 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// rsaKeyBits is the modulus size of generated RS256 keys
+const rsaKeyBits = 2048
+
+// SigningKey is an asymmetric key used to sign and verify JWTs
+// The key ID is a ULID, so it also records when the key was created
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// CreatedAt returns when the key was generated
+func (k *SigningKey) CreatedAt() time.Time {
+	id, err := ulid.ParseStrict(k.ID)
+	if err != nil {
+		return time.Time{}
+	}
+	return ulid.Time(id.Time())
+}
+
+// signingMethod returns the JWT signing method matching the key type
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// JWK is the public part of a signing key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set as published at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyRingOptions configures a KeyRing
+type KeyRingOptions struct {
+	// Algorithm of newly generated keys (RS256 or EdDSA)
+	Algorithm string
+	// Dir holds one PKCS#8 PEM file per key; empty keeps keys in memory only
+	Dir string
+	// RotationInterval is the age after which a new signing key is generated; zero disables rotation
+	RotationInterval time.Duration
+	// Retention is how long a retired key stays available for verification
+	Retention time.Duration
+}
+
+// KeyRing holds the signing keys of the API
+// The newest key signs new tokens; older keys are kept for verification until their retention ends
+type KeyRing struct {
+	mu      sync.RWMutex
+	options KeyRingOptions
+	keys    []*SigningKey // ordered oldest first
+}
+
+// NewKeyRing loads the keys stored in options.Dir and generates a signing key if none exists yet
+func NewKeyRing(options KeyRingOptions) (*KeyRing, error) {
+	if options.Algorithm != AlgorithmRS256 && options.Algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported key ring algorithm %q", options.Algorithm)
+	}
+
+	ring := &KeyRing{options: options}
+	if err := ring.reload(); err != nil {
+		return nil, err
+	}
+
+	if ring.Current() == nil {
+		if _, err := ring.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ring, nil
+}
+
+// Current returns the key used to sign new tokens
+func (r *KeyRing) Current() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].Algorithm == r.options.Algorithm {
+			return r.keys[i]
+		}
+	}
+	return nil
+}
+
+// Lookup returns the key with the given ID
+func (r *KeyRing) Lookup(kid string) (*SigningKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate generates a new signing key; previous keys remain available for verification
+func (r *KeyRing) Rotate() (*SigningKey, error) {
+	key, err := generateSigningKey(r.options.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.options.Dir != "" {
+		if err := writeSigningKey(r.options.Dir, key); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.keys = append(r.keys, key)
+	r.mu.Unlock()
+
+	return key, nil
+}
+
+// RotateIfDue picks up keys written by other instances, rotates when the signing key
+// is older than the rotation interval and drops retired keys past their retention
+func (r *KeyRing) RotateIfDue(now time.Time) (bool, error) {
+	if err := r.reload(); err != nil {
+		return false, err
+	}
+
+	rotated := false
+	current := r.Current()
+	if current == nil || (r.options.RotationInterval > 0 && now.Sub(current.CreatedAt()) >= r.options.RotationInterval) {
+		if _, err := r.Rotate(); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	return rotated, r.prune(now)
+}
+
+// Refresh picks up keys written by other instances and drops retired keys past their retention,
+// without rotating; instances that did not win the rotation lock call it instead of RotateIfDue
+func (r *KeyRing) Refresh(now time.Time) error {
+	if err := r.reload(); err != nil {
+		return err
+	}
+	return r.prune(now)
+}
+
+// JWKS returns the public keys of the ring, newest first
+func (r *KeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(r.keys))}
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if jwk, ok := publicJWK(r.keys[i]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// reload merges the keys stored on disk into the ring
+func (r *KeyRing) reload() error {
+	if r.options.Dir == "" {
+		return nil
+	}
+
+	loaded, err := readSigningKeys(r.options.Dir)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	known := make(map[string]bool, len(r.keys))
+	for _, key := range r.keys {
+		known[key.ID] = true
+	}
+	for _, key := range loaded {
+		if !known[key.ID] {
+			r.keys = append(r.keys, key)
+		}
+	}
+	sort.Slice(r.keys, func(i, j int) bool { return r.keys[i].ID < r.keys[j].ID })
+
+	return nil
+}
+
+// prune drops keys whose successor was created more than the retention period ago
+// Tokens signed with such a key have expired by then
+func (r *KeyRing) prune(now time.Time) error {
+	if r.options.Retention <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.keys[:0]
+	var removed []*SigningKey
+	for i, key := range r.keys {
+		if i < len(r.keys)-1 && now.Sub(r.keys[i+1].CreatedAt()) > r.options.Retention {
+			removed = append(removed, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	r.keys = kept
+
+	if r.options.Dir != "" {
+		for _, key := range removed {
+			if err := os.Remove(filepath.Join(r.options.Dir, key.ID+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateSigningKey creates a new key for the given algorithm
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var signer crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		signer = privateKey
+	case AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer = privateKey
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	return &SigningKey{
+		ID:         ulid.Make().String(),
+		Algorithm:  algorithm,
+		PrivateKey: signer,
+	}, nil
+}
+
+// writeSigningKey stores a key as <kid>.pem with owner-only permissions
+func writeSigningKey(dir string, key *SigningKey) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return os.WriteFile(filepath.Join(dir, key.ID+".pem"), data, 0600)
+}
+
+// readSigningKeys loads every <kid>.pem file of a directory
+// A missing directory yields no keys
+func readSigningKeys(dir string) ([]*SigningKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var keys []*SigningKey
+	for _, entry := range entries {
+		kid := strings.TrimSuffix(entry.Name(), ".pem")
+		if entry.IsDir() || kid == entry.Name() {
+			continue
+		}
+		if _, err := ulid.ParseStrict(kid); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key %s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// parseSigningKey decodes a PKCS#8 PEM private key
+func parseSigningKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Algorithm: AlgorithmRS256, PrivateKey: privateKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Algorithm: AlgorithmEdDSA, PrivateKey: privateKey}, nil
+	default:
+		return nil, errors.New("unsupported private key type")
+	}
+}
+
+// publicJWK converts the public part of a key to a JWK
+func publicJWK(key *SigningKey) (JWK, bool) {
+	jwk := JWK{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Algorithm,
+	}
+
+	switch publicKey := key.PrivateKey.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
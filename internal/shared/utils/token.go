@@ -5,18 +5,82 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/oklog/ulid/v2"
 )
 
 // JWTClaims represents the claims in our JWT tokens
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Type   string `json:"type"` // "access" or "refresh"
+	UserID       string `json:"user_id"`
+	Type         string `json:"type"`           // "access", "refresh" or "2fa_challenge"
+	SessionID    string `json:"sid,omitempty"`  // Login session (refresh token family) the token belongs to
+	TokenVersion int    `json:"ver,omitempty"`  // User token version at issue time; a bump revokes the token
+	Role         string `json:"role,omitempty"` // Account type of the user at issue time
+	// Act identifies the admin acting as the user in an impersonation token (RFC 8693)
+	Act *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaim is the RFC 8693 "act" claim naming the party acting on behalf of the subject
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonation reports whether the token was issued to an admin acting as the user
+func (c *JWTClaims) IsImpersonation() bool {
+	return c.Act != nil && c.Act.Subject != ""
+}
+
+// TokenTypeTwoFactorChallenge is the type of tokens issued between the password and TOTP login steps
+const TokenTypeTwoFactorChallenge = "2fa_challenge"
+
+// CredentialTypeAPIKey is the type set on the context claims of requests authenticated with an API key
+// No token of this type is ever signed
+const CredentialTypeAPIKey = "api_key"
+
+// TokenOption customises the claims of a generated token
+type TokenOption func(*JWTClaims)
+
+// WithSessionID binds a token to a login session
+func WithSessionID(sessionID string) TokenOption {
+	return func(claims *JWTClaims) {
+		claims.SessionID = sessionID
+	}
+}
+
+// WithTokenVersion stamps a token with the user's current token version
+func WithTokenVersion(version int) TokenOption {
+	return func(claims *JWTClaims) {
+		claims.TokenVersion = version
+	}
+}
+
+// WithRole stamps a token with the user's account type
+func WithRole(role string) TokenOption {
+	return func(claims *JWTClaims) {
+		claims.Role = role
+	}
+}
+
+// WithActor marks a token as issued to actorID acting as the token's user
+func WithActor(actorID string) TokenOption {
+	return func(claims *JWTClaims) {
+		claims.Act = &ActorClaim{Subject: actorID}
+	}
+}
+
+// WithExpiry overrides the lifetime of a token
+func WithExpiry(expiry time.Duration) TokenOption {
+	return func(claims *JWTClaims) {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(expiry))
+	}
+}
+
 // JWTManager handles JWT token operations
+// Tokens are signed with the shared HS256 secret, or with the current key of a KeyRing
+// when asymmetric signing is enabled
 type JWTManager struct {
 	secretKey     []byte
+	keyRing       *KeyRing
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
 }
@@ -35,8 +99,18 @@ func NewJWTManagerFromConfig(secretKey string, accessExpiry, refreshExpiry time.
 	return NewJWTManager(secretKey, accessExpiry, refreshExpiry)
 }
 
+// NewJWTManagerWithKeyRing creates a JWT manager that signs with asymmetric keys (RS256/EdDSA)
+// Tokens carry a "kid" header so verifiers can pick the matching public key from the JWKS
+func NewJWTManagerWithKeyRing(keyRing *KeyRing, accessExpiry, refreshExpiry time.Duration) *JWTManager {
+	return &JWTManager{
+		keyRing:       keyRing,
+		accessExpiry:  accessExpiry,
+		refreshExpiry: refreshExpiry,
+	}
+}
+
 // GenerateAccessToken generates a short-lived access token
-func (j *JWTManager) GenerateAccessToken(userID string) (string, error) {
+func (j *JWTManager) GenerateAccessToken(userID string, opts ...TokenOption) (string, error) {
 	claims := &JWTClaims{
 		UserID: userID,
 		Type:   "access",
@@ -48,9 +122,11 @@ func (j *JWTManager) GenerateAccessToken(userID string) (string, error) {
 			Subject:   userID,
 		},
 	}
+	for _, opt := range opts {
+		opt(claims)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	return j.sign(claims)
 }
 
 // GenerateRefreshToken generates a long-lived refresh token
@@ -59,6 +135,9 @@ func (h *JWTManager) GenerateRefreshToken(userID string) (string, error) {
 		UserID: userID,
 		Type:   "refresh",
 		RegisteredClaims: jwt.RegisteredClaims{
+			// A unique ID keeps tokens issued within the same second distinct,
+			// which the unique token digest index relies on
+			ID:        ulid.Make().String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(h.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -67,19 +146,71 @@ func (h *JWTManager) GenerateRefreshToken(userID string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(h.secretKey)
+	return h.sign(claims)
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+// GenerateChallengeToken generates a short-lived token proving the first login factor
+// It is only accepted by the second-factor verification step, never as an access token
+func (j *JWTManager) GenerateChallengeToken(userID string, expiry time.Duration) (string, error) {
+	claims := &JWTClaims{
+		UserID: userID,
+		Type:   TokenTypeTwoFactorChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			// The ID lets a challenge be consumed only once
+			ID:        ulid.Make().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "mitho-api",
+			Subject:   userID,
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// sign signs claims with the current key
+func (j *JWTManager) sign(claims *JWTClaims) (string, error) {
+	if j.keyRing == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(j.secretKey)
+	}
+
+	key := j.keyRing.Current()
+	if key == nil {
+		return "", errors.New("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// verificationKey resolves the key that must have signed a token
+// The signing method is pinned to the key type to prevent algorithm confusion
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.keyRing == nil {
 		// Validate the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
 		return j.secretKey, nil
-	})
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keyRing.Lookup(kid)
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.PrivateKey.Public(), nil
+}
+
+// ValidateToken validates a JWT token and returns the claims
+func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -92,6 +223,19 @@ func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
+// JWKS returns the public verification keys; it is empty when signing with the shared secret
+func (j *JWTManager) JWKS() JWKSet {
+	if j.keyRing == nil {
+		return JWKSet{Keys: []JWK{}}
+	}
+	return j.keyRing.JWKS()
+}
+
+// KeyRing returns the asymmetric key ring, or nil when signing with the shared secret
+func (j *JWTManager) KeyRing() *KeyRing {
+	return j.keyRing
+}
+
 // GetAccessExpiry returns the access token expiry duration
 func (j *JWTManager) GetAccessExpiry() time.Duration {
 	return j.accessExpiry